@@ -29,10 +29,13 @@ func (c *clientStruct) GetFormula(ssService *sheets.Service, spreadsheetId strin
 	call.ValueRenderOption("FORMULA")
 	call.Ranges(range_)
 	resp, err := call.Do()
-	if resp == nil {
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil || len(resp.ValueRanges) == 0 {
 		return nil, errors.New("empty response")
 	}
-	return resp.ValueRanges[0], err
+	return resp.ValueRanges[0], nil
 }
 
 func (c *clientStruct) GetSpreadsheet(ssService *sheets.Service, spreadsheetId string) (*sheets.Spreadsheet, error) {
@@ -50,7 +53,7 @@ func (c *clientStruct) Update(ssService *sheets.Service, spreadsheetId string, w
 	call.ValueInputOption("USER_ENTERED")
 	resp, err := call.Do()
 	if err != nil {
-		return resp, fmt.Errorf("unable to write cell data: %s", err.Error())
+		return resp, fmt.Errorf("unable to write cell data: %w", err)
 	}
 	return resp, err
 }
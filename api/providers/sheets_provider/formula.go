@@ -0,0 +1,25 @@
+package sheets_provider
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// cellRefRe matches a cell reference, eg. H10 or $F$1, and a following "(" so function names such as LOG10(
+// can be told apart
+var cellRefRe = regexp.MustCompile(`(\$?[A-Z]+)(\$?)(\d+)(\(?)`)
+
+// ShiftFormula moves the relative row references in the formula by offset rows, as pasting it offset rows
+// away does. Both backends copy Register rows with it, so their formulas stay the same.
+func ShiftFormula(formula string, offset int64) string {
+	return cellRefRe.ReplaceAllStringFunc(formula, func(ref string) string {
+		m := cellRefRe.FindStringSubmatch(ref)
+		// skip absolute rows and function names such as LOG10(
+		if m[2] == "$" || m[4] == "(" {
+			return ref
+		}
+		row, _ := strconv.ParseInt(m[3], 10, 64)
+		return fmt.Sprintf("%s%d", m[1], row+offset)
+	})
+}
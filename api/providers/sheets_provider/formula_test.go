@@ -0,0 +1,22 @@
+package sheets_provider
+
+import "testing"
+
+func TestShiftFormula(t *testing.T) {
+	tests := []struct {
+		name    string
+		formula string
+		offset  int64
+		want    string
+	}{
+		{name: "Test relative rows move", formula: "=SUM(H10-E12+$F$1)", offset: 2, want: "=SUM(H12-E14+$F$1)"},
+		{name: "Test absolute rows and functions stay", formula: "=ROUND(LOG10(A$3)+B4,2)", offset: -1, want: "=ROUND(LOG10(A$3)+B3,2)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ShiftFormula(tt.formula, tt.offset); got != tt.want {
+				t.Errorf("ShiftFormula() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package sheets_provider
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"google.golang.org/api/googleapi"
+)
+
+// ErrCallBudgetExceeded is returned once the provider has made the maximum number of API calls for this run
+var ErrCallBudgetExceeded = errors.New("sheets API call budget exceeded")
+
+// RetryPolicy controls the exponential backoff used when the Sheets API returns a quota (429) or server (5xx) error.
+// A server error does not mean the request was not applied, so only idempotent calls are retried on one.
+type RetryPolicy struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy follows Google's recommendation of truncated exponential backoff for the Sheets API
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:     6,
+	InitialBackoff: time.Second,
+	MaxBackoff:     64 * time.Second,
+}
+
+// sleep is a function variable that can be reassigned to handle mocking for testing
+var sleep = time.Sleep
+
+// SetRetryPolicy replaces the provider's retry policy
+func (p *SheetsProvider) SetRetryPolicy(policy RetryPolicy) {
+	p.retryPolicy = policy
+}
+
+// SetCallBudget limits the number of API calls, retries included, that the provider will make. Zero means no limit.
+func (p *SheetsProvider) SetCallBudget(budget int) {
+	p.callBudget = budget
+}
+
+// Calls returns the number of API calls made so far
+func (p *SheetsProvider) Calls() int {
+	return p.calls
+}
+
+// call runs fn, retrying with exponential backoff while it fails with a retryable error. Calls that are not
+// idempotent, eg. a batch update that inserts rows, are retried only when rejected for quota.
+func (p *SheetsProvider) call(name string, idempotent bool, fn func() error) error {
+	backoff := p.retryPolicy.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		if p.callBudget > 0 && p.calls >= p.callBudget {
+			return fmt.Errorf("%s: %w (%d calls)", name, ErrCallBudgetExceeded, p.calls)
+		}
+		p.calls++
+
+		err := fn()
+		if err == nil || !isRetryable(err, idempotent) || attempt >= p.retryPolicy.MaxRetries {
+			return err
+		}
+
+		wait := backoff + time.Duration(rand.Int63n(int64(time.Second)))
+		log.Printf("%s: %s, retrying in %s", name, err.Error(), wait.Round(time.Millisecond))
+		sleep(wait)
+
+		backoff *= 2
+		if backoff > p.retryPolicy.MaxBackoff {
+			backoff = p.retryPolicy.MaxBackoff
+		}
+	}
+}
+
+func isRetryable(err error, idempotent bool) bool {
+	var gErr *googleapi.Error
+	if !errors.As(err, &gErr) {
+		return false
+	}
+	if gErr.Code == http.StatusTooManyRequests {
+		return true
+	}
+	return idempotent && gErr.Code >= http.StatusInternalServerError
+}
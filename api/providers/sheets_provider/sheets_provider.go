@@ -26,6 +26,9 @@ type SheetsProviderInterface interface {
 type SheetsProvider struct {
 	service       *sheets.Service
 	spreadsheetID string
+	retryPolicy   RetryPolicy
+	callBudget    int
+	calls         int
 }
 
 func New(spreadsheetID string, cfg *config.Config) (*SheetsProvider, error) {
@@ -46,11 +49,16 @@ func New(spreadsheetID string, cfg *config.Config) (*SheetsProvider, error) {
 	return &SheetsProvider{
 		service:       service,
 		spreadsheetID: spreadsheetID,
+		retryPolicy:   DefaultRetryPolicy,
 	}, nil
 }
 
 func (p *SheetsProvider) GetValues(range_ string) (*sheets.ValueRange, error) {
-	resp, err := sheets_client.ClientStruct.Get(p.service, p.spreadsheetID, range_)
+	var resp *sheets.ValueRange
+	err := p.call("GetValues", true, func() (err error) {
+		resp, err = sheets_client.ClientStruct.Get(p.service, p.spreadsheetID, range_)
+		return err
+	})
 	if err != nil {
 		log.Printf("error when trying to get cell data: %s", err.Error())
 		return nil, err
@@ -59,7 +67,11 @@ func (p *SheetsProvider) GetValues(range_ string) (*sheets.ValueRange, error) {
 }
 
 func (p *SheetsProvider) GetFormula(range_ string) (*sheets.ValueRange, error) {
-	resp, err := sheets_client.ClientStruct.GetFormula(p.service, p.spreadsheetID, range_)
+	var resp *sheets.ValueRange
+	err := p.call("GetFormula", true, func() (err error) {
+		resp, err = sheets_client.ClientStruct.GetFormula(p.service, p.spreadsheetID, range_)
+		return err
+	})
 	if err != nil {
 		log.Printf("error when trying to get formula cells: %s, range: %s", err.Error(), range_)
 		return nil, err
@@ -68,7 +80,11 @@ func (p *SheetsProvider) GetFormula(range_ string) (*sheets.ValueRange, error) {
 }
 
 func (p *SheetsProvider) GetSpreadsheet() (*sheets.Spreadsheet, error) {
-	resp, err := sheets_client.ClientStruct.GetSpreadsheet(p.service, p.spreadsheetID)
+	var resp *sheets.Spreadsheet
+	err := p.call("GetSpreadsheet", true, func() (err error) {
+		resp, err = sheets_client.ClientStruct.GetSpreadsheet(p.service, p.spreadsheetID)
+		return err
+	})
 	if err != nil {
 		log.Printf("error when try to get spreadsheet: %s", err.Error())
 		return nil, err
@@ -77,7 +93,11 @@ func (p *SheetsProvider) GetSpreadsheet() (*sheets.Spreadsheet, error) {
 }
 
 func (p *SheetsProvider) BatchUpdate(updateReq *sheets.BatchUpdateSpreadsheetRequest) (*sheets.BatchUpdateSpreadsheetResponse, error) {
-	resp, err := sheets_client.ClientStruct.BatchUpdate(p.service, p.spreadsheetID, updateReq)
+	var resp *sheets.BatchUpdateSpreadsheetResponse
+	err := p.call("BatchUpdate", false, func() (err error) {
+		resp, err = sheets_client.ClientStruct.BatchUpdate(p.service, p.spreadsheetID, updateReq)
+		return err
+	})
 	if err != nil {
 		log.Printf("error when try to batch update spreadsheet: %s", err.Error())
 		return nil, err
@@ -86,7 +106,11 @@ func (p *SheetsProvider) BatchUpdate(updateReq *sheets.BatchUpdateSpreadsheetReq
 }
 
 func (p *SheetsProvider) Update(writeRange string, vRange *sheets.ValueRange) (*sheets.UpdateValuesResponse, error) {
-	resp, err := sheets_client.ClientStruct.Update(p.service, p.spreadsheetID, writeRange, vRange)
+	var resp *sheets.UpdateValuesResponse
+	err := p.call("Update", true, func() (err error) {
+		resp, err = sheets_client.ClientStruct.Update(p.service, p.spreadsheetID, writeRange, vRange)
+		return err
+	})
 	if err != nil {
 		log.Printf("error when try to update spreadsheet: %s", err.Error())
 		return nil, err
@@ -1,6 +1,12 @@
 package sheets_provider
 
 import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"google.golang.org/api/googleapi"
 	"google.golang.org/api/sheets/v4"
 )
 
@@ -13,3 +19,82 @@ func (c *getClientMock) Get(ssService *sheets.Service, spreadsheetId string, ran
 	return getRequestFunc(spreadsheetId, range_)
 }
 
+
+func Test_sheetsProvider_call(t *testing.T) {
+	sleep = func(time.Duration) {}
+	quotaErr := &googleapi.Error{Code: http.StatusTooManyRequests}
+	badRequestErr := &googleapi.Error{Code: http.StatusBadRequest}
+	serverErr := &googleapi.Error{Code: http.StatusServiceUnavailable}
+
+	tests := []struct {
+		name          string
+		budget        int
+		nonIdempotent bool
+		errs          []error
+		wantCalls     int
+		wantErr       error
+	}{
+		{
+			name:      "Test call succeeds after quota errors",
+			errs:      []error{quotaErr, quotaErr, nil},
+			wantCalls: 3,
+		},
+		{
+			name:      "Test call does not retry a bad request",
+			errs:      []error{badRequestErr, nil},
+			wantCalls: 1,
+			wantErr:   badRequestErr,
+		},
+		{
+			name:      "Test call retries a server error",
+			errs:      []error{serverErr, nil},
+			wantCalls: 2,
+		},
+		{
+			name:          "Test call does not retry a server error when not idempotent",
+			nonIdempotent: true,
+			errs:          []error{serverErr, nil},
+			wantCalls:     1,
+			wantErr:       serverErr,
+		},
+		{
+			name:          "Test call retries a quota error when not idempotent",
+			nonIdempotent: true,
+			errs:          []error{quotaErr, nil},
+			wantCalls:     2,
+		},
+		{
+			name:      "Test call gives up after max retries",
+			errs:      []error{quotaErr, quotaErr, quotaErr, quotaErr},
+			wantCalls: 3,
+			wantErr:   quotaErr,
+		},
+		{
+			name:      "Test call stops at the call budget",
+			budget:    2,
+			errs:      []error{quotaErr, quotaErr, nil},
+			wantCalls: 2,
+			wantErr:   ErrCallBudgetExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &SheetsProvider{
+				retryPolicy: RetryPolicy{MaxRetries: 2, InitialBackoff: time.Second, MaxBackoff: 4 * time.Second},
+				callBudget:  tt.budget,
+			}
+			i := 0
+			err := p.call("test", !tt.nonIdempotent, func() error {
+				err := tt.errs[i]
+				i++
+				return err
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("call() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if p.Calls() != tt.wantCalls {
+				t.Errorf("call() calls = %d, want %d", p.Calls(), tt.wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,491 @@
+package xlsx_provider
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"register/api/providers/sheets_provider"
+
+	"github.com/xuri/excelize/v2"
+	"google.golang.org/api/sheets/v4"
+)
+
+// XLSXProvider implements sheets_provider.SheetsProviderInterface against a local Excel workbook
+// so that the register can be kept offline instead of in Google Sheets
+type XLSXProvider struct {
+	file     *excelize.File
+	fileName string
+	styles   map[string]int
+}
+
+var rangeRe = regexp.MustCompile(`^(?:'?([^'!]+)'?!)?([A-Z]+)(\d*)(?::([A-Z]+)(\d*))?$`)
+
+func New(fileName string) (*XLSXProvider, error) {
+	f, err := excelize.OpenFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("unable to open workbook %s: %s", fileName, err.Error())
+	}
+	return &XLSXProvider{
+		file:     f,
+		fileName: fileName,
+		styles:   make(map[string]int),
+	}, nil
+}
+
+// GetValues returns the calculated values in the range, trimming empty trailing rows and cells as Google Sheets does
+func (p *XLSXProvider) GetValues(range_ string) (*sheets.ValueRange, error) {
+	return p.readRange(range_, false)
+}
+
+// GetFormula returns the formulas in the range, or the value for cells without a formula
+func (p *XLSXProvider) GetFormula(range_ string) (*sheets.ValueRange, error) {
+	return p.readRange(range_, true)
+}
+
+func (p *XLSXProvider) GetSpreadsheet() (*sheets.Spreadsheet, error) {
+	spreadsheet := &sheets.Spreadsheet{
+		Properties: &sheets.SpreadsheetProperties{Title: p.fileName},
+	}
+	for i, name := range p.file.GetSheetList() {
+		rows, cols, err := p.sheetSize(name)
+		if err != nil {
+			return nil, err
+		}
+		spreadsheet.Sheets = append(spreadsheet.Sheets, &sheets.Sheet{
+			Properties: &sheets.SheetProperties{
+				SheetId: int64(i),
+				Title:   name,
+				Index:   int64(i),
+				GridProperties: &sheets.GridProperties{
+					RowCount:    rows,
+					ColumnCount: cols,
+				},
+			},
+		})
+	}
+	return spreadsheet, nil
+}
+
+// BatchUpdate applies the UpdateCells, CopyPaste and tab and row requests used by the sheets service and saves
+// the workbook
+func (p *XLSXProvider) BatchUpdate(updateReq *sheets.BatchUpdateSpreadsheetRequest) (*sheets.BatchUpdateSpreadsheetResponse, error) {
+	resp := &sheets.BatchUpdateSpreadsheetResponse{}
+	for _, req := range updateReq.Requests {
+		var err error
+		switch {
+		case req.UpdateCells != nil:
+			err = p.updateCells(req.UpdateCells)
+		case req.CopyPaste != nil:
+			err = p.copyPaste(req.CopyPaste)
+		case req.DuplicateSheet != nil:
+			err = p.duplicateSheet(req.DuplicateSheet)
+		case req.UpdateSheetProperties != nil:
+			err = p.renameSheet(req.UpdateSheetProperties)
+		case req.AddSheet != nil:
+			_, err = p.file.NewSheet(req.AddSheet.Properties.Title)
+		case req.DeleteDimension != nil:
+			err = p.deleteRows(req.DeleteDimension)
+		default:
+			j, _ := json.Marshal(req)
+			err = fmt.Errorf("unsupported batch update request: %s", j)
+		}
+		if err != nil {
+			return nil, err
+		}
+		resp.Replies = append(resp.Replies, &sheets.Response{})
+	}
+	if err := p.file.Save(); err != nil {
+		return nil, fmt.Errorf("unable to save workbook %s: %s", p.fileName, err.Error())
+	}
+	return resp, nil
+}
+
+// Update writes the values as a user would enter them: leading "=" is a formula and numeric strings are numbers
+func (p *XLSXProvider) Update(writeRange string, vRange *sheets.ValueRange) (*sheets.UpdateValuesResponse, error) {
+	sheet, startCol, startRow, _, _, err := p.parseRange(writeRange)
+	if err != nil {
+		return nil, err
+	}
+
+	var cells int64
+	for r, row := range vRange.Values {
+		for c, value := range row {
+			cell, _ := excelize.CoordinatesToCellName(startCol+c, startRow+r)
+			if err = p.setUserEnteredValue(sheet, cell, value); err != nil {
+				return nil, fmt.Errorf("unable to write cell data: %s", err.Error())
+			}
+			cells++
+		}
+	}
+	if err = p.file.Save(); err != nil {
+		return nil, fmt.Errorf("unable to save workbook %s: %s", p.fileName, err.Error())
+	}
+	return &sheets.UpdateValuesResponse{
+		UpdatedRange: writeRange,
+		UpdatedRows:  int64(len(vRange.Values)),
+		UpdatedCells: cells,
+	}, nil
+}
+
+func (p *XLSXProvider) readRange(range_ string, formulas bool) (*sheets.ValueRange, error) {
+	sheet, startCol, startRow, endCol, endRow, err := p.parseRange(range_)
+	if err != nil {
+		return nil, err
+	}
+
+	var values [][]interface{}
+	for r := startRow; r <= endRow; r++ {
+		var row []interface{}
+		for c := startCol; c <= endCol; c++ {
+			cell, _ := excelize.CoordinatesToCellName(c, r)
+			v, err := p.readCell(sheet, cell, formulas)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, v)
+		}
+		values = append(values, trimRow(row))
+	}
+	return &sheets.ValueRange{
+		Range:  range_,
+		Values: trimRows(values),
+	}, nil
+}
+
+func (p *XLSXProvider) readCell(sheet, cell string, formulas bool) (string, error) {
+	formula, err := p.file.GetCellFormula(sheet, cell)
+	if err != nil {
+		return "", err
+	}
+	if formula == "" {
+		return p.file.GetCellValue(sheet, cell)
+	}
+	if formulas {
+		return "=" + strings.TrimPrefix(formula, "="), nil
+	}
+	v, err := p.file.CalcCellValue(sheet, cell)
+	if err != nil {
+		// fall back to the value cached in the workbook
+		return p.file.GetCellValue(sheet, cell)
+	}
+	return v, nil
+}
+
+func (p *XLSXProvider) updateCells(req *sheets.UpdateCellsRequest) error {
+	if req.Range != nil {
+		return p.clearRows(req.Range)
+	}
+	sheet, err := p.sheetName(req.Start.SheetId)
+	if err != nil {
+		return err
+	}
+	for r, row := range req.Rows {
+		for c, cellData := range row.Values {
+			if cellData == nil {
+				continue
+			}
+			cell, _ := excelize.CoordinatesToCellName(int(req.Start.ColumnIndex)+c+1, int(req.Start.RowIndex)+r+1)
+			if err = p.setCellData(sheet, cell, cellData); err != nil {
+				return fmt.Errorf("unable to update cell %s!%s: %s", sheet, cell, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// copyPaste copies the source block, values, formulas and styles, to the destination. Like Google Sheets the
+// destination only needs its top left corner and relative row references in formulas are shifted.
+func (p *XLSXProvider) copyPaste(req *sheets.CopyPasteRequest) error {
+	srcSheet, err := p.sheetName(req.Source.SheetId)
+	if err != nil {
+		return err
+	}
+	dstSheet, err := p.sheetName(req.Destination.SheetId)
+	if err != nil {
+		return err
+	}
+
+	rowOffset := req.Destination.StartRowIndex - req.Source.StartRowIndex
+	colOffset := req.Destination.StartColumnIndex - req.Source.StartColumnIndex
+	for r := req.Source.StartRowIndex; r < req.Source.EndRowIndex; r++ {
+		for c := req.Source.StartColumnIndex; c < req.Source.EndColumnIndex; c++ {
+			src, _ := excelize.CoordinatesToCellName(int(c)+1, int(r)+1)
+			dst, _ := excelize.CoordinatesToCellName(int(c+colOffset)+1, int(r+rowOffset)+1)
+			if err = p.copyCell(srcSheet, src, dstSheet, dst, rowOffset); err != nil {
+				return fmt.Errorf("unable to copy %s!%s to %s!%s: %s", srcSheet, src, dstSheet, dst, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// duplicateSheet copies a sheet to a new one added after the others, so the sheet ids stay the same
+func (p *XLSXProvider) duplicateSheet(req *sheets.DuplicateSheetRequest) error {
+	src, err := p.sheetName(req.SourceSheetId)
+	if err != nil {
+		return err
+	}
+	to, err := p.file.NewSheet(req.NewSheetName)
+	if err != nil {
+		return err
+	}
+	from, err := p.file.GetSheetIndex(src)
+	if err != nil {
+		return err
+	}
+	return p.file.CopySheet(from, to)
+}
+
+// renameSheet applies the title of sheet properties updates; no other properties are supported
+func (p *XLSXProvider) renameSheet(req *sheets.UpdateSheetPropertiesRequest) error {
+	if req.Fields != "title" {
+		return fmt.Errorf("unsupported sheet properties update: %s", req.Fields)
+	}
+	sheet, err := p.sheetName(req.Properties.SheetId)
+	if err != nil {
+		return err
+	}
+	return p.file.SetSheetName(sheet, req.Properties.Title)
+}
+
+// deleteRows deletes a range of rows; the formulas below are shifted up
+func (p *XLSXProvider) deleteRows(req *sheets.DeleteDimensionRequest) error {
+	if req.Range.Dimension != "ROWS" {
+		return fmt.Errorf("unsupported delete dimension: %s", req.Range.Dimension)
+	}
+	sheet, err := p.sheetName(req.Range.SheetId)
+	if err != nil {
+		return err
+	}
+	for r := req.Range.EndIndex; r > req.Range.StartIndex; r-- {
+		if err = p.file.RemoveRow(sheet, int(r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// clearRows clears a range of rows, the whole sheet when the range has no end row, like an UpdateCells request
+// with a range and no rows; the rows are removed so their values and styles go
+func (p *XLSXProvider) clearRows(gr *sheets.GridRange) error {
+	sheet, err := p.sheetName(gr.SheetId)
+	if err != nil {
+		return err
+	}
+	end := gr.EndRowIndex
+	if end == 0 {
+		// the saved dimension misses rows added since the workbook was opened
+		rows, err := p.file.GetRows(sheet)
+		if err != nil {
+			return err
+		}
+		if end, _, err = p.sheetSize(sheet); err != nil {
+			return err
+		}
+		end = max(end, int64(len(rows)))
+	}
+	for r := end; r > gr.StartRowIndex; r-- {
+		if err = p.file.RemoveRow(sheet, int(r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *XLSXProvider) copyCell(srcSheet, src, dstSheet, dst string, rowOffset int64) error {
+	style, err := p.file.GetCellStyle(srcSheet, src)
+	if err != nil {
+		return err
+	}
+	formula, err := p.file.GetCellFormula(srcSheet, src)
+	if err != nil {
+		return err
+	}
+	if formula != "" {
+		err = p.file.SetCellFormula(dstSheet, dst, sheets_provider.ShiftFormula(formula, rowOffset))
+	} else {
+		var v string
+		v, err = p.file.GetCellValue(srcSheet, src, excelize.Options{RawCellValue: true})
+		if err == nil {
+			err = p.setUserEnteredValue(dstSheet, dst, v)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	return p.file.SetCellStyle(dstSheet, dst, dst, style)
+}
+
+func (p *XLSXProvider) setCellData(sheet, cell string, cellData *sheets.CellData) error {
+	var err error
+	if v := cellData.UserEnteredValue; v != nil {
+		switch {
+		case v.FormulaValue != nil:
+			err = p.file.SetCellFormula(sheet, cell, strings.TrimPrefix(*v.FormulaValue, "="))
+		case v.NumberValue != nil:
+			err = p.file.SetCellFloat(sheet, cell, *v.NumberValue, -1, 64)
+		case v.BoolValue != nil:
+			err = p.file.SetCellBool(sheet, cell, *v.BoolValue)
+		case v.StringValue != nil:
+			err = p.file.SetCellStr(sheet, cell, *v.StringValue)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if cellData.UserEnteredFormat != nil {
+		style, err := p.style(cellData.UserEnteredFormat)
+		if err != nil {
+			return err
+		}
+		return p.file.SetCellStyle(sheet, cell, cell, style)
+	}
+	return nil
+}
+
+func (p *XLSXProvider) setUserEnteredValue(sheet, cell string, value interface{}) error {
+	switch v := value.(type) {
+	case float64:
+		return p.file.SetCellFloat(sheet, cell, v, -1, 64)
+	case string:
+		if strings.HasPrefix(v, "=") {
+			return p.file.SetCellFormula(sheet, cell, strings.TrimPrefix(v, "="))
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return p.file.SetCellFloat(sheet, cell, f, -1, 64)
+		}
+		return p.file.SetCellStr(sheet, cell, v)
+	default:
+		return p.file.SetCellValue(sheet, cell, v)
+	}
+}
+
+// style converts a Google Sheets cell format to an Excel style, reusing styles already created
+func (p *XLSXProvider) style(format *sheets.CellFormat) (int, error) {
+	key, _ := json.Marshal(format)
+	if id, ok := p.styles[string(key)]; ok {
+		return id, nil
+	}
+
+	style := &excelize.Style{}
+	if format.HorizontalAlignment != "" {
+		style.Alignment = &excelize.Alignment{Horizontal: strings.ToLower(format.HorizontalAlignment)}
+	}
+	if tf := format.TextFormat; tf != nil {
+		style.Font = &excelize.Font{
+			Bold:   tf.Bold,
+			Italic: tf.Italic,
+			Family: tf.FontFamily,
+			Size:   float64(tf.FontSize),
+		}
+	}
+	if format.BackgroundColor != nil {
+		style.Fill = excelize.Fill{Type: "pattern", Pattern: 1, Color: []string{hexColor(format.BackgroundColor)}}
+	}
+	if b := format.Borders; b != nil {
+		for side, border := range map[string]*sheets.Border{"left": b.Left, "right": b.Right, "top": b.Top, "bottom": b.Bottom} {
+			if border != nil && border.Style != "" && border.Style != "NONE" {
+				style.Border = append(style.Border, excelize.Border{Type: side, Color: hexColor(border.Color), Style: 1})
+			}
+		}
+	}
+	if format.NumberFormat != nil && format.NumberFormat.Pattern != "" {
+		pattern := format.NumberFormat.Pattern
+		style.CustomNumFmt = &pattern
+	}
+
+	id, err := p.file.NewStyle(style)
+	if err != nil {
+		return 0, err
+	}
+	p.styles[string(key)] = id
+	return id, nil
+}
+
+func (p *XLSXProvider) sheetName(sheetID int64) (string, error) {
+	list := p.file.GetSheetList()
+	if sheetID < 0 || sheetID >= int64(len(list)) {
+		return "", fmt.Errorf("could not get sheet name: no sheet id %d", sheetID)
+	}
+	return list[sheetID], nil
+}
+
+func (p *XLSXProvider) sheetSize(sheet string) (int64, int64, error) {
+	dimension, err := p.file.GetSheetDimension(sheet)
+	if err != nil {
+		return 0, 0, err
+	}
+	parts := strings.Split(dimension, ":")
+	col, row, err := excelize.CellNameToCoordinates(parts[len(parts)-1])
+	if err != nil {
+		return 0, 0, nil
+	}
+	return int64(row), int64(col), nil
+}
+
+// parseRange splits an A1 range such as Register!A3:BC500 or Register!F1:F1 into its sheet and 1-based coordinates.
+// Open ended ranges such as Register!A3:J are bounded by the used size of the sheet.
+func (p *XLSXProvider) parseRange(range_ string) (string, int, int, int, int, error) {
+	m := rangeRe.FindStringSubmatch(range_)
+	if m == nil {
+		return "", 0, 0, 0, 0, fmt.Errorf("could not parse range: %s", range_)
+	}
+	sheet := m[1]
+	if sheet == "" {
+		sheet = p.file.GetSheetName(0)
+	}
+	if idx, err := p.file.GetSheetIndex(sheet); err != nil || idx < 0 {
+		return "", 0, 0, 0, 0, fmt.Errorf("unable to parse range: %s: no sheet named %s", range_, sheet)
+	}
+	maxRows, _, err := p.sheetSize(sheet)
+	if err != nil {
+		return "", 0, 0, 0, 0, err
+	}
+
+	startCol, _ := excelize.ColumnNameToNumber(m[2])
+	startRow := atoiOr(m[3], 1)
+	endCol, endRow := startCol, startRow
+	if m[4] != "" {
+		endCol, _ = excelize.ColumnNameToNumber(m[4])
+		endRow = atoiOr(m[5], int(maxRows))
+	}
+	return sheet, startCol, startRow, endCol, endRow, nil
+}
+
+func atoiOr(s string, def int) int {
+	if i, err := strconv.Atoi(s); err == nil {
+		return i
+	}
+	return def
+}
+
+func hexColor(c *sheets.Color) string {
+	if c == nil {
+		return "000000"
+	}
+	return fmt.Sprintf("%02X%02X%02X", colorByte(c.Red), colorByte(c.Green), colorByte(c.Blue))
+}
+
+func colorByte(f float64) int {
+	return int(math.Round(f * 255))
+}
+
+func trimRow(row []interface{}) []interface{} {
+	end := len(row)
+	for end > 0 && row[end-1] == "" {
+		end--
+	}
+	return row[:end]
+}
+
+func trimRows(rows [][]interface{}) [][]interface{} {
+	end := len(rows)
+	for end > 0 && len(rows[end-1]) == 0 {
+		end--
+	}
+	return rows[:end]
+}
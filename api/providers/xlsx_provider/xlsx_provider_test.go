@@ -0,0 +1,206 @@
+package xlsx_provider
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/xuri/excelize/v2"
+	"google.golang.org/api/sheets/v4"
+)
+
+func newTestProvider(t *testing.T) *XLSXProvider {
+	fileName := filepath.Join(t.TempDir(), "register.xlsx")
+	f := excelize.NewFile()
+	_ = f.SetSheetName("Sheet1", "Register")
+	_, _ = f.NewSheet("Budget")
+	_ = f.SetSheetRow("Register", "A1", &[]interface{}{"X", "WellsFargo", "01/02/26", "Groceries", 12.5})
+	_ = f.SetCellFormula("Register", "H1", "G1-E1")
+	if err := f.SaveAs(fileName); err != nil {
+		t.Fatalf("error: %s\n", err.Error())
+	}
+	p, err := New(fileName)
+	if err != nil {
+		t.Fatalf("error: %s\n", err.Error())
+	}
+	return p
+}
+
+func TestXLSXProvider_GetSpreadsheet(t *testing.T) {
+	p := newTestProvider(t)
+	s, err := p.GetSpreadsheet()
+	if err != nil {
+		t.Fatalf("GetSpreadsheet() error = %v", err)
+	}
+	var titles []string
+	for _, sheet := range s.Sheets {
+		titles = append(titles, sheet.Properties.Title)
+	}
+	if want := []string{"Register", "Budget"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("GetSpreadsheet() titles = %v, want %v", titles, want)
+	}
+}
+
+func TestXLSXProvider_GetValuesAndFormula(t *testing.T) {
+	p := newTestProvider(t)
+	tests := []struct {
+		name     string
+		range_   string
+		formulas bool
+		want     [][]interface{}
+	}{
+		{
+			name:   "Test GetValues trims empty cells and rows",
+			range_: "Register!A1:J5",
+			want:   [][]interface{}{{"X", "WellsFargo", "01/02/26", "Groceries", "12.5", "", "", "-12.5"}},
+		},
+		{
+			name:     "Test GetFormula",
+			range_:   "Register!H1:H1",
+			formulas: true,
+			want:     [][]interface{}{{"=G1-E1"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *sheets.ValueRange
+			var err error
+			if tt.formulas {
+				got, err = p.GetFormula(tt.range_)
+			} else {
+				got, err = p.GetValues(tt.range_)
+			}
+			if err != nil {
+				t.Fatalf("error = %v", err)
+			}
+			if !reflect.DeepEqual(got.Values, tt.want) {
+				t.Errorf("got = %v, want %v", got.Values, tt.want)
+			}
+		})
+	}
+}
+
+func TestXLSXProvider_BatchUpdate(t *testing.T) {
+	p := newTestProvider(t)
+	name := "Dining"
+	amount := 20.0
+	formula := "=G2-E2"
+	_, err := p.BatchUpdate(&sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{
+			{
+				CopyPaste: &sheets.CopyPasteRequest{
+					Source:      &sheets.GridRange{SheetId: 0, StartRowIndex: 0, EndRowIndex: 1, StartColumnIndex: 0, EndColumnIndex: 8},
+					Destination: &sheets.GridRange{SheetId: 0, StartRowIndex: 2, EndRowIndex: 2, StartColumnIndex: 0, EndColumnIndex: 8},
+					PasteType:   "PASTE_NORMAL",
+				},
+			},
+			{
+				UpdateCells: &sheets.UpdateCellsRequest{
+					Fields: "*",
+					Start:  &sheets.GridCoordinate{SheetId: 0, RowIndex: 1, ColumnIndex: 3},
+					Rows: []*sheets.RowData{{Values: []*sheets.CellData{
+						{
+							UserEnteredValue: &sheets.ExtendedValue{StringValue: &name},
+							UserEnteredFormat: &sheets.CellFormat{
+								HorizontalAlignment: "LEFT",
+								BackgroundColor:     &sheets.Color{Red: 1, Green: 1, Blue: 0.6},
+							},
+						},
+						{UserEnteredValue: &sheets.ExtendedValue{NumberValue: &amount}},
+						nil,
+						nil,
+						{UserEnteredValue: &sheets.ExtendedValue{FormulaValue: &formula}},
+					}}},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("BatchUpdate() error = %v", err)
+	}
+
+	got, err := p.GetFormula("Register!D2:H3")
+	if err != nil {
+		t.Fatalf("GetFormula() error = %v", err)
+	}
+	want := [][]interface{}{
+		{"Dining", "20", "", "", "=G2-E2"},
+		{"Groceries", "12.5", "", "", "=G3-E3"},
+	}
+	if !reflect.DeepEqual(got.Values, want) {
+		t.Errorf("GetFormula() = %v, want %v", got.Values, want)
+	}
+
+	reopened, err := New(p.fileName)
+	if err != nil {
+		t.Fatalf("error: %s\n", err.Error())
+	}
+	v, err := reopened.GetValues("Register!D2:D2")
+	if err != nil || !reflect.DeepEqual(v.Values, [][]interface{}{{"Dining"}}) {
+		t.Errorf("saved workbook = %v, %v", v, err)
+	}
+}
+
+func TestXLSXProvider_BatchUpdateSheets(t *testing.T) {
+	p := newTestProvider(t)
+	_ = p.file.SetSheetRow("Register", "A2", &[]interface{}{"X", "WellsFargo", "01/03/26", "Dining", 20})
+	_ = p.file.SetSheetRow("Register", "A3", &[]interface{}{"X", "WellsFargo", "01/04/26", "Gas", 30})
+	_, err := p.BatchUpdate(&sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{
+			{DuplicateSheet: &sheets.DuplicateSheetRequest{SourceSheetId: 0, NewSheetName: "Register 2026", InsertSheetIndex: 2}},
+			{DeleteDimension: &sheets.DeleteDimensionRequest{Range: &sheets.DimensionRange{SheetId: 0, Dimension: "ROWS", StartIndex: 0, EndIndex: 2}}},
+			{UpdateSheetProperties: &sheets.UpdateSheetPropertiesRequest{Properties: &sheets.SheetProperties{SheetId: 1, Title: "Budget 2026"}, Fields: "title"}},
+			{AddSheet: &sheets.AddSheetRequest{Properties: &sheets.SheetProperties{Title: "Budget"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("BatchUpdate() error = %v", err)
+	}
+
+	if got, want := p.file.GetSheetList(), []string{"Register", "Budget 2026", "Register 2026", "Budget"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sheets = %v, want %v", got, want)
+	}
+	got, err := p.GetValues("Register!D1:D3")
+	if err != nil || !reflect.DeepEqual(got.Values, [][]interface{}{{"Gas"}}) {
+		t.Errorf("Register = %v, %v, want the last row only", got, err)
+	}
+	got, err = p.GetValues("'Register 2026'!D1:D3")
+	if err != nil || !reflect.DeepEqual(got.Values, [][]interface{}{{"Groceries"}, {"Dining"}, {"Gas"}}) {
+		t.Errorf("Register 2026 = %v, %v, want every row", got, err)
+	}
+}
+
+func TestXLSXProvider_BatchUpdateClear(t *testing.T) {
+	p := newTestProvider(t)
+	_ = p.file.SetSheetRow("Register", "A2", &[]interface{}{"X", "WellsFargo", "01/03/26", "Dining", 20})
+	name := "Gas"
+	_, err := p.BatchUpdate(&sheets.BatchUpdateSpreadsheetRequest{
+		Requests: []*sheets.Request{
+			{UpdateCells: &sheets.UpdateCellsRequest{Fields: "*", Range: &sheets.GridRange{SheetId: 0}}},
+			{UpdateCells: &sheets.UpdateCellsRequest{
+				Fields: "*",
+				Rows:   []*sheets.RowData{{Values: []*sheets.CellData{{UserEnteredValue: &sheets.ExtendedValue{StringValue: &name}}}}},
+				Start:  &sheets.GridCoordinate{SheetId: 0, ColumnIndex: 3},
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("BatchUpdate() error = %v", err)
+	}
+	got, err := p.GetValues("Register!A1:H2")
+	if err != nil || !reflect.DeepEqual(got.Values, [][]interface{}{{"", "", "", "Gas"}}) {
+		t.Errorf("Register = %v, %v, want the new row only", got, err)
+	}
+}
+
+func TestXLSXProvider_Update(t *testing.T) {
+	p := newTestProvider(t)
+	_, err := p.Update("Register!F1:F1", &sheets.ValueRange{Values: [][]interface{}{{"=SUM(E1*2)"}}})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	got, _ := p.GetValues("Register!F1:F1")
+	if want := [][]interface{}{{"25"}}; !reflect.DeepEqual(got.Values, want) {
+		t.Errorf("Update() = %v, want %v", got.Values, want)
+	}
+}
@@ -0,0 +1,88 @@
+package sheets_service
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+const ForecastTabName = "Forecast"
+
+// ForecastRow is one day of the cash-flow forecast
+type ForecastRow struct {
+	Date       string
+	Balance    float64
+	BelowFloor bool
+	Expected   []string // the day's deposits and withdrawals, eg. "Rent -1800.00"
+}
+
+// UpdateForecast writes the cash-flow forecast to the tab, one row per day; days below the floor are yellow. The
+// tab is added if it is missing and the previous forecast's rows are cleared first.
+func (ss *SheetsService) UpdateForecast(tabName string, forecast []ForecastRow) error {
+	exists, err := ss.TabExists(tabName)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		_, err = ss.Provider.BatchUpdate(&sheets.BatchUpdateSpreadsheetRequest{
+			Requests: []*sheets.Request{{
+				AddSheet: &sheets.AddSheetRequest{Properties: &sheets.SheetProperties{Title: tabName}},
+			}},
+		})
+		if err != nil {
+			return fmt.Errorf("could not add the %s tab: %s", tabName, err.Error())
+		}
+	}
+	id, err := ss.getSheetID(tabName)
+	if err != nil {
+		return fmt.Errorf("error: %s\n", err.Error())
+	}
+
+	requests := []*sheets.Request{
+		{
+			UpdateCells: &sheets.UpdateCellsRequest{
+				Fields: "*",
+				Range:  &sheets.GridRange{SheetId: id},
+			},
+		},
+		{
+			UpdateCells: &sheets.UpdateCellsRequest{
+				Fields: "*",
+				Rows:   populateForecast(forecast),
+				Start:  &sheets.GridCoordinate{SheetId: id},
+			},
+		},
+	}
+	_, err = ss.Provider.BatchUpdate(&sheets.BatchUpdateSpreadsheetRequest{Requests: requests})
+	if err != nil {
+		return fmt.Errorf("error: %s\n", err.Error())
+	}
+	return nil
+}
+
+func populateForecast(forecast []ForecastRow) []*sheets.RowData {
+	bgColor := "grey"
+	rows := []*sheets.RowData{{Values: []*sheets.CellData{
+		mkBoldFormat("Date", "left", bgColor, false),
+		mkBoldFormat("Balance", "center", bgColor, false),
+		mkBoldFormat("Below Floor", "center", bgColor, false),
+		mkBoldFormat("Expected", "left", bgColor, false),
+	}}}
+
+	for _, d := range forecast {
+		bgColor = "white"
+		below := ""
+		if d.BelowFloor {
+			bgColor = "yellow"
+			below = "yes"
+		}
+		rows = append(rows, &sheets.RowData{Values: []*sheets.CellData{
+			mkCellDataString(d.Date, "left", bgColor, false),
+			mkCellDataDollars(d.Balance, "right", bgColor, false),
+			mkCellDataString(below, "center", bgColor, false),
+			mkCellDataString(strings.Join(d.Expected, "; "), "left", bgColor, false),
+		}})
+	}
+	return rows
+}
@@ -8,7 +8,7 @@ import (
 	"math"
 	"os"
 	"regexp"
-	"register/pkg/banking"
+	"register/api/providers/sheets_provider"
 	"register/pkg/models"
 	"sort"
 	"strconv"
@@ -68,20 +68,65 @@ func hasNote(trans *models.Transaction) bool {
 	return false
 }
 
-func isPaycheck(name string) bool {
-	if strings.Contains(name, banking.PayCheckName) {
-		return true
+// incomeSource returns the income source with the transaction's register name, or nil
+func (ss *SheetsService) incomeSource(name string) *models.IncomeSource {
+	return ss.Income.Named(name)
+}
+
+// hasAllocation returns true if recording the income enters budget amounts across the category columns
+func hasAllocation(src *models.IncomeSource) bool {
+	return src.Allocation != models.AllocationNone || len(src.Allocations) > 0
+}
+
+// allocation returns the amount an income source allocates to a category column: its own amount for the
+// column if it has one, otherwise the Budget sheet's amount for its Allocation period
+func allocation(src *models.IncomeSource, category string, entry *BudgetEntry) float64 {
+	if amount, ok := src.Allocations[category]; ok {
+		return amount
 	}
-	return false
+	if entry == nil {
+		return 0
+	}
+	switch src.Allocation {
+	case models.AllocationWeekly:
+		return entry.Weekly
+	case models.AllocationEvery2Weeks:
+		return entry.Every2Weeks
+	case models.AllocationTwiceMonthly:
+		return entry.TwiceMonthly
+	case models.AllocationMonthly:
+		return entry.Monthly
+	}
+	return 0
+}
+
+// incomeColumns adds the income sources without an allocation to the name to column map, so their
+// deposits are entered in their own column like any other transaction, eg. a reimbursement
+func (ss *SheetsService) incomeColumns(columns []models.Column, transNameToColName map[string]string) map[string]string {
+	names := make(map[string]string, len(transNameToColName))
+	for k, v := range transNameToColName {
+		names[k] = v
+	}
+	for _, src := range ss.Income.All() {
+		if hasAllocation(&src) {
+			continue
+		}
+		for _, col := range columns {
+			if col.ColumnIndex == src.ColumnIndex {
+				names[src.Name] = col.Name
+			}
+		}
+	}
+	return names
 }
 
 func getRegisterToDeltaReadRange(i int64) string {
 	return fmt.Sprintf("%s!%s%d:%s%d", "Register", RegisterColumn, i+1, DeltaColumn, i+1)
 }
 
-func getBackgroundColor(trans *models.Transaction) string {
-	if isPaycheck(trans.Name) {
-		return "green"
+func (ss *SheetsService) getBackgroundColor(trans *models.Transaction) string {
+	if src := ss.incomeSource(trans.Name); src != nil {
+		return src.Color
 	} else if trans.TaxDeductible {
 		return "yellow"
 	}
@@ -258,7 +303,7 @@ func getNumberFormatDollars() *sheets.NumberFormat {
 
 func getCellDataDate(dateString, align, colorName string, borders bool) (*sheets.CellData, error) {
 	dateString = formatYear(dateString)
-	csvTime, err := time.Parse("01/02/06", dateString)
+	csvTime, err := time.Parse(models.RegisterDateFormat, dateString)
 	if err != nil {
 		return nil, fmt.Errorf("could not parse date string: %s: %s", dateString, err.Error())
 	}
@@ -290,6 +335,15 @@ func formatYear(date string) string {
 	return re.ReplaceAllString(date, "${1}/${2}")
 }
 
+// shiftFormulas moves the relative row references in each formula down by offset rows
+func shiftFormulas(formulas []string, offset int64) []string {
+	shifted := make([]string, 0, len(formulas))
+	for _, f := range formulas {
+		shifted = append(shifted, sheets_provider.ShiftFormula(f, offset))
+	}
+	return shifted
+}
+
 func readStringValue(text interface{}) string {
 	return fmt.Sprintf("%v", text)
 }
@@ -390,6 +444,15 @@ func sortAggregateMapKeys(aggMap *map[string]map[string]float64) *[]string {
 	return &keys
 }
 
+// columnLetter returns the letter of the column at the 0-based index, eg. 0 is A and 26 is AA
+func columnLetter(index int) string {
+	letter := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		letter = string(rune('A'+(index-1)%26)) + letter
+	}
+	return letter
+}
+
 func isCreditCardTransaction(source, colName string) bool {
 	if source != CheckingAccountSourceName && colName == CreditCardColumnName {
 		return true
@@ -2,8 +2,8 @@ package sheets_service
 
 import (
 	"encoding/json"
-	"os"
 	"reflect"
+	"register/pkg/income"
 	"register/pkg/models"
 	"testing"
 
@@ -39,7 +39,7 @@ func Test_hasNote(t *testing.T) {
 	}
 }
 
-func Test_isPaycheck(t *testing.T) {
+func Test_incomeSource(t *testing.T) {
 	type args struct {
 		name string
 	}
@@ -49,20 +49,44 @@ func Test_isPaycheck(t *testing.T) {
 		want bool
 	}{
 		{
-			name: "Test isPaycheck is true",
-			args: args{name: PayCheckName},
+			name: "Test incomeSource finds the paycheck",
+			args: args{name: income.Default[0].Name},
 			want: true,
 		},
 		{
-			name: "Test isPaycheck is false",
+			name: "Test incomeSource is nil",
 			args: args{name: "Amazon"},
 			want: false,
 		},
 	}
+	ss := &SheetsService{}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := isPaycheck(tt.args.name); got != tt.want {
-				t.Errorf("isPaycheck() = %+v, want %+v", got, tt.want)
+			if got := ss.incomeSource(tt.args.name) != nil; got != tt.want {
+				t.Errorf("incomeSource() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_allocation(t *testing.T) {
+	entry := &BudgetEntry{Category: "Groceries", Weekly: 100, Every2Weeks: 200, TwiceMonthly: 216.67, Monthly: 433.33}
+	tests := []struct {
+		name  string
+		src   models.IncomeSource
+		entry *BudgetEntry
+		want  float64
+	}{
+		{name: "Test every 2 weeks", src: models.IncomeSource{Allocation: models.AllocationEvery2Weeks}, entry: entry, want: 200},
+		{name: "Test twice monthly", src: models.IncomeSource{Allocation: models.AllocationTwiceMonthly}, entry: entry, want: 216.67},
+		{name: "Test own amount", src: models.IncomeSource{Allocation: models.AllocationMonthly, Allocations: map[string]float64{"Groceries": 50}}, entry: entry, want: 50},
+		{name: "Test no budget entry", src: models.IncomeSource{Allocation: models.AllocationWeekly}, entry: nil, want: 0},
+		{name: "Test no allocation", src: models.IncomeSource{}, entry: entry, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allocation(&tt.src, "Groceries", tt.entry); got != tt.want {
+				t.Errorf("allocation() = %+v, want %+v", got, tt.want)
 			}
 		})
 	}
@@ -103,7 +127,7 @@ func Test_getBackgroundColor(t *testing.T) {
 	}{
 		{
 			name: "Test getBackgroundColor for paycheck",
-			args: args{trans: &models.Transaction{Name: PayCheckName}},
+			args: args{trans: &models.Transaction{Name: income.Default[0].Name}},
 			want: "green",
 		},
 		{
@@ -112,9 +136,10 @@ func Test_getBackgroundColor(t *testing.T) {
 			want: "white",
 		},
 	}
+	ss := &SheetsService{}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := getBackgroundColor(tt.args.trans); got != tt.want {
+			if got := ss.getBackgroundColor(tt.args.trans); got != tt.want {
 				t.Errorf("getBackgroundColor() = %+v, want %+v", got, tt.want)
 			}
 		})
@@ -1142,7 +1167,7 @@ func Test_addWithdrawal(t *testing.T) {
 	}{
 		{
 			name: "Test add a withdrawal row entry",
-			args: args{amount: -v, bgColor: "white", cells: []*sheets.CellData{}},
+			args: args{amount: v, bgColor: "white", cells: []*sheets.CellData{}},
 			want: want,
 		},
 	}
@@ -1287,7 +1312,7 @@ func Test_addCheckingTransaction(t *testing.T) {
 		}}
 
 	type args struct {
-		amount  float64
+		trans   *models.Transaction
 		bgColor string
 		cells   []*sheets.CellData
 	}
@@ -1298,25 +1323,25 @@ func Test_addCheckingTransaction(t *testing.T) {
 	}{
 		{
 			name: "Test add a deposit checking row entry",
-			args: args{amount: v, bgColor: "white", cells: []*sheets.CellData{}},
+			args: args{trans: &models.Transaction{Deposit: v}, bgColor: "white", cells: []*sheets.CellData{}},
 			want: wantDeposit,
 		},
 		{
 			name: "Test add a withdrawal checking row entry",
-			args: args{amount: -v, bgColor: "white", cells: []*sheets.CellData{}},
+			args: args{trans: &models.Transaction{Withdrawal: v}, bgColor: "white", cells: []*sheets.CellData{}},
 			want: wantWithdrawal,
 		},
 	}
 	tt := tests[0]
 	t.Run(tt.name, func(t *testing.T) {
-		if got := addCheckingTransaction(tt.args.amount, tt.args.bgColor, tt.args.cells); !reflect.DeepEqual(got, tt.want) {
+		if got := addCheckingTransaction(tt.args.trans, tt.args.bgColor, tt.args.cells); !reflect.DeepEqual(got, tt.want) {
 			t.Errorf("addCheckingTransaction() = %v, want %v",
 				got[1].UserEnteredValue.NumberValue, tt.want[1].UserEnteredValue.NumberValue)
 		}
 	})
 	tt = tests[1]
 	t.Run(tt.name, func(t *testing.T) {
-		if got := addCheckingTransaction(tt.args.amount, tt.args.bgColor, tt.args.cells); !reflect.DeepEqual(got, tt.want) {
+		if got := addCheckingTransaction(tt.args.trans, tt.args.bgColor, tt.args.cells); !reflect.DeepEqual(got, tt.want) {
 			t.Errorf("addCheckingTransaction() = %v, want %v",
 				got[0].UserEnteredValue.NumberValue, tt.want[0].UserEnteredValue.NumberValue)
 		}
@@ -1391,7 +1416,7 @@ func Test_addCCTransaction(t *testing.T) {
 	}
 
 	type args struct {
-		amount  float64
+		trans   *models.Transaction
 		bgColor string
 		cells   []*sheets.CellData
 	}
@@ -1402,13 +1427,13 @@ func Test_addCCTransaction(t *testing.T) {
 	}{
 		{
 			name: "Test add a credit card row entry",
-			args: args{amount: -v, bgColor: "white", cells: []*sheets.CellData{}},
+			args: args{trans: &models.Transaction{CreditPurchase: v}, bgColor: "white", cells: []*sheets.CellData{}},
 			want: want,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := addCCTransaction(tt.args.amount, tt.args.bgColor, tt.args.cells); !reflect.DeepEqual(got, tt.want) {
+			if got := addCCTransaction(tt.args.trans, tt.args.bgColor, tt.args.cells); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("addCCTransaction() = %v, want %v",
 					got[2].UserEnteredValue.NumberValue, tt.want[2].UserEnteredValue.NumberValue)
 			}
@@ -1620,7 +1645,7 @@ func Test_getSourceField(t *testing.T) {
 		{
 			name: "Test get source field",
 			args: args{values: values},
-			want: "Fidelity",
+			want: "fidelity",
 		},
 	}
 	for _, tt := range tests {
@@ -1680,7 +1705,7 @@ func Test_getAmountString(t *testing.T) {
 		{
 			name: "Test get amount field",
 			args: args{values: values},
-			want: "-123.45",
+			want: "123.45",
 		},
 	}
 	for _, tt := range tests {
@@ -1710,7 +1735,7 @@ func Test_getTransactionKey1(t *testing.T) {
 		{
 			name: "Test get transaction key",
 			args: args{values: values},
-			want: "Fidelity:01/02/23:-123.45",
+			want: "fidelity:01/02/23:123.45",
 		},
 	}
 	for _, tt := range tests {
@@ -1851,8 +1876,9 @@ func Test_addAmountCell(t *testing.T) {
 	v := 101.56
 
 	transWithdrawal := models.Transaction{
-		Source: CheckingAccountSourceName,
-		Amount: -v,
+		Source:     CheckingAccountSourceName,
+		Amount:     -v,
+		Withdrawal: v,
 	}
 	wantWithdrawal := []*sheets.CellData{
 		{
@@ -1918,8 +1944,9 @@ func Test_addAmountCell(t *testing.T) {
 		}}
 
 	transDeposit := models.Transaction{
-		Source: CheckingAccountSourceName,
-		Amount: v,
+		Source:  CheckingAccountSourceName,
+		Amount:  v,
+		Deposit: v,
 	}
 	wantDeposit := []*sheets.CellData{
 		{
@@ -1986,9 +2013,9 @@ func Test_addAmountCell(t *testing.T) {
 	}
 
 	transCC := models.Transaction{
-		Source:     "Fidelity",
-		Amount:     v,
-		CreditCard: -v,
+		Source:         "Fidelity",
+		Amount:         -v,
+		CreditPurchase: v,
 	}
 	wantCC := []*sheets.CellData{
 		{
@@ -2081,49 +2108,35 @@ func Test_addAmountCell(t *testing.T) {
 			want: wantCC,
 		},
 	}
-	tt := tests[0]
-	t.Run(tt.name, func(t *testing.T) {
-		if got := addAmountCell(tt.args.cells, tt.args.trans, tt.args.bgColor); !reflect.DeepEqual(got, tt.want) {
-			t.Errorf("addCheckingTransaction() = %v, want %v",
-				got[1].UserEnteredValue.NumberValue, tt.want[0].UserEnteredValue.NumberValue)
-		}
-	})
-	tt = tests[1]
-	t.Run(tt.name, func(t *testing.T) {
-		if got := addAmountCell(tt.args.cells, tt.args.trans, tt.args.bgColor); !reflect.DeepEqual(got, tt.want) {
-			t.Errorf("addCheckingTransaction() = %v, want %v",
-				got[0].UserEnteredValue.NumberValue, tt.want[1].UserEnteredValue.NumberValue)
-		}
-	})
-	tt = tests[2]
-	t.Run(tt.name, func(t *testing.T) {
-		if got := addAmountCell(tt.args.cells, tt.args.trans, tt.args.bgColor); !reflect.DeepEqual(got, tt.want) {
-			t.Errorf("addCheckingTransaction() = %v, want %v",
-				got[0].UserEnteredValue.NumberValue, tt.want[3].UserEnteredValue.NumberValue)
-		}
-	})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := addAmountCell(tt.args.cells, tt.args.trans, tt.args.bgColor); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("addAmountCell() = %v, want %v", got, tt.want)
+			}
+		})
+	}
 }
 
 func Test_addCategoryCells(t *testing.T) {
-	j, err := os.ReadFile(sheetsServiceJSONDir + "transactions.json")
+	j, err := readFixture(t, sheetsServiceJSONDir+"transactions.json")
 	checkTestingError(t, err)
 	var trans []models.Transaction
 	err = json.Unmarshal(j, &trans)
 	checkTestingError(t, err)
 
-	j, err = os.ReadFile(sheetsServiceJSONDir + "columns.json")
+	j, err = readFixture(t, sheetsServiceJSONDir+"columns.json")
 	checkTestingError(t, err)
 	var columns []models.Column
 	err = json.Unmarshal(j, &columns)
 	checkTestingError(t, err)
 
-	j, err = os.ReadFile(sheetsServiceJSONDir + "transNameToColName.json")
+	j, err = readFixture(t, sheetsServiceJSONDir+"transNameToColName.json")
 	checkTestingError(t, err)
 	var transNameToColName map[string]string
 	err = json.Unmarshal(j, &transNameToColName)
 	checkTestingError(t, err)
 
-	j, err = os.ReadFile(sheetsServiceJSONDir + "want_addCategoryCells.json")
+	j, err = readFixture(t, sheetsServiceJSONDir+"want_addCategoryCells.json")
 	checkTestingError(t, err)
 	var want []*sheets.CellData
 	err = json.Unmarshal(j, &want)
@@ -2222,3 +2235,54 @@ func Test_getNameField(t *testing.T) {
 		})
 	}
 }
+
+func Test_shiftFormulas(t *testing.T) {
+	type args struct {
+		formulas []string
+		offset   int64
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "Test shiftFormulas relative references",
+			args: args{formulas: []string{"=SUM(H10-E12+F12)", "=I10-E12", "=H12-I12"}, offset: 4},
+			want: []string{"=SUM(H14-E16+F16)", "=I14-E16", "=H16-I16"},
+		},
+		{
+			name: "Test shiftFormulas absolute references and functions",
+			args: args{formulas: []string{"=H$1+$G12", "=LOG10(H12)"}, offset: 2},
+			want: []string{"=H$1+$G14", "=LOG10(H14)"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shiftFormulas(tt.args.formulas, tt.args.offset); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("shiftFormulas() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_columnLetter(t *testing.T) {
+	tests := []struct {
+		name  string
+		index int
+		want  string
+	}{
+		{name: "Test first column", index: 0, want: "A"},
+		{name: "Test 13 months", index: 13, want: "N"},
+		{name: "Test last single letter", index: 25, want: "Z"},
+		{name: "Test double letters", index: 26, want: "AA"},
+		{name: "Test 3 years of months", index: 37, want: "AL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := columnLetter(tt.index); got != tt.want {
+				t.Errorf("columnLetter() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
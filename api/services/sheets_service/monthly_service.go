@@ -2,8 +2,9 @@ package sheets_service
 
 import (
 	"fmt"
-	"regexp"
-	"register/pkg/banking"
+	"slices"
+	"time"
+
 	"register/pkg/models"
 	repo "register/pkg/repository"
 
@@ -11,7 +12,7 @@ import (
 )
 
 func (ss *SheetsService) UpdateMonthlyCategories(tabName string, catAgg map[string]map[string]float64, columns []models.Column) error {
-	rows := populateMonthlyCategories(catAgg, columns)
+	rows := populateMonthlyCategories(catAgg, columns, ss.incomeNames())
 	id, err := ss.getSheetID(tabName)
 	if err != nil {
 		return fmt.Errorf("error: %s\n", err.Error())
@@ -20,7 +21,7 @@ func (ss *SheetsService) UpdateMonthlyCategories(tabName string, catAgg map[stri
 }
 
 func (ss *SheetsService) UpdateMonthlyPayees(tabName string, catAgg map[string]map[string]float64) error {
-	rows := populateMonthlyPayees(catAgg)
+	rows := populateMonthlyPayees(catAgg, ss.incomeNames())
 	id, err := ss.getSheetID(tabName)
 	if err != nil {
 		return fmt.Errorf("error: %s\n", err.Error())
@@ -59,7 +60,9 @@ func (ss *SheetsService) updateMonthly(sheetID int64, rows []*sheets.RowData) er
 	return nil
 }
 
-func (ss *SheetsService) Aggregate(cols []models.Column) (map[string]map[string]float64, map[string]map[string]float64) {
+// Aggregate sums the Register's entries by month, eg. 2026-09, and category and by month and payee. Only the
+// entries of the years are summed; no years sums them all. Balance forward rows are left out.
+func (ss *SheetsService) Aggregate(cols []models.Column, years []int) (map[string]map[string]float64, map[string]map[string]float64) {
 	// map of register entries by month and category
 	catAgg := make(map[string]map[string]float64)
 
@@ -68,10 +71,9 @@ func (ss *SheetsService) Aggregate(cols []models.Column) (map[string]map[string]
 
 	rangeValues := ss.RegisterSheet.RangeValues
 	for i, r := range ss.RegisterSheet.Register {
-		re := regexp.MustCompile(`(\d\d)/\d\d/20`)
-		m := re.FindStringSubmatch(r.Date)
-		if len(m) > 0 {
-			k := m[1] + "/20"
+		date, err := time.Parse(models.RegisterDateFormat, r.Date)
+		if err == nil && !isBalanceForward(r.Name) && (len(years) == 0 || slices.Contains(years, date.Year())) {
+			k := date.Format(AggregateMonthFormat)
 			if _, ok := payeeAgg[k]; !ok {
 				payeeAgg[k] = make(map[string]float64)
 			}
@@ -81,8 +83,8 @@ func (ss *SheetsService) Aggregate(cols []models.Column) (map[string]map[string]
 				catAgg[k] = make(map[string]float64)
 			}
 
-			if r.Name == banking.PayCheckName {
-				catAgg[k][banking.PayCheckName] += r.Deposit
+			if src := ss.incomeSource(r.Name); src != nil {
+				catAgg[k][src.Name] += r.Deposit
 				continue
 			}
 
@@ -98,7 +100,16 @@ func (ss *SheetsService) Aggregate(cols []models.Column) (map[string]map[string]
 	return catAgg, payeeAgg
 }
 
-func populateMonthlyCategories(catAgg map[string]map[string]float64, cats []models.Column) []*sheets.RowData {
+// incomeNames returns the names of the income sources, each summarized in its own row
+func (ss *SheetsService) incomeNames() []string {
+	var names []string
+	for _, src := range ss.Income.All() {
+		names = append(names, src.Name)
+	}
+	return names
+}
+
+func populateMonthlyCategories(catAgg map[string]map[string]float64, cats []models.Column, incomeNames []string) []*sheets.RowData {
 	var rows []*sheets.RowData
 
 	months := sortAggregateMapKeys(&catAgg)
@@ -110,12 +121,12 @@ func populateMonthlyCategories(catAgg map[string]map[string]float64, cats []mode
 	cNames := repo.ColumnNames(cats)
 
 	// now all the category rows
-	rows = addSummaryRows(rows, catAgg, months, cNames)
+	rows = addSummaryRows(rows, catAgg, months, cNames, incomeNames)
 
 	return rows
 }
 
-func populateMonthlyPayees(payeeAgg map[string]map[string]float64) []*sheets.RowData {
+func populateMonthlyPayees(payeeAgg map[string]map[string]float64, incomeNames []string) []*sheets.RowData {
 	var rows []*sheets.RowData
 
 	// sort the months
@@ -129,12 +140,12 @@ func populateMonthlyPayees(payeeAgg map[string]map[string]float64) []*sheets.Row
 	payees := sortAggregateMapKeys(&payeeAgg)
 
 	// now all the payee rows
-	rows = addSummaryRows(rows, payeeAgg, months, payees)
+	rows = addSummaryRows(rows, payeeAgg, months, payees, incomeNames)
 
 	return rows
 }
 
-func addSummaryRows(rows []*sheets.RowData, aggData map[string]map[string]float64, months, cats *[]string) []*sheets.RowData {
+func addSummaryRows(rows []*sheets.RowData, aggData map[string]map[string]float64, months, cats *[]string, incomeNames []string) []*sheets.RowData {
 	r := 2
 	d := 10
 	numCats := len(*cats) - d
@@ -156,10 +167,10 @@ func addSummaryRows(rows []*sheets.RowData, aggData map[string]map[string]float6
 		}
 
 		// add the totals and average in last 2 columns
-		f := mkCellDataFormula(fmt.Sprintf("=SUM(B%d:L%d) * -1", r, r), "right", bgColor, false)
+		f := mkCellDataFormula(fmt.Sprintf("=SUM(B%d:%s%d) * -1", r, lastMonthColumn(months), r), "right", bgColor, false)
 		f.UserEnteredFormat.TextFormat.Bold = true
 		cells = append(cells, f)
-		f = mkCellDataFormula(fmt.Sprintf("=AVERAGE(B%d:L%d) * -1", r, r), "right", bgColor, false)
+		f = mkCellDataFormula(fmt.Sprintf("=AVERAGE(B%d:%s%d) * -1", r, lastMonthColumn(months), r), "right", bgColor, false)
 		f.UserEnteredFormat.TextFormat.Bold = true
 		cells = append(cells, f)
 
@@ -170,11 +181,18 @@ func addSummaryRows(rows []*sheets.RowData, aggData map[string]map[string]float6
 		row := &sheets.RowData{Values: cells}
 		rows = append(rows, row)
 	}
-	row := addSummarySalaryRow(r, months, aggData)
-	rows = append(rows, row)
+	for _, name := range incomeNames {
+		rows = append(rows, addSummarySalaryRow(r, months, aggData, name))
+		r++
+	}
 	return rows
 }
 
+// lastMonthColumn returns the letter of the summary tabs' last month column; the months start in column B
+func lastMonthColumn(months *[]string) string {
+	return columnLetter(len(*months))
+}
+
 func addSummaryTopRow(months *[]string) *sheets.RowData {
 	bgColor := "grey"
 
@@ -190,31 +208,31 @@ func addSummaryTopRow(months *[]string) *sheets.RowData {
 		cells = append(cells, mkBoldFormat(m, "center", bgColor, false))
 	}
 	// summary columns
-	cells = append(cells, mkBoldFormat("Yearly Totals", "center", bgColor, false))
+	cells = append(cells, mkBoldFormat("Totals", "center", bgColor, false))
 	cells = append(cells, mkBoldFormat("Monthly Average", "center", bgColor, false))
 
 	// add the cells to the row
 	return &sheets.RowData{Values: cells}
 }
 
-func addSummarySalaryRow(rNum int, months *[]string, aggData map[string]map[string]float64) *sheets.RowData {
+func addSummarySalaryRow(rNum int, months *[]string, aggData map[string]map[string]float64, name string) *sheets.RowData {
 	bgColor := "grey"
 	var cells []*sheets.CellData
 
 	// 1st column: category name
-	cells = append(cells, mkBoldFormat(banking.PayCheckName, "left", bgColor, false))
+	cells = append(cells, mkBoldFormat(name, "left", bgColor, false))
 
 	// remaining columns: $value for each month
 	for i := 0; i < len(*months); i++ {
 		m := (*months)[i]
-		cells = append(cells, mkCellDataDollars(aggData[m][banking.PayCheckName], "right", bgColor, false))
+		cells = append(cells, mkCellDataDollars(aggData[m][name], "right", bgColor, false))
 	}
 
 	// add the totals and average in last 2 columns
-	f := mkCellDataFormula(fmt.Sprintf("=SUM(B%d:L%d) * -1", rNum, rNum), "right", bgColor, false)
+	f := mkCellDataFormula(fmt.Sprintf("=SUM(B%d:%s%d) * -1", rNum, lastMonthColumn(months), rNum), "right", bgColor, false)
 	f.UserEnteredFormat.TextFormat.Bold = true
 	cells = append(cells, f)
-	f = mkCellDataFormula(fmt.Sprintf("=AVERAGE(B%d:L%d) * -1", rNum, rNum), "right", bgColor, false)
+	f = mkCellDataFormula(fmt.Sprintf("=AVERAGE(B%d:%s%d) * -1", rNum, lastMonthColumn(months), rNum), "right", bgColor, false)
 	f.UserEnteredFormat.TextFormat.Bold = true
 	cells = append(cells, f)
 
@@ -2,7 +2,6 @@ package sheets_service
 
 import (
 	"fmt"
-	"log"
 	"register/pkg/config"
 	"register/pkg/models"
 
@@ -48,8 +47,13 @@ type RegisterSheet struct {
 // Public methods
 
 func (ss *SheetsService) NewRegisterSheet(cfg *config.Config) error {
+	return ss.NewRegisterTab(cfg, RegisterTabName)
+}
+
+// NewRegisterTab is NewRegisterSheet for a tab laid out like the Register, eg. an archived "Register 2026"
+func (ss *SheetsService) NewRegisterTab(cfg *config.Config, tabName string) error {
 	ss.RegisterSheet = &RegisterSheet{
-		TabName: "Register",
+		TabName: tabName,
 		SheetCoords: SheetCoords{
 			StartRow:       cfg.RegisterStartRow,
 			EndRow:         cfg.RegisterEndRow,
@@ -58,7 +62,7 @@ func (ss *SheetsService) NewRegisterSheet(cfg *config.Config) error {
 		},
 	}
 
-	id, err := ss.getSheetID("Register")
+	id, err := ss.getSheetID(tabName)
 	if err != nil {
 		return fmt.Errorf("unable to retrieve spreadsheet: %v", err)
 	}
@@ -82,10 +86,13 @@ func (ss *SheetsService) ReadRegisterSheet() (*RegisterSheet, error) {
 	// determine last used row in the spreadsheet
 	ss.RegisterSheet.SheetCoords.LastRow = ss.getLastRow(resp.Values)
 	keysMap := make(map[string]bool)
+	occurrences := make(map[string]int)
 
 	for i = 0; i <= ss.RegisterSheet.SheetCoords.LastRow && !ss.isEmptyRow(resp.Values[i]); i += 2 {
+		// entries with the same key, eg. splits of the same amount, are keyed by their occurrence
 		transactionKey := getTransactionKey(resp.Values[i])
-		keysMap[transactionKey] = true
+		occurrences[transactionKey]++
+		keysMap[models.OccurrenceKey(transactionKey, occurrences[transactionKey])] = true
 		registerEntry := ss.populateRegisterEntry(resp.Values[i])
 		registerEntry.RowID = ss.getRowID(i)
 		register = append(register, registerEntry)
@@ -215,20 +222,29 @@ func (ss *SheetsService) getGridCoordinate() *sheets.GridCoordinate {
 func (ss *SheetsService) populateCells(columns []models.Column, transNameToColName map[string]string, transactions []*models.Transaction) ([]*sheets.RowData, error) {
 	var rows []*sheets.RowData
 
-	rowIndex := ss.RegisterSheet.SheetCoords.FirstRowToUpdate
+	// the copied rows all share the same Register, Cleared & Delta formulas. Read them once from the
+	// first row to update and shift the row references for each following transaction.
+	firstRow := ss.RegisterSheet.SheetCoords.FirstRowToUpdate
+	templateFormulas, err := ss.readRangeFormulas(getRegisterToDeltaReadRange(firstRow))
+	if err != nil {
+		return nil, err
+	}
+
+	transNameToColName = ss.incomeColumns(columns, transNameToColName)
+	rowIndex := firstRow
 	for _, trans := range transactions {
 		var cells []*sheets.CellData
 
-		bgColor := getBackgroundColor(trans)
+		bgColor := ss.getBackgroundColor(trans)
 		cells, err := addSourceDateNameCells(cells, trans, bgColor)
 		if err != nil {
 			return nil, err
 		}
 		cells = addAmountCell(cells, trans, bgColor)
 
-		totalsFormulas := ss.readRangeFormulas(getRegisterToDeltaReadRange(rowIndex))
-		if isPaycheck(trans.Name) {
-			cells = ss.addSalaryCells(cells, columns, totalsFormulas)
+		totalsFormulas := shiftFormulas(templateFormulas, rowIndex-firstRow)
+		if src := ss.incomeSource(trans.Name); src != nil && hasAllocation(src) {
+			cells = ss.addSalaryCells(cells, columns, totalsFormulas, src)
 		} else {
 			cells = addCategoryCells(cells, trans, columns, transNameToColName, totalsFormulas)
 		}
@@ -259,7 +275,8 @@ func (ss *SheetsService) makeNoteRow(note string) *sheets.RowData {
 	}
 }
 
-func (ss *SheetsService) addSalaryCells(cells []*sheets.CellData, columns []models.Column, totalsFormulas []string) []*sheets.CellData {
+// addSalaryCells enters the income source's allocation in each budget category column
+func (ss *SheetsService) addSalaryCells(cells []*sheets.CellData, columns []models.Column, totalsFormulas []string, src *models.IncomeSource) []*sheets.CellData {
 	// colOffset is because we've already taken care of cols A-G (0-6)
 	colOffset := BankRegister
 
@@ -271,9 +288,9 @@ func (ss *SheetsService) addSalaryCells(cells []*sheets.CellData, columns []mode
 		if isRegisterClearedOrDeltaColumn(i) {
 			// first 3 columns are Register, Cleared & Delta. We copied the cell formulas above and are pasting here
 			cells = append(cells, mkCellDataFormula(totalsFormulas[i], "right", col.Color, false))
-		} else if isBudgetColumn(col.Name) && entry != nil {
-			// enter the budgeted amount in this category column
-			cells = append(cells, mkCellDataDollars(entry.Every2Weeks, "left", col.Color, true))
+		} else if isBudgetColumn(col.Name) {
+			// enter the allocated amount in this category column
+			cells = append(cells, mkCellDataDollars(allocation(src, col.Name, entry), "left", col.Color, true))
 		} else {
 			// this cell doesn't apply. Just create an empty (opaque) cell.
 			cells = append(cells, mkCellDataDollars(0.00, "left", col.Color, true))
@@ -282,6 +299,22 @@ func (ss *SheetsService) addSalaryCells(cells []*sheets.CellData, columns []mode
 	return cells
 }
 
+// Allocations returns the amounts recording the income source enters in the budget category columns, by
+// column name. They are the amounts addSalaryCells writes; the Budget sheet must have been read.
+func (ss *SheetsService) Allocations(src *models.IncomeSource, columns []models.Column) map[string]float64 {
+	amounts := make(map[string]float64)
+	for i := BankRegister; i < len(columns); i++ {
+		col := columns[i]
+		if isRegisterClearedOrDeltaColumn(i-BankRegister) || !isBudgetColumn(col.Name) {
+			continue
+		}
+		if amount := allocation(src, col.Name, ss.BudgetSheet.CategoriesMap[col.Name]); amount != 0 {
+			amounts[col.Name] = amount
+		}
+	}
+	return amounts
+}
+
 func (ss *SheetsService) isEmptyRow(values []interface{}) bool {
 	if values[Date] == "" {
 		return true
@@ -335,7 +368,7 @@ func (ss *SheetsService) getSheetID(tabName string) (int64, error) {
 			return p.SheetId, nil
 		}
 	}
-	return 0, fmt.Errorf("could not get sheet id: %v", err)
+	return 0, fmt.Errorf("could not get sheet id: no tab named %s", tabName)
 }
 
 func (ss *SheetsService) copyRowsBatchUpdateRequest(numCopies int) sheets.BatchUpdateSpreadsheetRequest {
@@ -381,19 +414,19 @@ func (ss *SheetsService) getCopyDestination(index int64) *sheets.GridRange {
 	}
 }
 
-func (ss *SheetsService) readRangeFormulas(readRange string) []string {
+func (ss *SheetsService) readRangeFormulas(readRange string) ([]string, error) {
 	resp, err := ss.Provider.GetFormula(readRange)
 	if err != nil {
-		log.Fatalf("unable to retrieve data from sheet: %v", err)
+		return nil, fmt.Errorf("unable to retrieve data from sheet: %v", err)
 	}
 	rangeValues := resp.Values
 	if len(rangeValues) == 0 {
-		log.Fatalf("no data found for read range: %s", readRange)
+		return nil, fmt.Errorf("no data found for read range: %s", readRange)
 	}
 
 	var retValues []string
 	for _, val := range rangeValues[0] {
 		retValues = append(retValues, fmt.Sprintf("%v", val))
 	}
-	return retValues
+	return retValues, nil
 }
@@ -0,0 +1,212 @@
+package sheets_service
+
+import (
+	"fmt"
+	"strings"
+
+	"register/api/providers/sheets_provider"
+	"register/pkg/config"
+	"register/pkg/models"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+const (
+	BudgetTabName            = "Budget"
+	RegisterTabName          = "Register"
+	MonthlyCategoriesTabName = "MonthlyCategories"
+	MonthlyPayeesTabName     = "MonthlyPayees"
+)
+
+var requiredTabs = []string{RegisterTabName, BudgetTabName, MonthlyCategoriesTabName, MonthlyPayeesTabName}
+
+// SchemaCheck is the result of a single spreadsheet schema validation
+type SchemaCheck struct {
+	Name    string
+	Passed  bool
+	Details []string
+}
+
+// SchemaReport holds the results of all spreadsheet schema validations
+type SchemaReport struct {
+	Checks []*SchemaCheck
+}
+
+// Passed returns true if every check in the report passed
+func (r *SchemaReport) Passed() bool {
+	for _, c := range r.Checks {
+		if !c.Passed {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *SchemaReport) add(name string, details []string) {
+	r.Checks = append(r.Checks, &SchemaCheck{
+		Name:    name,
+		Passed:  len(details) == 0,
+		Details: details,
+	})
+}
+
+// CheckSchema verifies that the spreadsheet matches what the register, budget and monthly services expect
+func (ss *SheetsService) CheckSchema(cfg *config.Config, columns []models.Column) (*SchemaReport, error) {
+	report := &SchemaReport{}
+
+	spreadsheet, err := ss.Provider.GetSpreadsheet()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve spreadsheet: %v", err)
+	}
+	tabs := make(map[string]*sheets.SheetProperties)
+	for _, sheet := range spreadsheet.Sheets {
+		tabs[sheet.Properties.Title] = sheet.Properties
+	}
+
+	report.add("Required tabs exist", checkRequiredTabs(tabs))
+	report.add("Register row range", checkRowRange(tabs[RegisterTabName], cfg.RegisterStartRow, cfg.RegisterEndRow))
+	report.add("Budget row range", checkRowRange(tabs[BudgetTabName], cfg.BudgetStartRow, cfg.BudgetEndRow))
+	if tabs[RegisterTabName] == nil || tabs[BudgetTabName] == nil {
+		return report, nil
+	}
+
+	err = ss.NewRegisterSheet(cfg)
+	if err != nil {
+		return nil, err
+	}
+	_, err = ss.ReadRegisterSheet()
+	if err != nil {
+		report.add("Register formulas (H-J)", []string{err.Error()})
+	} else {
+		report.add("Register formulas (H-J)", ss.checkRegisterFormulas())
+	}
+	report.add("Register columns match DB columns", ss.checkRegisterColumns(cfg, columns))
+
+	err = ss.NewBudgetSheet(cfg)
+	if err != nil {
+		return nil, err
+	}
+	_, err = ss.ReadBudgetSheet()
+	if err != nil {
+		report.add("Budget categories match DB columns", []string{err.Error()})
+	} else {
+		report.add("Budget categories match DB columns", checkBudgetCategories(ss.BudgetSheet.BudgetEntries, columns))
+	}
+	return report, nil
+}
+
+func checkRequiredTabs(tabs map[string]*sheets.SheetProperties) []string {
+	var details []string
+	for _, name := range requiredTabs {
+		if _, ok := tabs[name]; !ok {
+			details = append(details, fmt.Sprintf("missing tab: %s", name))
+		}
+	}
+	return details
+}
+
+func checkRowRange(tab *sheets.SheetProperties, startRow, endRow int64) []string {
+	var details []string
+	if startRow < 1 {
+		details = append(details, fmt.Sprintf("start row %d must be greater than 0", startRow))
+	}
+	if endRow != 0 && endRow <= startRow {
+		details = append(details, fmt.Sprintf("end row %d must be greater than start row %d", endRow, startRow))
+	}
+	if tab != nil && tab.GridProperties != nil {
+		if startRow > tab.GridProperties.RowCount {
+			details = append(details, fmt.Sprintf("start row %d is beyond the %d rows in %s", startRow, tab.GridProperties.RowCount, tab.Title))
+		}
+		if endRow > tab.GridProperties.RowCount {
+			details = append(details, fmt.Sprintf("end row %d is beyond the %d rows in %s", endRow, tab.GridProperties.RowCount, tab.Title))
+		}
+	}
+	return details
+}
+
+// checkRegisterFormulas compares the Register, Cleared and Delta formulas of the last transaction row with the
+// transaction row above it. The formulas are copied down when rows are added, so they should only differ by row number.
+func (ss *SheetsService) checkRegisterFormulas() []string {
+	lastRow := ss.RegisterSheet.SheetCoords.LastRow - 1
+	last, err := ss.readRangeFormulas(getRegisterToDeltaReadRange(lastRow))
+	if err != nil {
+		return []string{err.Error()}
+	}
+	prev, err := ss.readRangeFormulas(getRegisterToDeltaReadRange(lastRow - 2))
+	if err != nil {
+		return []string{err.Error()}
+	}
+	return compareFormulaRows(last, lastRow+1, prev, lastRow-1)
+}
+
+func compareFormulaRows(last []string, lastRow int64, prev []string, prevRow int64) []string {
+	var details []string
+	columns := []string{"H", "I", "J"}
+	for i, col := range columns {
+		if i >= len(last) || !strings.HasPrefix(last[i], "=") {
+			details = append(details, fmt.Sprintf("%s%d is not a formula", col, lastRow))
+			continue
+		}
+		if i >= len(prev) {
+			continue
+		}
+		if sheets_provider.ShiftFormula(prev[i], lastRow-prevRow) != last[i] {
+			details = append(details, fmt.Sprintf("%s%d formula %q does not follow %s%d formula %q", col, lastRow, last[i], col, prevRow, prev[i]))
+		}
+	}
+	return details
+}
+
+// checkRegisterColumns compares the Register header row, the row above RegisterStartRow, with the DB columns
+func (ss *SheetsService) checkRegisterColumns(cfg *config.Config, columns []models.Column) []string {
+	headerRow := cfg.RegisterStartRow - 1
+	readRange := fmt.Sprintf("%s!A%d:%s%d", RegisterTabName, headerRow, cfg.RegisterCategoryEndColumn, headerRow)
+	resp, err := ss.Provider.GetValues(readRange)
+	if err != nil {
+		return []string{fmt.Sprintf("could not read header row %d: %s", headerRow, err.Error())}
+	}
+	var header []interface{}
+	if len(resp.Values) > 0 {
+		header = resp.Values[0]
+	}
+	return compareColumns(header, columns)
+}
+
+func compareColumns(header []interface{}, columns []models.Column) []string {
+	var details []string
+	for _, col := range columns {
+		if strings.HasPrefix(col.Name, "old-") {
+			continue
+		}
+		name := strings.TrimSpace(getStringField(header, col.ColumnIndex))
+		if name == "" {
+			details = append(details, fmt.Sprintf("DB column %q (index %d) has no sheet column", col.Name, col.ColumnIndex))
+		} else if !strings.EqualFold(name, col.Name) {
+			details = append(details, fmt.Sprintf("DB column %q (index %d) does not match sheet column %q", col.Name, col.ColumnIndex, name))
+		}
+	}
+	return details
+}
+
+func checkBudgetCategories(entries []*BudgetEntry, columns []models.Column) []string {
+	var details []string
+	categories := make(map[string]bool)
+	for _, col := range columns {
+		if col.IsCategory {
+			categories[col.Name] = true
+		}
+	}
+	budgeted := make(map[string]bool)
+	for _, entry := range entries {
+		budgeted[entry.Category] = true
+		if !categories[entry.Category] {
+			details = append(details, fmt.Sprintf("budget category %q has no DB column", entry.Category))
+		}
+	}
+	for _, col := range columns {
+		if col.IsCategory && isBudgetColumn(col.Name) && !budgeted[col.Name] {
+			details = append(details, fmt.Sprintf("DB column %q has no budget category", col.Name))
+		}
+	}
+	return details
+}
@@ -0,0 +1,148 @@
+package sheets_service
+
+import (
+	"reflect"
+	"testing"
+
+	"register/pkg/models"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+func Test_compareFormulaRows(t *testing.T) {
+	type args struct {
+		last    []string
+		lastRow int64
+		prev    []string
+		prevRow int64
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "Test compareFormulaRows pattern holds",
+			args: args{
+				last:    []string{"=H10-E12+F12", "=I10-E12", "=H12-I12"},
+				lastRow: 12,
+				prev:    []string{"=H8-E10+F10", "=I8-E10", "=H10-I10"},
+				prevRow: 10,
+			},
+			want: nil,
+		},
+		{
+			name: "Test compareFormulaRows absolute references",
+			args: args{
+				last:    []string{"=H10-E12+F12", "=I10-E12*$K$1", "=H12-I$2"},
+				lastRow: 12,
+				prev:    []string{"=H8-E10+F10", "=I8-E10*$K$1", "=H10-I$2"},
+				prevRow: 10,
+			},
+			want: nil,
+		},
+		{
+			name: "Test compareFormulaRows broken pattern",
+			args: args{
+				last:    []string{"=H10-E12+F12", "1234.56", "=H12-I10"},
+				lastRow: 12,
+				prev:    []string{"=H8-E10+F10", "=I8-E10", "=H10-I10"},
+				prevRow: 10,
+			},
+			want: []string{
+				"I12 is not a formula",
+				`J12 formula "=H12-I10" does not follow J10 formula "=H10-I10"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareFormulaRows(tt.args.last, tt.args.lastRow, tt.args.prev, tt.args.prevRow); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("compareFormulaRows() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_checkRowRange(t *testing.T) {
+	tab := &sheets.SheetProperties{
+		Title:          "Register",
+		GridProperties: &sheets.GridProperties{RowCount: 100},
+	}
+	type args struct {
+		tab      *sheets.SheetProperties
+		startRow int64
+		endRow   int64
+	}
+	tests := []struct {
+		name string
+		args args
+		want []string
+	}{
+		{
+			name: "Test checkRowRange valid",
+			args: args{tab: tab, startRow: 3, endRow: 100},
+			want: nil,
+		},
+		{
+			name: "Test checkRowRange end before start",
+			args: args{tab: tab, startRow: 30, endRow: 20},
+			want: []string{"end row 20 must be greater than start row 30"},
+		},
+		{
+			name: "Test checkRowRange beyond grid",
+			args: args{tab: tab, startRow: 3, endRow: 200},
+			want: []string{"end row 200 is beyond the 100 rows in Register"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := checkRowRange(tt.args.tab, tt.args.startRow, tt.args.endRow); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checkRowRange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_compareColumns(t *testing.T) {
+	columns := []models.Column{
+		{Name: "Reconciled", ColumnIndex: 0},
+		{Name: "Groceries", ColumnIndex: 11, IsCategory: true},
+		{Name: "Dining", ColumnIndex: 12, IsCategory: true},
+		{Name: "Utilities", ColumnIndex: 13, IsCategory: true},
+	}
+	header := make([]interface{}, 13)
+	for i := range header {
+		header[i] = ""
+	}
+	header[0] = "Reconciled"
+	header[11] = "Groceries"
+	header[12] = "Dining Out"
+
+	want := []string{
+		`DB column "Dining" (index 12) does not match sheet column "Dining Out"`,
+		`DB column "Utilities" (index 13) has no sheet column`,
+	}
+	if got := compareColumns(header, columns); !reflect.DeepEqual(got, want) {
+		t.Errorf("compareColumns() = %v, want %v", got, want)
+	}
+}
+
+func Test_checkBudgetCategories(t *testing.T) {
+	columns := []models.Column{
+		{Name: "Credit Cards", IsCategory: true},
+		{Name: "Groceries", IsCategory: true},
+		{Name: "Dining", IsCategory: true},
+	}
+	entries := []*BudgetEntry{
+		{Category: "Groceries"},
+		{Category: "Gifts"},
+	}
+	want := []string{
+		`budget category "Gifts" has no DB column`,
+		`DB column "Dining" has no budget category`,
+	}
+	if got := checkBudgetCategories(entries, columns); !reflect.DeepEqual(got, want) {
+		t.Errorf("checkBudgetCategories() = %v, want %v", got, want)
+	}
+}
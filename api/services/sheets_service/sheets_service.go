@@ -2,6 +2,7 @@ package sheets_service
 
 import (
 	"register/api/providers/sheets_provider"
+	"register/pkg/income"
 
 	"google.golang.org/api/sheets/v4"
 )
@@ -36,6 +37,7 @@ type SheetsService struct {
 	SpreadsheetID string
 	BudgetSheet   *BudgetSheet
 	RegisterSheet *RegisterSheet
+	Income        *income.Sources // nil for the default income source
 	Debug         bool
 	Verbose       bool
 }
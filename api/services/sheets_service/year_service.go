@@ -0,0 +1,219 @@
+package sheets_service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"register/pkg/models"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+const (
+	AggregateMonthFormat = "2006-01"
+
+	// BalanceForwardName starts the description of the row a closed year's Register entries are folded into
+	BalanceForwardName = "Balance Forward"
+)
+
+// YearClose is the result of closing a year
+type YearClose struct {
+	Year     int
+	Archived []string // the archive tabs created, eg. "Register 2026"
+	Folded   int      // the Register entries folded into the balance forward row
+}
+
+// ArchiveTabName returns the name of a tab archived at the close of the year, eg. "Register 2026"
+func ArchiveTabName(tabName string, year int) string {
+	return fmt.Sprintf("%s %d", tabName, year)
+}
+
+// TabExists returns true if the spreadsheet has a tab with the name
+func (ss *SheetsService) TabExists(tabName string) (bool, error) {
+	spreadsheet, err := ss.Provider.GetSpreadsheet()
+	if err != nil {
+		return false, fmt.Errorf("unable to retrieve spreadsheet: %v", err)
+	}
+	for _, sheet := range spreadsheet.Sheets {
+		if sheet.Properties.Title == tabName {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// CloseYear archives the Register and monthly tabs for the finished year and starts new ones. The Register
+// is copied to "Register <year>" and its entries through the year are folded into one balance forward row,
+// dated the last day of the year, that holds their register balances and category totals; entries of later
+// years stay, so the Register must be sorted by date. The monthly tabs are renamed "<tab> <year>" and
+// replaced with empty ones. The Register must have been read.
+func (ss *SheetsService) CloseYear(year int, columns []models.Column) (*YearClose, error) {
+	spreadsheet, err := ss.Provider.GetSpreadsheet()
+	if err != nil {
+		return nil, fmt.Errorf("unable to retrieve spreadsheet: %v", err)
+	}
+	tabs := make(map[string]int64)
+	for _, sheet := range spreadsheet.Sheets {
+		tabs[sheet.Properties.Title] = sheet.Properties.SheetId
+	}
+	for _, tab := range []string{RegisterTabName, MonthlyCategoriesTabName, MonthlyPayeesTabName} {
+		if _, ok := tabs[ArchiveTabName(tab, year)]; ok {
+			return nil, fmt.Errorf("%d is already closed: the spreadsheet has a %s tab", year, ArchiveTabName(tab, year))
+		}
+	}
+
+	// the entries through the year must come before the later years' entries, which stay
+	last, later := -1, -1
+	for i, r := range ss.RegisterSheet.Register {
+		date, err := time.Parse(models.RegisterDateFormat, r.Date)
+		switch {
+		case err != nil:
+		case date.Year() > year:
+			if later < 0 {
+				later = i
+			}
+		case later >= 0:
+			return nil, fmt.Errorf("the Register is out of order: %s %s is after %s %s; sort it before closing %d",
+				r.Date, r.Name, ss.RegisterSheet.Register[later].Date, ss.RegisterSheet.Register[later].Name, year)
+		default:
+			last = i
+		}
+	}
+	if last < 0 {
+		return nil, fmt.Errorf("the Register has no entries through %d", year)
+	}
+
+	closed := &YearClose{Year: year, Folded: last + 1}
+	requests := []*sheets.Request{{
+		DuplicateSheet: &sheets.DuplicateSheetRequest{
+			SourceSheetId:    ss.RegisterSheet.ID,
+			NewSheetName:     ArchiveTabName(RegisterTabName, year),
+			InsertSheetIndex: int64(len(spreadsheet.Sheets)),
+		},
+	}}
+	closed.Archived = append(closed.Archived, ArchiveTabName(RegisterTabName, year))
+
+	// the balance forward row replaces the year's last entry and the entries before it are deleted
+	forwardRow := ss.RegisterSheet.SheetCoords.StartRow + int64(2*last) - 1
+	cells, err := ss.balanceForwardCells(year, last, columns)
+	if err != nil {
+		return nil, err
+	}
+	requests = append(requests, &sheets.Request{
+		UpdateCells: &sheets.UpdateCellsRequest{
+			Fields: "*",
+			Rows:   []*sheets.RowData{{Values: cells}, ss.makeNoteRow("")},
+			Start:  &sheets.GridCoordinate{SheetId: ss.RegisterSheet.ID, RowIndex: forwardRow},
+		},
+	})
+	if last > 0 {
+		requests = append(requests, &sheets.Request{
+			DeleteDimension: &sheets.DeleteDimensionRequest{
+				Range: &sheets.DimensionRange{
+					SheetId:    ss.RegisterSheet.ID,
+					Dimension:  "ROWS",
+					StartIndex: ss.RegisterSheet.SheetCoords.StartRow - 1,
+					EndIndex:   forwardRow,
+				},
+			},
+		})
+	}
+
+	for _, tab := range []string{MonthlyCategoriesTabName, MonthlyPayeesTabName} {
+		id, ok := tabs[tab]
+		if !ok {
+			continue
+		}
+		requests = append(requests,
+			&sheets.Request{
+				UpdateSheetProperties: &sheets.UpdateSheetPropertiesRequest{
+					Properties: &sheets.SheetProperties{SheetId: id, Title: ArchiveTabName(tab, year)},
+					Fields:     "title",
+				},
+			},
+			&sheets.Request{
+				AddSheet: &sheets.AddSheetRequest{Properties: &sheets.SheetProperties{Title: tab}},
+			},
+		)
+		closed.Archived = append(closed.Archived, ArchiveTabName(tab, year))
+	}
+
+	_, err = ss.Provider.BatchUpdate(&sheets.BatchUpdateSpreadsheetRequest{Requests: requests})
+	if err != nil {
+		return nil, fmt.Errorf("could not close %d: %s", year, err.Error())
+	}
+	return closed, nil
+}
+
+// balanceForwardCells returns the cells of the row the Register entries through last are folded into: the
+// last entry's register balances and each category column's total
+func (ss *SheetsService) balanceForwardCells(year, last int, columns []models.Column) ([]*sheets.CellData, error) {
+	totals := make(map[int]float64)
+	for i := 0; i <= last; i++ {
+		values := ss.RegisterSheet.RangeValues[i*2]
+		for j := Delta + 1; j < len(values) && j < len(columns); j++ {
+			totals[j] += getDollarsCellByIndex(values, j)
+		}
+	}
+
+	bgColor := "white"
+	var cells []*sheets.CellData
+	cells = append(cells, getCellDataReconcileColumn("X", "center", bgColor, false))
+	cells = append(cells, mkCellDataString("", "center", bgColor, false))
+	dateCell, err := getCellDataDate(fmt.Sprintf("12/31/%02d", year%100), "center", bgColor, false)
+	if err != nil {
+		return nil, err
+	}
+	cells = append(cells, dateCell)
+	cells = append(cells, mkCellDataString(fmt.Sprintf("%s %d", BalanceForwardName, year), "left", bgColor, false))
+	for i := Withdrawals; i < BankRegister; i++ {
+		cells = append(cells, mkCellDataString("", "right", bgColor, false))
+	}
+
+	entry := ss.RegisterSheet.Register[last]
+	for j := BankRegister; j < len(columns); j++ {
+		col := columns[j]
+		switch j {
+		case BankRegister:
+			cells = append(cells, mkCellDataDollars(entry.BankRegister, "right", col.Color, false))
+		case Cleared:
+			cells = append(cells, mkCellDataDollars(entry.Cleared, "right", col.Color, false))
+		case Delta:
+			cells = append(cells, mkCellDataDollars(entry.Delta, "right", col.Color, false))
+		default:
+			cells = append(cells, mkCellDataDollars(math.Round(totals[j]*100)/100, "left", col.Color, true))
+		}
+	}
+	return cells, nil
+}
+
+// ClosedYear returns the latest year folded into a balance forward row of the Register; 0 if no year has
+// been closed. The Register must have been read.
+func (ss *SheetsService) ClosedYear() int {
+	closed := 0
+	for _, r := range ss.RegisterSheet.Register {
+		date, err := time.Parse(models.RegisterDateFormat, r.Date)
+		if err == nil && isBalanceForward(r.Name) && date.Year() > closed {
+			closed = date.Year()
+		}
+	}
+	return closed
+}
+
+// MergeAggregate adds the sums of an aggregate, eg. of an archived Register, to another
+func MergeAggregate(into, from map[string]map[string]float64) {
+	for month, sums := range from {
+		if _, ok := into[month]; !ok {
+			into[month] = make(map[string]float64)
+		}
+		for name, sum := range sums {
+			into[month][name] += sum
+		}
+	}
+}
+
+func isBalanceForward(name string) bool {
+	return strings.HasPrefix(name, BalanceForwardName)
+}
@@ -0,0 +1,245 @@
+package sheets_service
+
+import (
+	"reflect"
+	"testing"
+
+	"register/pkg/models"
+
+	"google.golang.org/api/sheets/v4"
+)
+
+// yearProviderMock serves a spreadsheet of the tabs and records the batch update requests
+type yearProviderMock struct {
+	providerMock
+	tabs     []string
+	requests []*sheets.Request
+}
+
+func (p *yearProviderMock) GetSpreadsheet() (*sheets.Spreadsheet, error) {
+	s := &sheets.Spreadsheet{}
+	for i, tab := range p.tabs {
+		s.Sheets = append(s.Sheets, &sheets.Sheet{Properties: &sheets.SheetProperties{SheetId: int64(i), Title: tab}})
+	}
+	return s, nil
+}
+
+func (p *yearProviderMock) BatchUpdate(updateReq *sheets.BatchUpdateSpreadsheetRequest) (*sheets.BatchUpdateSpreadsheetResponse, error) {
+	p.requests = append(p.requests, updateReq.Requests...)
+	return &sheets.BatchUpdateSpreadsheetResponse{}, nil
+}
+
+var yearColumns = []models.Column{
+	{Name: "Reconciled"}, {Name: "Source", ColumnIndex: 1}, {Name: "Date", ColumnIndex: 2},
+	{Name: "Description", ColumnIndex: 3}, {Name: "Withdrawals", ColumnIndex: 4}, {Name: "Deposits", ColumnIndex: 5},
+	{Name: "Credit Cards", ColumnIndex: 6}, {Name: "Bank Register", ColumnIndex: 7}, {Name: "Cleared", ColumnIndex: 8},
+	{Name: "Delta", ColumnIndex: 9}, {Name: "Groceries", ColumnIndex: 10, IsCategory: true},
+	{Name: "Dining", ColumnIndex: 11, IsCategory: true},
+}
+
+type yearEntry struct {
+	date, name        string
+	withdrawal        float64
+	balance           float64
+	groceries, dining string
+}
+
+// newYearService returns a service whose Register, starting at row 3, has the entries
+func newYearService(p *yearProviderMock, entries []yearEntry) *SheetsService {
+	s := New(p)
+	s.RegisterSheet = &RegisterSheet{
+		TabName:     RegisterTabName,
+		SheetCoords: SheetCoords{StartRow: 3, EndColumnIndex: 11},
+	}
+	for _, e := range entries {
+		s.RegisterSheet.Register = append(s.RegisterSheet.Register, &RegisterEntry{
+			Date: e.date, Name: e.name, Withdrawal: e.withdrawal, BankRegister: e.balance, Cleared: e.balance,
+		})
+		s.RegisterSheet.RangeValues = append(s.RegisterSheet.RangeValues,
+			[]interface{}{"X", "WellsFargo", e.date, e.name, e.withdrawal, "", "", e.balance, e.balance, 0, e.groceries, e.dining},
+			[]interface{}{})
+	}
+	return s
+}
+
+func TestSheetsService_CloseYear(t *testing.T) {
+	tabs := []string{RegisterTabName, BudgetTabName, MonthlyCategoriesTabName}
+	tests := []struct {
+		name        string
+		tabs        []string
+		entries     []yearEntry
+		want        *YearClose
+		wantErr     bool
+		forwardRow  int64
+		deleted     []int64 // the start and end index of the rows deleted, none if nil
+		wantBalance float64
+		wantTotals  []float64 // Groceries and Dining
+	}{
+		{
+			name: "Test closes the year and keeps the later entries",
+			tabs: tabs,
+			entries: []yearEntry{
+				{date: "11/30/26", name: "Safeway", withdrawal: 40, balance: 1000, groceries: "$40.00"},
+				{date: "12/15/26", name: "Cafe", withdrawal: 10, balance: 990, dining: "$10.00"},
+				{date: "12/20/26", name: "Safeway", withdrawal: 25.5, balance: 964.5, groceries: "$25.50"},
+				{date: "01/02/27", name: "Safeway", withdrawal: 20, balance: 944.5, groceries: "$20.00"},
+			},
+			want:        &YearClose{Year: 2026, Archived: []string{"Register 2026", "MonthlyCategories 2026"}, Folded: 3},
+			forwardRow:  6,
+			deleted:     []int64{2, 6},
+			wantBalance: 964.5,
+			wantTotals:  []float64{65.5, 10},
+		},
+		{
+			name: "Test one entry is replaced",
+			tabs: []string{RegisterTabName},
+			entries: []yearEntry{
+				{date: "12/15/26", name: "Cafe", withdrawal: 10, balance: 990, dining: "$10.00"},
+				{date: "01/02/27", name: "Safeway", withdrawal: 20, balance: 970, groceries: "$20.00"},
+			},
+			want:        &YearClose{Year: 2026, Archived: []string{"Register 2026"}, Folded: 1},
+			forwardRow:  2,
+			wantBalance: 990,
+			wantTotals:  []float64{0, 10},
+		},
+		{
+			name:    "Test already closed",
+			tabs:    []string{RegisterTabName, "Register 2026"},
+			entries: []yearEntry{{date: "12/15/26", name: "Cafe", balance: 990}},
+			wantErr: true,
+		},
+		{
+			name:    "Test no entries through the year",
+			tabs:    tabs,
+			entries: []yearEntry{{date: "01/02/27", name: "Safeway", balance: 970}},
+			wantErr: true,
+		},
+		{
+			name: "Test out of order",
+			tabs: tabs,
+			entries: []yearEntry{
+				{date: "12/15/26", name: "Cafe", balance: 990},
+				{date: "01/02/27", name: "Safeway", balance: 970},
+				{date: "12/20/26", name: "Safeway", balance: 945},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &yearProviderMock{tabs: tt.tabs}
+			got, err := newYearService(p, tt.entries).CloseYear(2026, yearColumns)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CloseYear() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				if len(p.requests) != 0 {
+					t.Errorf("CloseYear() sent %d requests, want none", len(p.requests))
+				}
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CloseYear() = %+v, want %+v", got, tt.want)
+			}
+
+			if d := p.requests[0].DuplicateSheet; d == nil || d.SourceSheetId != 0 || d.NewSheetName != "Register 2026" {
+				t.Errorf("request 0 = %+v, want the Register duplicated", p.requests[0])
+			}
+			u := p.requests[1].UpdateCells
+			if u == nil || u.Start.RowIndex != tt.forwardRow {
+				t.Fatalf("request 1 = %+v, want the balance forward row at %d", p.requests[1], tt.forwardRow)
+			}
+			cells := u.Rows[0].Values
+			if name := *cells[Description].UserEnteredValue.StringValue; name != "Balance Forward 2026" {
+				t.Errorf("balance forward name = %s", name)
+			}
+			if balance := *cells[BankRegister].UserEnteredValue.NumberValue; balance != tt.wantBalance {
+				t.Errorf("balance forward register balance = %.2f, want %.2f", balance, tt.wantBalance)
+			}
+			totals := []float64{*cells[10].UserEnteredValue.NumberValue, *cells[11].UserEnteredValue.NumberValue}
+			if !reflect.DeepEqual(totals, tt.wantTotals) {
+				t.Errorf("balance forward totals = %v, want %v", totals, tt.wantTotals)
+			}
+
+			next := 2
+			if tt.deleted != nil {
+				r := p.requests[2].DeleteDimension
+				if r == nil || r.Range.StartIndex != tt.deleted[0] || r.Range.EndIndex != tt.deleted[1] {
+					t.Errorf("request 2 = %+v, want rows %d to %d deleted", p.requests[2], tt.deleted[0], tt.deleted[1])
+				}
+				next = 3
+			}
+			for _, r := range p.requests[next:] {
+				if r.DeleteDimension != nil || r.UpdateCells != nil {
+					t.Errorf("request %+v changes the Register's later entries", r)
+				}
+			}
+		})
+	}
+}
+
+func TestSheetsService_Aggregate(t *testing.T) {
+	entries := []yearEntry{
+		{date: "12/31/26", name: "Balance Forward 2026", balance: 990, groceries: "$300.00", dining: "$50.00"},
+		{date: "12/31/26", name: "Cafe", withdrawal: 10, dining: "$10.00"},
+		{date: "01/02/27", name: "Safeway", withdrawal: 20, groceries: "$20.00"},
+		{date: "01/09/27", name: "Safeway", withdrawal: 25, groceries: "$25.00"},
+	}
+	tests := []struct {
+		name       string
+		years      []int
+		wantCats   map[string]map[string]float64
+		wantPayees map[string]map[string]float64
+	}{
+		{
+			name:  "Test every year",
+			years: nil,
+			wantCats: map[string]map[string]float64{
+				"2026-12": {"Groceries": 0, "Dining": 10},
+				"2027-01": {"Groceries": 45, "Dining": 0},
+			},
+			wantPayees: map[string]map[string]float64{
+				"2026-12": {"Cafe": -10},
+				"2027-01": {"Safeway": -45},
+			},
+		},
+		{
+			name:       "Test one year",
+			years:      []int{2027},
+			wantCats:   map[string]map[string]float64{"2027-01": {"Groceries": 45, "Dining": 0}},
+			wantPayees: map[string]map[string]float64{"2027-01": {"Safeway": -45}},
+		},
+		{
+			name:       "Test a year without entries",
+			years:      []int{2025},
+			wantCats:   map[string]map[string]float64{},
+			wantPayees: map[string]map[string]float64{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newYearService(&yearProviderMock{}, entries)
+			cats, payees := s.Aggregate(yearColumns, tt.years)
+			if !reflect.DeepEqual(cats, tt.wantCats) {
+				t.Errorf("Aggregate() categories = %v, want %v", cats, tt.wantCats)
+			}
+			if !reflect.DeepEqual(payees, tt.wantPayees) {
+				t.Errorf("Aggregate() payees = %v, want %v", payees, tt.wantPayees)
+			}
+		})
+	}
+}
+
+func TestSheetsService_ClosedYear(t *testing.T) {
+	entries := []yearEntry{
+		{date: "12/31/25", name: "Balance Forward 2025"},
+		{date: "12/31/26", name: "Balance Forward 2026"},
+		{date: "01/02/27", name: "Safeway"},
+	}
+	if got := newYearService(&yearProviderMock{}, entries).ClosedYear(); got != 2026 {
+		t.Errorf("ClosedYear() = %d, want 2026", got)
+	}
+	if got := newYearService(&yearProviderMock{}, entries[2:]).ClosedYear(); got != 0 {
+		t.Errorf("ClosedYear() = %d, want 0", got)
+	}
+}
@@ -35,5 +35,12 @@ func getBalances() {
 		} else {
 			fmt.Printf("%s: $%.2f\n", balance.BankName, balance.Amount)
 		}
+		for _, account := range balance.Accounts {
+			if account.Error != nil {
+				fmt.Printf("    %s: %s\n", account.AccountName, account.Error.Error())
+			} else {
+				fmt.Printf("    %s: $%.2f\n", account.AccountName, account.Amount)
+			}
+		}
 	}
 }
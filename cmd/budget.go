@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"register/pkg/budget"
+
+	"github.com/spf13/cobra"
+)
+
+var budgetCmd = &cobra.Command{
+	Use:   "budget",
+	Short: "Budget commands",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var budgetReportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Compares a month's budget with its spending by category",
+	Long: `Report reads the budget from the Budget sheet and the month's transactions from the database
+and shows each category column's budgeted, spent and remaining amounts. Weekly and every-2-weeks
+budgets are pro-rated by the days in the month and twice-monthly budgets doubled. Categories over
+budget are marked, in red on a terminal.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		budgetReport()
+	},
+}
+
+var budgetOptions struct {
+	Month  string
+	Format string
+}
+
+func init() {
+	rootCmd.AddCommand(budgetCmd)
+	budgetCmd.AddCommand(budgetReportCmd)
+
+	budgetReportCmd.Flags().StringVar(&budgetOptions.Month, "month", time.Now().Format(budget.MonthFormat), "the month to report, eg. 2026-09")
+	budgetReportCmd.Flags().StringVarP(&budgetOptions.Format, "format", "f", "table", "output format: table, csv or json")
+}
+
+func budgetReport() {
+	month, err := time.Parse(budget.MonthFormat, budgetOptions.Month)
+	checkError(err)
+	format, err := budget.ParseFormat(budgetOptions.Format)
+	checkError(err)
+
+	entries, err := readBudgets()
+	checkError(err)
+	qHandler := getQueryHandler()
+	report := budget.NewReport(entries, qHandler.GetColumns(), qHandler.GetTransactions(), month)
+	checkError(budget.Write(os.Stdout, report, format, isTerminal(os.Stdout)))
+}
+
+// isTerminal returns true if f is a terminal rather than a file or pipe
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"fmt"
+
+	"register/pkg/models"
+
+	"github.com/spf13/cobra"
+)
+
+var columnsCmd = &cobra.Command{
+	Use:   "columns",
+	Short: "Register column (budget category) commands",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var columnsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the register columns",
+	Run: func(cmd *cobra.Command, args []string) {
+		columnsList()
+	},
+}
+
+var columnsAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Adds a register column",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		c := &models.Column{
+			Name:        args[0],
+			Color:       columnColor,
+			ColumnIndex: columnIndex,
+			Letter:      columnLetter,
+			IsCategory:  columnIsCategory,
+		}
+		checkError(getQueryHandler().SaveColumn(c))
+		fmt.Printf("Added column %d: %s\n", c.ID, c.Name)
+	},
+}
+
+var columnsEditCmd = &cobra.Command{
+	Use:   "edit <id>",
+	Short: "Changes a register column",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		columnsEdit(cmd, args[0])
+	},
+}
+
+var columnsRmCmd = &cobra.Command{
+	Use:   "rm <id>",
+	Short: "Removes a register column no merchant is mapped to",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		checkError(getQueryHandler().DeleteColumn(parseID(args[0])))
+		fmt.Printf("Removed column %s\n", args[0])
+	},
+}
+
+var (
+	columnName       string
+	columnColor      string
+	columnIndex      int
+	columnLetter     string
+	columnIsCategory bool
+)
+
+func init() {
+	rootCmd.AddCommand(columnsCmd)
+	columnsCmd.AddCommand(columnsListCmd)
+	columnsCmd.AddCommand(columnsAddCmd)
+	columnsCmd.AddCommand(columnsEditCmd)
+	columnsCmd.AddCommand(columnsRmCmd)
+
+	for _, c := range []*cobra.Command{columnsAddCmd, columnsEditCmd} {
+		c.Flags().StringVar(&columnColor, "color", "white", "the column's color")
+		c.Flags().IntVar(&columnIndex, "index", 0, "the column's index in the register sheet")
+		c.Flags().StringVar(&columnLetter, "letter", "", "the column's letter in the register sheet")
+		c.Flags().BoolVar(&columnIsCategory, "category", true, "the column is a budget category")
+	}
+	columnsEditCmd.Flags().StringVar(&columnName, "name", "", "the column's name")
+}
+
+func columnsList() {
+	fmt.Printf("    %5s %-30s %-10s %5s %-6s %s\n", "ID", "Name", "Color", "Index", "Letter", "Category")
+	for _, c := range getQueryHandler().GetColumns() {
+		fmt.Printf("    %5d %-30s %-10s %5d %-6s %t\n", c.ID, c.Name, c.Color, c.ColumnIndex, c.Letter, c.IsCategory)
+	}
+}
+
+func columnsEdit(cmd *cobra.Command, id string) {
+	qHandler := getQueryHandler()
+	c, err := qHandler.GetColumn(parseID(id))
+	checkError(err)
+
+	flags := cmd.Flags()
+	if flags.Changed("name") {
+		c.Name = columnName
+	}
+	if flags.Changed("color") {
+		c.Color = columnColor
+	}
+	if flags.Changed("index") {
+		c.ColumnIndex = columnIndex
+	}
+	if flags.Changed("letter") {
+		c.Letter = columnLetter
+	}
+	if flags.Changed("category") {
+		c.IsCategory = columnIsCategory
+	}
+	checkError(qHandler.SaveColumn(c))
+	fmt.Printf("Updated column %d: %s\n", c.ID, c.Name)
+}
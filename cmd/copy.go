@@ -19,7 +19,6 @@ package cmd
 import (
 	"fmt"
 
-	"register/api/providers/sheets_provider"
 	"register/api/services/sheets_service"
 	cfg "register/pkg/config"
 
@@ -51,9 +50,10 @@ func copyRows() {
 	config, err = cfg.ReadConfig(ConfigFile)
 	checkError(err)
 
-	sheetsProvider, err := sheets_provider.New(options.SpreadsheetID, config)
+	sheetsProvider, err := newSheetsProvider()
 	checkError(err)
 	sheetsService := sheets_service.New(sheetsProvider)
+	sheetsService.Income = incomeSources()
 	checkError(err)
 	err = sheetsService.NewRegisterSheet(config)
 	checkError(err)
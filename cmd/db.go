@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+
+	"register/pkg/seed"
+
+	"github.com/spf13/cobra"
+)
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Database commands",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var dbSeedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Loads the register columns and merchants from the seed CSV files",
+	Long: `Seed reads columns.csv (Name, Color, Category), merch_to_cats.csv (Merchant, Column Name)
+and merchants.csv (Bank Name, Name) from --dir and upserts them into the configured database:
+columns by name, then merchants by bank name. Every file is validated first and nothing is
+written if any row is invalid, so seeding can be run again after fixing the files or adding
+rows. A blank column name is an unused sheet column and is named old-<index>.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		dbSeed()
+	},
+}
+
+var (
+	seedDir    string
+	seedDryRun bool
+)
+
+func init() {
+	rootCmd.AddCommand(dbCmd)
+	dbCmd.AddCommand(dbSeedCmd)
+
+	dbSeedCmd.Flags().StringVar(&seedDir, "dir", "pkg/repository/csv", "the directory of the seed CSV files")
+	dbSeedCmd.Flags().BoolVar(&seedDryRun, "dry-run", false, "validate the files without writing to the database")
+}
+
+func dbSeed() {
+	fmt.Printf("Validating seed files in %s...\n", seedDir)
+	data, err := seed.Load(seedDir)
+	checkError(err)
+	fmt.Printf("    %d columns, %d merchants\n", len(data.Columns), len(data.Merchants))
+	for _, s := range data.Skipped {
+		fmt.Printf("    skipped %s: no column\n", s)
+	}
+	if seedDryRun {
+		return
+	}
+
+	fmt.Println("Seeding...")
+	result, err := seed.Seed(getQueryHandler(), data)
+	checkError(err)
+	fmt.Printf("    columns: %d created, %d updated\n", result.ColumnsCreated, result.ColumnsUpdated)
+	fmt.Printf("    merchants: %d created, %d updated\n", result.MerchantsCreated, result.MerchantsUpdated)
+	fmt.Printf("    %d unchanged\n", result.Unchanged)
+}
@@ -0,0 +1,206 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"register/api/services/sheets_service"
+	"register/pkg/envelopes"
+	"register/pkg/handler"
+	"register/pkg/models"
+
+	"github.com/spf13/cobra"
+)
+
+const envelopeDateFormat = "2006-01-02"
+
+var envelopesCmd = &cobra.Command{
+	Use:   "envelopes",
+	Short: "Shows the budget categories' envelope balances",
+	Long: `Envelopes shows each budget category's envelope at the end of a date from the funding,
+spending and transfer events in the database, without reading the sheet. Each update records a
+funding event for every category an income source's deposit is allocated to, as the paycheck row
+tops up the Register's category columns, and a spending event for every transaction in a category
+column. At the start of each month a category's rollover rule carries its balance over, drops the
+money left over (reset) or caps it.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		showEnvelopes()
+	},
+}
+
+var envelopesTransferCmd = &cobra.Command{
+	Use:   "transfer <from category> <to category> <amount>",
+	Short: "Moves money from one envelope to another",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		transferEnvelope(args)
+	},
+}
+
+var envelopesRuleCmd = &cobra.Command{
+	Use:   "rule <category> <carry|reset|cap> [<cap>]",
+	Short: "Sets a category's rollover rule",
+	Long: `Rule sets what happens to a category's balance at the start of each month: carry keeps it,
+reset drops the money left over but keeps overspending, and cap keeps at most the cap amount.
+Categories without a rule carry over.`,
+	Args: cobra.RangeArgs(2, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		setEnvelopeRule(args)
+	},
+}
+
+var envelopesSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Records envelope events for the transactions in the database",
+	Long: `Sync records the envelope events of every transaction in the database that has none yet, eg.
+those from before envelopes were tracked. Income is allocated with the Budget sheet's current
+amounts.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		syncEnvelopes()
+	},
+}
+
+var envelopesOptions struct {
+	Date string
+	Note string
+}
+
+func init() {
+	rootCmd.AddCommand(envelopesCmd)
+	envelopesCmd.AddCommand(envelopesTransferCmd)
+	envelopesCmd.AddCommand(envelopesRuleCmd)
+	envelopesCmd.AddCommand(envelopesSyncCmd)
+
+	today := time.Now().Format(envelopeDateFormat)
+	envelopesCmd.Flags().StringVar(&envelopesOptions.Date, "date", today, "show the balances at the end of this date, eg. 2026-09-30")
+	envelopesTransferCmd.Flags().StringVar(&envelopesOptions.Date, "date", today, "the date of the transfer, eg. 2026-09-30")
+	envelopesTransferCmd.Flags().StringVar(&envelopesOptions.Note, "note", "", "a note kept with the transfer")
+}
+
+func showEnvelopes() {
+	date, err := time.Parse(envelopeDateFormat, envelopesOptions.Date)
+	checkError(err)
+	asOf := date.Add(24*time.Hour - time.Nanosecond)
+
+	qHandler := getQueryHandler()
+	checkError(qHandler.MigrateEnvelopes())
+	rules, err := qHandler.GetEnvelopeRules()
+	checkError(err)
+	events, err := qHandler.GetEnvelopeEvents(asOf)
+	checkError(err)
+
+	balances := envelopes.Balances(rules, events, asOf)
+	if len(balances) == 0 {
+		fmt.Println("No envelope events; run update or envelopes sync")
+		return
+	}
+	var total float64
+	fmt.Printf("    %-25s %-6s %10s %10s %11s %10s %10s\n", "Category", "Rule", "Funded", "Spent", "Transferred", "Rolled Off", "Balance")
+	for _, b := range balances {
+		fmt.Printf("    %-25s %-6s %10.2f %10.2f %11.2f %10.2f %10.2f\n", b.Category, b.Rollover, b.Funded, b.Spent, b.Transferred, b.RolledOff, b.Balance)
+		total += b.Balance
+	}
+	fmt.Printf("    %-25s %-6s %10s %10s %11s %10s %10.2f\n", "Total", "", "", "", "", "", total)
+}
+
+func transferEnvelope(args []string) {
+	from, to := args[0], args[1]
+	amount, err := strconv.ParseFloat(args[2], 64)
+	checkError(err)
+	if amount <= 0 {
+		checkError(fmt.Errorf("the amount must be more than 0"))
+	}
+	if from == to {
+		checkError(fmt.Errorf("the categories must differ"))
+	}
+	date, err := time.Parse(envelopeDateFormat, envelopesOptions.Date)
+	checkError(err)
+
+	qHandler := getQueryHandler()
+	checkError(checkEnvelopeCategory(qHandler, from))
+	checkError(checkEnvelopeCategory(qHandler, to))
+	checkError(qHandler.MigrateEnvelopes())
+	_, err = qHandler.AddEnvelopeEvents(envelopes.Transfer(from, to, amount, date, envelopesOptions.Note))
+	checkError(err)
+	fmt.Printf("Moved %.2f from %s to %s\n", amount, from, to)
+}
+
+func setEnvelopeRule(args []string) {
+	rule := &models.EnvelopeRule{Category: args[0], Rollover: args[1]}
+	if !envelopes.ValidRollover(rule.Rollover) {
+		checkError(fmt.Errorf("the rollover rule must be carry, reset or cap"))
+	}
+	if rule.Rollover == models.RolloverCap {
+		if len(args) != 3 {
+			checkError(fmt.Errorf("give the cap amount"))
+		}
+		amount, err := strconv.ParseFloat(args[2], 64)
+		checkError(err)
+		if amount < 0 {
+			checkError(fmt.Errorf("the cap must not be negative"))
+		}
+		rule.Cap = amount
+	} else if len(args) == 3 {
+		checkError(fmt.Errorf("only the cap rule takes an amount"))
+	}
+
+	qHandler := getQueryHandler()
+	checkError(checkEnvelopeCategory(qHandler, rule.Category))
+	checkError(qHandler.MigrateEnvelopes())
+	checkError(qHandler.SaveEnvelopeRule(rule))
+	fmt.Printf("%s rolls over with %s\n", rule.Category, rule.Rollover)
+}
+
+func syncEnvelopes() {
+	sheetsProvider, err := newSheetsProvider()
+	checkError(err)
+	sheetsService := sheets_service.New(sheetsProvider)
+	sheetsService.Income = incomeSources()
+	checkError(sheetsService.NewBudgetSheet(config))
+	fmt.Println("Reading Budget...")
+	_, err = sheetsService.ReadBudgetSheet()
+	checkError(err)
+
+	qHandler := getQueryHandler()
+	var trans []*models.Transaction
+	for _, t := range qHandler.GetTransactions() {
+		trans = append(trans, &t)
+	}
+	added, err := recordEnvelopeEvents(qHandler, sheetsService, qHandler.GetColumns(), trans)
+	checkError(err)
+	fmt.Printf("Recorded %d envelope events\n", added)
+}
+
+// recordEnvelopeEvents records the envelope events of the transactions not yet recorded and returns the
+// number added. The Budget sheet must have been read.
+func recordEnvelopeEvents(qHandler *handler.Query, sheetsService *sheets_service.SheetsService, columns []models.Column,
+	trans []*models.Transaction) (int64, error) {
+	funding := make(map[string]map[string]float64)
+	for _, src := range sheetsService.Income.All() {
+		funding[src.Name] = sheetsService.Allocations(&src, columns)
+	}
+	events, err := envelopes.Events(trans, columns, sheetsService.Income, funding)
+	if err != nil {
+		return 0, err
+	}
+	if err := qHandler.MigrateEnvelopes(); err != nil {
+		return 0, err
+	}
+	return qHandler.AddEnvelopeEvents(events)
+}
+
+// checkEnvelopeCategory returns an error if the name is not a budget category column
+func checkEnvelopeCategory(qHandler *handler.Query, name string) error {
+	var categories []string
+	for _, c := range qHandler.GetColumns() {
+		if c.IsCategory {
+			categories = append(categories, c.Name)
+		}
+	}
+	if !slices.Contains(categories, name) {
+		return fmt.Errorf("%s is not a budget category", name)
+	}
+	return nil
+}
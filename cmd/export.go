@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"register/pkg/export"
+	"register/pkg/handler"
+	"register/pkg/qif"
+
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exports stored transactions to a plain-text accounting journal",
+	Long: `Export turns the transactions stored in the database into double-entry postings for
+beancount, ledger or hledger, or to QIF for tools that only read that. Wells Fargo and checks post to the checking account, the credit
+cards to liability accounts and the budget category columns to expense accounts. With
+--balances, balance assertions are added from the current bank balances.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		exportTransactions()
+	},
+}
+
+var exportOptions struct {
+	Format   string
+	Output   string
+	Balances bool
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.Flags().StringVarP(&exportOptions.Format, "format", "f", "beancount", "journal format: beancount, ledger, hledger or qif")
+	exportCmd.Flags().StringVarP(&exportOptions.Output, "output", "o", "", "output file; default stdout")
+	exportCmd.Flags().BoolVar(&exportOptions.Balances, "balances", false, "add balance assertions from the current bank balances")
+}
+
+func exportTransactions() {
+	qHandler := getQueryHandler()
+	if strings.EqualFold(exportOptions.Format, "qif") {
+		exportQIF(qHandler)
+		return
+	}
+
+	format, err := export.ParseFormat(exportOptions.Format)
+	checkError(err)
+
+	o := export.Options{
+		Format:  format,
+		Columns: qHandler.GetColumns(),
+		Date:    time.Now(),
+		Income:  incomeSources(),
+	}
+
+	if exportOptions.Balances {
+		o.Balances = make(map[string]float64)
+		balances := getBankingClient().BankClient.GetBalances(options.BankIDs)
+		for id, balance := range balances {
+			if balance.Error != nil {
+				fmt.Fprintln(os.Stderr, balance.Error.Error())
+				continue
+			}
+			account := export.SourceAccount(config.Banks[id].Source)
+			if account == export.CheckingAccount {
+				o.Balances[account] = balance.Amount
+			} else {
+				// credit card balances are amounts owed
+				o.Balances[account] = -balance.Amount
+			}
+		}
+	}
+
+	out, closeOut := exportOutput()
+	defer closeOut()
+	err = export.Write(out, qHandler.GetTransactions(), o)
+	checkError(err)
+}
+
+// exportQIF writes the transactions as QIF; balances are not part of QIF so --balances is ignored
+func exportQIF(qHandler *handler.Query) {
+	out, closeOut := exportOutput()
+	defer closeOut()
+	err := qif.Write(out, qHandler.GetTransactions(), qHandler.GetColumns())
+	checkError(err)
+}
+
+// exportOutput returns the --output file, or stdout without one, and the function that closes it
+func exportOutput() (*os.File, func()) {
+	if exportOptions.Output == "" {
+		return os.Stdout, func() {}
+	}
+	out, err := os.Create(exportOptions.Output)
+	checkError(err)
+	return out, func() {
+		checkError(out.Close())
+	}
+}
@@ -0,0 +1,186 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"register/api/services/sheets_service"
+	"register/pkg/banking"
+	"register/pkg/forecast"
+	"register/pkg/models"
+	"register/pkg/recurring"
+
+	"github.com/spf13/cobra"
+)
+
+var forecastCmd = &cobra.Command{
+	Use:   "forecast",
+	Short: "Projects the daily checking balance and flags days below a floor",
+	Long: `Forecast projects the Wells Fargo checking balance day by day from its current balance, the
+income sources paid on a cadence (at the Budget sheet's amount for the source, or its last
+deposit), the recurring bills paid from checking and the current balance of each credit card in the
+CardPaymentDays config setting, paid in full on its day of the month. Days the balance would
+drop below the floor, ForecastFloor or --floor, are flagged. With --sheet the forecast is also
+written to the Forecast tab.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		projectForecast(cmd)
+	},
+}
+
+var forecastOptions struct {
+	Days  int
+	Floor float64
+	Sheet bool
+	All   bool
+}
+
+func init() {
+	rootCmd.AddCommand(forecastCmd)
+
+	forecastCmd.Flags().IntVar(&forecastOptions.Days, "days", 60, "the number of days to project, up to 365")
+	forecastCmd.Flags().Float64Var(&forecastOptions.Floor, "floor", 0, "the lowest acceptable balance; default is the ForecastFloor config setting")
+	forecastCmd.Flags().BoolVar(&forecastOptions.Sheet, "sheet", false, "also write the forecast to the Forecast tab")
+	forecastCmd.Flags().BoolVar(&forecastOptions.All, "all", false, "list every day, not only those with expected deposits or withdrawals")
+}
+
+func projectForecast(cmd *cobra.Command) {
+	if forecastOptions.Days < 1 || forecastOptions.Days > 365 {
+		checkError(fmt.Errorf("--days must be from 1 to 365"))
+	}
+	o := forecast.Options{Start: time.Now(), Days: forecastOptions.Days, Floor: config.ForecastFloor}
+	if cmd.Flags().Changed("floor") {
+		o.Floor = forecastOptions.Floor
+	}
+
+	fmt.Println("Getting accounts balances...")
+	bankIDs := []string{banking.WellsFargoID}
+	for id := range config.CardPaymentDays {
+		bankIDs = append(bankIDs, id)
+	}
+	sort.Strings(bankIDs[1:])
+	balances := getBankingClient().BankClient.GetBalances(bankIDs)
+	checkError(balances[banking.WellsFargoID].Error)
+	o.Balance = balances[banking.WellsFargoID].Amount
+	for _, id := range bankIDs[1:] {
+		if balances[id].Error != nil {
+			fmt.Printf("Warning: leaving out %s: %s\n", id, balances[id].Error.Error())
+			continue
+		}
+		o.Cards = append(o.Cards, forecast.Card{Name: config.Banks[id].Name, Balance: balances[id].Amount, PaymentDay: config.CardPaymentDays[id]})
+	}
+
+	trans := getQueryHandler().GetTransactions()
+	o.Incomes = forecastIncomes(trans, incomeSources().All())
+	o.Bills = checkingBills(trans, o.Start)
+
+	f := forecast.Project(o)
+	printForecast(f, o.Floor)
+
+	if forecastOptions.Sheet {
+		fmt.Println("Updating Forecast tab...")
+		sheetsProvider, err := newSheetsProvider()
+		checkError(err)
+		checkError(sheets_service.New(sheetsProvider).UpdateForecast(sheets_service.ForecastTabName, forecastRows(f)))
+	}
+}
+
+// forecastIncomes returns the income sources paid on a cadence, each from its last deposit. The amount is
+// the Budget sheet's amount for the cadence in the source's category when it has one, otherwise the last
+// deposit.
+func forecastIncomes(trans []models.Transaction, sources []models.IncomeSource) []forecast.Income {
+	var incomes []forecast.Income
+	for _, src := range sources {
+		if src.Cadence == models.AllocationNone {
+			continue
+		}
+		in := forecast.Income{Name: src.Name, Cadence: src.Cadence}
+		for _, t := range trans {
+			date, err := time.Parse(models.RegisterDateFormat, t.Date)
+			if err == nil && t.Name == src.Name && t.Deposit > 0 && date.After(in.Last) {
+				in.Amount, in.Last = t.Deposit, date
+			}
+		}
+		if in.Last.IsZero() {
+			fmt.Printf("Warning: no %s deposit found, forecasting without it\n", src.Name)
+			continue
+		}
+		incomes = append(incomes, in)
+	}
+	if len(incomes) == 0 {
+		return nil
+	}
+
+	entries, err := readBudgets()
+	if err != nil {
+		fmt.Printf("Warning: using the last deposits, could not read the budget: %s\n", err.Error())
+	}
+	for _, e := range entries {
+		for i, in := range incomes {
+			if amount := budgetAmount(e, in.Cadence); e.Category == in.Name && amount != 0 {
+				incomes[i].Amount = amount
+			}
+		}
+	}
+	return incomes
+}
+
+// budgetAmount returns a budget entry's amount for the cadence
+func budgetAmount(e *sheets_service.BudgetEntry, cadence string) float64 {
+	switch cadence {
+	case models.AllocationWeekly:
+		return e.Weekly
+	case models.AllocationEvery2Weeks:
+		return e.Every2Weeks
+	case models.AllocationTwiceMonthly:
+		return e.TwiceMonthly
+	case models.AllocationMonthly:
+		return e.Monthly
+	}
+	return 0
+}
+
+// checkingBills returns the recurring charges paid from checking, other than credit card payments
+func checkingBills(trans []models.Transaction, asOf time.Time) []recurring.Subscription {
+	var checking []models.Transaction
+	for _, t := range trans {
+		if t.Source == sheets_service.CheckingAccountSourceName && t.CreditCard == 0 {
+			checking = append(checking, t)
+		}
+	}
+	return recurring.Detect(recurring.FromTransactions(checking), asOf)
+}
+
+func printForecast(f *forecast.Forecast, floor float64) {
+	fmt.Printf("    %-10s %12s  %s\n", "Date", "Balance", "Expected")
+	for _, d := range f.Days {
+		if len(d.Events) == 0 && !d.BelowFloor && !forecastOptions.All {
+			continue
+		}
+		flag := ""
+		if d.BelowFloor {
+			flag = "  below floor"
+		}
+		fmt.Printf("    %-10s %12.2f%s\n", d.Date.Format(models.RegisterDateFormat), d.Balance, flag)
+		for _, e := range d.Events {
+			fmt.Printf("    %-10s %12s  %-30s %10.2f\n", "", "", e.Name, e.Amount)
+		}
+	}
+	below := f.BelowFloor()
+	fmt.Printf("Lowest balance: %.2f on %s\n", f.Lowest.Balance, f.Lowest.Date.Format(models.RegisterDateFormat))
+	if len(below) > 0 {
+		fmt.Printf("Warning: %d days below the %.2f floor, first on %s\n", len(below), floor, below[0].Date.Format(models.RegisterDateFormat))
+	}
+}
+
+func forecastRows(f *forecast.Forecast) []sheets_service.ForecastRow {
+	rows := make([]sheets_service.ForecastRow, 0, len(f.Days))
+	for _, d := range f.Days {
+		row := sheets_service.ForecastRow{Date: d.Date.Format(models.RegisterDateFormat), Balance: d.Balance, BelowFloor: d.BelowFloor}
+		for _, e := range d.Events {
+			row.Expected = append(row.Expected, fmt.Sprintf("%s %.2f", e.Name, e.Amount))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
@@ -1,17 +1,73 @@
 package cmd
 
-import "time"
+import (
+	"path/filepath"
+	"time"
 
-const PlaidDateFormat = "2006-01-02"
+	"register/api/providers/sheets_provider"
+	"register/api/providers/xlsx_provider"
+	cfg "register/pkg/config"
+	"register/pkg/driver"
+	"register/pkg/handler"
+	"register/pkg/window"
+)
 
-func weeksAgo(weeks int) string {
-	//t := time.Now().AddDate(0, -3, 0)
-	// changing to the first of the year
-	// TODO need to fix for next year
-	t := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
-	return t.Format(PlaidDateFormat)
+// lastRunFileName records, in FinanceDir, the end of the last successful update for --since last-run
+const lastRunFileName = ".register_last_run"
+
+// transactionWindow returns the dates to read transactions for: the --year or --since window, else the
+// StartDate and EndDate config settings, else the current year to date
+func transactionWindow(year int, since string) window.Window {
+	o := window.Options{Year: year, Since: since, Start: config.StartDate, End: config.EndDate, Now: time.Now()}
+	if since == window.LastRun {
+		lastRun, err := window.ReadLastRun(lastRunFile())
+		checkError(err)
+		o.LastRun = lastRun
+	}
+	w, err := window.New(o)
+	checkError(err)
+	return w
+}
+
+func lastRunFile() string {
+	return filepath.Join(config.FinanceDir, lastRunFileName)
+}
+
+func getQueryHandler() *handler.Query {
+	conn, err := driver.ConnectSQL(&driver.ConnectParams{
+		DBType: driver.DBType(config.DBType),
+		Host:   config.DBHost,
+		Port:   config.DBPort,
+		DBName: config.DBName,
+		User:   config.DBUsername,
+		Pass:   config.DBPassword,
+	})
+	checkError(err)
+	return handler.NewQueryHandler(conn)
+}
+
+// newSheetsProvider returns the spreadsheet backend selected by the SheetsBackend config setting:
+// "xlsx" for a local workbook at WorkbookFile, otherwise Google Sheets
+func newSheetsProvider() (sheets_provider.SheetsProviderInterface, error) {
+	if config.SheetsBackend == "xlsx" {
+		return xlsx_provider.New(config.WorkbookFile)
+	}
+	provider, err := sheets_provider.New(options.SpreadsheetID, config)
+	if err != nil {
+		return nil, err
+	}
+	provider.SetCallBudget(sheetsCallBudget)
+	return provider, nil
 }
 
-func today() string {
-	return time.Now().Format(PlaidDateFormat)
+// bankItemIDs returns the IDs of a bank's Plaid items; a bank without items has one, named by its Source
+func bankItemIDs(bank cfg.Bank) []string {
+	if len(bank.Items) == 0 {
+		return []string{bank.Source}
+	}
+	itemIDs := make([]string, 0, len(bank.Items))
+	for _, item := range bank.Items {
+		itemIDs = append(itemIDs, item.ID)
+	}
+	return itemIDs
 }
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"fmt"
+
+	"register/pkg/banking"
+
+	"github.com/spf13/cobra"
+)
+
+var itemsCmd = &cobra.Command{
+	Use:   "items",
+	Short: "Plaid item (bank login) commands",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var itemsStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Checks the health of each bank's Plaid items",
+	Long: `Status asks Plaid for the health of every item of the banks given by --bank-ids, records it
+and lists each item with how to re-link it when its login has expired. With --cached the
+statuses recorded by the last update or status run are listed without calling Plaid.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		itemsStatus()
+	},
+}
+
+var itemsStatusCached bool
+
+func init() {
+	rootCmd.AddCommand(itemsCmd)
+	itemsCmd.AddCommand(itemsStatusCmd)
+
+	itemsStatusCmd.Flags().BoolVar(&itemsStatusCached, "cached", false, "list the recorded statuses without calling Plaid")
+}
+
+func itemsStatus() {
+	bankClient := getBankingClient().BankClient
+	if !itemsStatusCached {
+		for _, bankID := range options.BankIDs {
+			for _, itemID := range bankItemIDs(config.Banks[bankID]) {
+				bankClient.CheckItem(bankID, itemID, ctx)
+			}
+		}
+		checkError(bankClient.SaveItemStatuses())
+	}
+
+	statuses, err := bankClient.ItemStatuses()
+	checkError(err)
+	for _, s := range statuses {
+		fmt.Printf("    %-12s %-16s %-15s %s\n", s.BankID, s.ItemID, s.Status, s.CheckedAt.Format("2006-01-02 15:04"))
+		if s.Status == banking.ItemStatusOK {
+			continue
+		}
+		fmt.Printf("        %s %s\n", s.ErrorCode, s.Message)
+		if s.Status == banking.ItemStatusLoginRequired {
+			fmt.Printf("        re-link: register server, then GET /api/create_link_token?institution=%s&item=%s\n", s.BankID, s.ItemID)
+		}
+	}
+}
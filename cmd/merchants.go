@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"register/pkg/models"
+
+	"github.com/spf13/cobra"
+)
+
+var merchantsCmd = &cobra.Command{
+	Use:   "merchants",
+	Short: "Merchant (bank name to register name and column) commands",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var merchantsListCmd = &cobra.Command{
+	Use:   "list [<search>]",
+	Short: "Lists the merchants, those whose bank name or name contains search if given",
+	Args:  cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		merchantsList(args)
+	},
+}
+
+var merchantsAddCmd = &cobra.Command{
+	Use:   "add <bank name> <name> <column id>",
+	Short: "Maps a bank's transaction name to a register name and column",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		merchantsAdd(args)
+	},
+}
+
+var merchantsEditCmd = &cobra.Command{
+	Use:   "edit <id>",
+	Short: "Changes a merchant's bank name, name, column or tax deductible flag",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		merchantsEdit(cmd, args[0])
+	},
+}
+
+var merchantsRmCmd = &cobra.Command{
+	Use:   "rm <id>...",
+	Short: "Removes merchants",
+	Args:  cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		qHandler := getQueryHandler()
+		for _, id := range args {
+			checkError(qHandler.DeleteMerchant(parseID(id)))
+			fmt.Printf("Removed merchant %s\n", id)
+		}
+	},
+}
+
+var merchantsMergeCmd = &cobra.Command{
+	Use:   "merge <into id> <from id>...",
+	Short: "Merges duplicate merchants into one",
+	Long: `Merge maps every bank name of the from merchants' names to the into merchant's name and
+column, eg. "AMZN Mktp" of "Amazon Marketplace" to "Amazon.com", and drops bank names the into
+merchant already has.`,
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		merchantsMerge(args)
+	},
+}
+
+var (
+	merchantBankName      string
+	merchantName          string
+	merchantColumnID      int
+	merchantTaxDeductible bool
+)
+
+func init() {
+	rootCmd.AddCommand(merchantsCmd)
+	merchantsCmd.AddCommand(merchantsListCmd)
+	merchantsCmd.AddCommand(merchantsAddCmd)
+	merchantsCmd.AddCommand(merchantsEditCmd)
+	merchantsCmd.AddCommand(merchantsRmCmd)
+	merchantsCmd.AddCommand(merchantsMergeCmd)
+
+	merchantsAddCmd.Flags().BoolVar(&merchantTaxDeductible, "tax-deductible", false, "the merchant's purchases are tax deductible")
+	merchantsEditCmd.Flags().StringVar(&merchantBankName, "bank-name", "", "the bank's transaction name")
+	merchantsEditCmd.Flags().StringVar(&merchantName, "name", "", "the register name")
+	merchantsEditCmd.Flags().IntVar(&merchantColumnID, "column", 0, "the register column id")
+	merchantsEditCmd.Flags().BoolVar(&merchantTaxDeductible, "tax-deductible", false, "the merchant's purchases are tax deductible")
+}
+
+func parseID(s string) int {
+	id, err := strconv.Atoi(s)
+	if err != nil || id <= 0 {
+		checkError(fmt.Errorf("%s is not an id", s))
+	}
+	return id
+}
+
+func merchantsList(args []string) {
+	qHandler := getQueryHandler()
+	columns := make(map[int]string)
+	for _, c := range qHandler.GetColumns() {
+		columns[c.ID] = c.Name
+	}
+
+	fmt.Printf("    %5s %-35s %-30s %-25s %s\n", "ID", "Bank Name", "Name", "Column", "Tax")
+	for _, m := range qHandler.GetMerchants() {
+		if len(args) == 1 {
+			search := strings.ToLower(args[0])
+			if !strings.Contains(strings.ToLower(m.BankName), search) && !strings.Contains(strings.ToLower(m.Name), search) {
+				continue
+			}
+		}
+		tax := ""
+		if m.TaxDeductible {
+			tax = "yes"
+		}
+		fmt.Printf("    %5d %-35s %-30s %-25s %s\n", m.ID, m.BankName, m.Name, columns[m.ColumnID], tax)
+	}
+}
+
+func merchantsAdd(args []string) {
+	m := &models.Merchant{
+		BankName:      args[0],
+		Name:          args[1],
+		ColumnID:      parseID(args[2]),
+		TaxDeductible: merchantTaxDeductible,
+	}
+	checkError(getQueryHandler().SaveMerchant(m))
+	fmt.Printf("Added merchant %d: %s -> %s\n", m.ID, m.BankName, m.Name)
+}
+
+func merchantsEdit(cmd *cobra.Command, id string) {
+	qHandler := getQueryHandler()
+	m, err := qHandler.GetMerchant(parseID(id))
+	checkError(err)
+
+	if cmd.Flags().Changed("bank-name") {
+		m.BankName = merchantBankName
+	}
+	if cmd.Flags().Changed("name") {
+		m.Name = merchantName
+	}
+	if cmd.Flags().Changed("column") {
+		m.ColumnID = merchantColumnID
+	}
+	if cmd.Flags().Changed("tax-deductible") {
+		m.TaxDeductible = merchantTaxDeductible
+	}
+	checkError(qHandler.SaveMerchant(m))
+	fmt.Printf("Updated merchant %d: %s -> %s\n", m.ID, m.BankName, m.Name)
+}
+
+func merchantsMerge(args []string) {
+	var fromIDs []int
+	for _, id := range args[1:] {
+		fromIDs = append(fromIDs, parseID(id))
+	}
+	checkError(getQueryHandler().MergeMerchants(parseID(args[0]), fromIDs))
+	fmt.Printf("Merged %s into merchant %s\n", strings.Join(args[1:], ", "), args[0])
+}
@@ -18,10 +18,10 @@ package cmd
 
 import (
 	"fmt"
-	"register/api/providers/sheets_provider"
 
 	"register/pkg/driver"
 	"register/pkg/handler"
+	"register/pkg/models"
 
 	"register/api/services/sheets_service"
 
@@ -32,14 +32,20 @@ import (
 var monthlyCmd = &cobra.Command{
 	Use:   "monthly",
 	Short: "Monthly aggregates monthly budget category expenses and updates the monthly summary tabs",
-	Long:  `Monthly aggregates monthly budget category expenses and updates the monthly summary tabs`,
+	Long: `Monthly aggregates monthly budget category expenses and updates the monthly summary tabs.
+With --year only those years are aggregated, each from the Register or, once the year is closed,
+its archived Register tab, so several years can be summarized together.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		monthly()
 	},
 }
 
+var monthlyYears []int
+
 func init() {
 	rootCmd.AddCommand(monthlyCmd)
+
+	monthlyCmd.Flags().IntSliceVar(&monthlyYears, "year", nil, "the years to aggregate, eg. 2026,2027; default is every year in the Register")
 }
 
 const (
@@ -61,20 +67,13 @@ func monthly() {
 	}
 	qHandler := handler.NewQueryHandler(conn)
 
-	sheetsProvider, err := sheets_provider.New(options.SpreadsheetID, config)
+	sheetsProvider, err := newSheetsProvider()
 	checkError(err)
 	sheetsService := sheets_service.New(sheetsProvider)
-	checkError(err)
-	err = sheetsService.NewRegisterSheet(config)
-
-	fmt.Printf("Reading Register...\n")
-	_, err = sheetsService.ReadRegisterSheet()
-	checkError(err)
+	sheetsService.Income = incomeSources()
 
 	cols := qHandler.GetColumns()
-
-	fmt.Println("Aggregating...")
-	catAgg, payeeAgg := sheetsService.Aggregate(cols)
+	catAgg, payeeAgg := aggregateRegisters(sheetsService, cols, monthlyYears)
 
 	//sheets_service.WriteJSONFile(jsonDir+"columns.json", cols)
 	//sheets_service.WriteJSONFile(jsonDir+"register.json", sheetsService.RegisterSheet.Register)
@@ -82,6 +81,46 @@ func monthly() {
 	//sheets_service.WriteJSONFile(jsonDir+"payee_agg.json", payeeAgg)
 	/**/
 	fmt.Println("Updating...")
-	sheetsService.UpdateMonthlyCategories("MonthlyCategories", catAgg, cols)
-	sheetsService.UpdateMonthlyPayees("MonthlyPayees", payeeAgg)
+	sheetsService.UpdateMonthlyCategories(sheets_service.MonthlyCategoriesTabName, catAgg, cols)
+	sheetsService.UpdateMonthlyPayees(sheets_service.MonthlyPayeesTabName, payeeAgg)
+}
+
+// aggregateRegisters aggregates the years' Register entries: a closed year from its archived Register tab
+// and the other years from the Register, so no entry is summed twice; no years aggregates every year in
+// the Register
+func aggregateRegisters(sheetsService *sheets_service.SheetsService, cols []models.Column, years []int) (map[string]map[string]float64, map[string]map[string]float64) {
+	type registerTab struct {
+		name  string
+		years []int
+	}
+	var tabs []registerTab
+	var live []int
+	for _, y := range years {
+		tab := sheets_service.ArchiveTabName(sheets_service.RegisterTabName, y)
+		exists, err := sheetsService.TabExists(tab)
+		checkError(err)
+		if exists {
+			tabs = append(tabs, registerTab{tab, []int{y}})
+		} else {
+			live = append(live, y)
+		}
+	}
+	if len(years) == 0 || len(live) > 0 {
+		tabs = append([]registerTab{{sheets_service.RegisterTabName, live}}, tabs...)
+	}
+
+	catAgg := make(map[string]map[string]float64)
+	payeeAgg := make(map[string]map[string]float64)
+	for _, tab := range tabs {
+		checkError(sheetsService.NewRegisterTab(config, tab.name))
+		fmt.Printf("Reading %s...\n", tab.name)
+		_, err := sheetsService.ReadRegisterSheet()
+		checkError(err)
+
+		fmt.Println("Aggregating...")
+		cats, payees := sheetsService.Aggregate(cols, tab.years)
+		sheets_service.MergeAggregate(catAgg, cats)
+		sheets_service.MergeAggregate(payeeAgg, payees)
+	}
+	return catAgg, payeeAgg
 }
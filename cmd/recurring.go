@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"register/api/services/sheets_service"
+	"register/pkg/models"
+	"register/pkg/recurring"
+
+	"github.com/spf13/cobra"
+)
+
+var recurringCmd = &cobra.Command{
+	Use:   "recurring",
+	Short: "Lists subscriptions and other charges that recur weekly, monthly or yearly",
+	Long: `Recurring finds merchants that charge at a regular interval with a stable or slowly changing
+amount, from the transactions in the database or, with --register, the Register sheet. Each is
+listed with its cadence, next expected charge, average amount and price changes. Subscriptions
+that missed their next charge are flagged stopped and those whose last charge changed price are
+flagged changed.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		listRecurring()
+	},
+}
+
+var recurringOptions struct {
+	Register bool
+	Changes  bool
+}
+
+func init() {
+	rootCmd.AddCommand(recurringCmd)
+
+	recurringCmd.Flags().BoolVar(&recurringOptions.Register, "register", false, "read the Register sheet instead of the database")
+	recurringCmd.Flags().BoolVar(&recurringOptions.Changes, "changes", false, "list every price change of each subscription")
+}
+
+func listRecurring() {
+	var charges []recurring.Charge
+	if recurringOptions.Register {
+		sheetsProvider, err := newSheetsProvider()
+		checkError(err)
+		sheetsService := sheets_service.New(sheetsProvider)
+		checkError(sheetsService.NewRegisterSheet(config))
+		fmt.Println("Reading Register...")
+		register, err := sheetsService.ReadRegisterSheet()
+		checkError(err)
+		charges = recurring.FromRegister(register.Register)
+	} else {
+		charges = recurring.FromTransactions(getQueryHandler().GetTransactions())
+	}
+
+	subs := recurring.Detect(charges, time.Now())
+	if len(subs) == 0 {
+		fmt.Println("No recurring charges")
+		return
+	}
+	fmt.Printf("    %-30s %-8s %7s %9s %-10s %-10s %s\n", "Name", "Cadence", "Charges", "Average", "Last", "Next", "Flags")
+	for _, s := range subs {
+		var flags []string
+		if s.Stopped {
+			flags = append(flags, "stopped")
+		}
+		if s.PriceChanged {
+			flags = append(flags, fmt.Sprintf("changed %.2f -> %.2f", s.PriceChanges[len(s.PriceChanges)-1].From, s.LastAmount))
+		}
+		fmt.Printf("    %-30s %-8s %7d %9.2f %-10s %-10s %s\n", s.Name, s.Cadence, s.Charges, s.AverageAmount,
+			s.Last.Format(models.RegisterDateFormat), s.NextExpected.Format(models.RegisterDateFormat), strings.Join(flags, ", "))
+		if recurringOptions.Changes {
+			for _, c := range s.PriceChanges {
+				fmt.Printf("        %s  %8.2f -> %8.2f\n", c.Date.Format(models.RegisterDateFormat), c.From, c.To)
+			}
+		}
+	}
+}
@@ -19,10 +19,14 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"register/pkg/banking"
 	cfg "register/pkg/config"
+	"register/pkg/handler"
+	"register/pkg/income"
+	"register/pkg/token_store"
 
 	"github.com/plaid/plaid-go/v15/plaid"
 	"github.com/spf13/cobra"
@@ -48,8 +52,13 @@ const ConfigFile = "config/config.json"
 var options = &cfg.Options{}
 var config = &cfg.Config{}
 
+// sheetsCallBudget is the maximum number of Google Sheets API calls a single run may make; by default there is
+// no limit, as a partial write would leave the Register half updated
+var sheetsCallBudget int
+
 type Client struct {
 	BankClient *banking.Client
+	Users      *handler.Query // the server's users and their items
 }
 
 var client = new(Client)
@@ -104,6 +113,7 @@ func init() {
 	//rootCmd.PersistentFlags().StringVarP(&bankIDs, "bank-ids", "b", "fidelity", "comma-separated list of bank IDs")
 	rootCmd.PersistentFlags().StringVarP(&options.SpreadsheetID, "ss_id", "s", config.SpreadsheetID, "The Google spreadsheet id")
 	rootCmd.PersistentFlags().BoolVarP(&options.Debug, "debug", "d", false, "Debug mode")
+	rootCmd.PersistentFlags().IntVar(&sheetsCallBudget, "sheets-call-budget", 0, "maximum number of Google Sheets API calls per run, eg. to stop a runaway run; 0 for no limit")
 
 	options.BankIDs = strings.Split(bankIDs, ",")
 }
@@ -117,6 +127,8 @@ func getBankingClient() *Client {
 	if config.PlaidEnvironment == "production" {
 		plaidEnvironment = plaid.Production
 	}
+	tokens, err := newTokenStore()
+	checkError(err)
 	client.BankClient = banking.NewClient(&banking.ClientOptions{
 		UserID:           config.UserID,
 		Banks:            config.Banks,
@@ -126,10 +138,31 @@ func getBankingClient() *Client {
 		PlaidSecret:      config.PlaidEnvSecrets[config.PlaidEnvironment],
 		PlaidEnvironment: plaidEnvironment,
 		PlaidTokensDir:   config.PlaidTokensDir,
+		Tokens:           tokens,
+		ItemStatusFile:   filepath.Join(config.PlaidTokensDir, banking.ItemStatusFileName),
+		PlaidWebhookURL:  config.PlaidWebhookURL,
+		Income:           incomeSources(),
 	})
 	return client
 }
 
+// incomeSources returns the IncomeSources config setting, or the default paycheck when it is empty
+func incomeSources() *income.Sources {
+	sources, err := income.New(config.IncomeSources)
+	checkError(err)
+	return sources
+}
+
+// newTokenStore returns the Plaid access token store selected by the TokenStore config setting:
+// "encrypted" for the encrypted store in PlaidTokensDir, otherwise the legacy token files
+func newTokenStore() (token_store.Store, error) {
+	return token_store.New(token_store.ConfigOptions{
+		Dir:     config.PlaidTokensDir,
+		Backend: config.TokenStore,
+		KeyFile: config.TokenKeyFile,
+	})
+}
+
 func checkError(err error) {
 	if err != nil {
 		fmt.Println(err.Error())
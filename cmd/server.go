@@ -18,24 +18,29 @@ package cmd
 
 import (
 	"encoding/json"
-	"fmt"
 	"log"
 	"net/http"
-	"os"
+	"regexp"
 
+	"register/api/services/sheets_service"
+	"register/pkg/api"
+	"register/pkg/banking"
 	cfg "register/pkg/config"
+	"register/pkg/models"
 	"register/pkg/plaid_auth"
+	"register/pkg/webhook"
+	"register/pkg/window"
 
 	"github.com/gorilla/mux"
-	"github.com/gorilla/sessions"
-	"github.com/rs/cors"
 	"github.com/spf13/cobra"
 )
 
 var serverCmd = &cobra.Command{
 	Use:   "server",
 	Short: "Provides a web service function",
-	Long:  ``,
+	Long: `Server serves the JSON API under /api/v1 and the Plaid Link and webhook endpoints. API requests
+authenticate with an API token, "Authorization: Bearer <token>", or a username and password by basic
+auth; see register users. Cross-origin requests are allowed from CORSAllowedOrigins only.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		server()
 	},
@@ -48,25 +53,42 @@ func init() {
 	client = getBankingClient()
 }
 
-var store = sessions.NewCookieStore([]byte(os.Getenv("SESSION_KEY")))
+// idRe matches the bank and item IDs given to the Link endpoints
+var idRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
 
 func server() {
+	client.Users = getQueryHandler()
+	checkError(client.Users.MigrateUsers())
+	transactionWindow(0, "") // exits on a bad StartDate or EndDate config setting before serving
+	apiServer := api.New(api.ConfigOptions{
+		Store:   client.Users,
+		Bank:    client.BankClient,
+		Budgets: readBudgets,
+		Window:  func() window.Window { return transactionWindow(0, "") },
+	})
+
 	r := mux.NewRouter()
 
 	fs := http.FileServer(http.Dir("./www/public/"))
 	r.PathPrefix("/public").Handler(http.StripPrefix("/public/", fs))
 
-	r.HandleFunc("/api/test", test).Methods("GET", "OPTIONS")
-	r.HandleFunc("/api/create_link_token", client.createLinkToken).Methods("GET", "OPTIONS")
-	r.HandleFunc("/api/exchange_public_token", client.exchangePublicToken).Methods("POST")
-	r.HandleFunc("/api/get_accounts", client.getAccounts).Methods("GET")
-	r.HandleFunc("/api/get_balance", client.getBalance).Methods("GET")
-	r.HandleFunc("/api/get_transactions", client.getTransactions).Methods("GET")
-	r.HandleFunc("/api/is_account_connected", isAccountConnected).Methods("GET")
+	r.HandleFunc("/api/test", test).Methods("GET")
+	r.Handle("/api/create_link_token", apiServer.Authenticate(http.HandlerFunc(client.createLinkToken))).Methods("GET")
+	r.Handle("/api/exchange_public_token", apiServer.Authenticate(http.HandlerFunc(client.exchangePublicToken))).Methods("POST")
+
+	// Plaid webhooks queue a sync of the item's transactions into the database
+	queue := webhook.NewQueue(client.syncItem, 100)
+	go queue.Run(ctx)
+	r.Handle("/api/webhook", webhook.New(webhook.ConfigOptions{
+		Keys:  client.BankClient,
+		Items: client.BankClient,
+		Queue: queue,
+	})).Methods("POST")
+
+	apiServer.Register(r)
 
 	log.Println("Server will start at https://localhost:9000/")
-	handler := cors.Default().Handler(r)
-	log.Fatal(http.ListenAndServeTLS(":9000", config.CertFile, config.KeyFile, handler))
+	log.Fatal(http.ListenAndServeTLS(":9000", config.CertFile, config.KeyFile, api.CORS(config.CORSAllowedOrigins, r)))
 }
 
 func test(w http.ResponseWriter, r *http.Request) {
@@ -76,153 +98,140 @@ func test(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("{\"hello\": \"world\"}"))
 }
 
+// ownsItem returns true if the item is one of the user's
+func (c *Client) ownsItem(user *models.User, bankID, itemID string) (bool, error) {
+	items, err := c.Users.GetUserItems(user.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, i := range items {
+		if i.BankID == bankID && i.ItemID == itemID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (c *Client) createLinkToken(w http.ResponseWriter, r *http.Request) {
 	log.Println("createLinkToken()")
 
-	linkToken, err := plaid_auth.GetLinkToken(c.BankClient)
+	// ?institution=<bank id>&item=<item id> creates an update-mode token to re-link one of the user's items
+	var linkToken string
+	var err error
+	institution, item := r.URL.Query().Get("institution"), r.URL.Query().Get("item")
+	if institution != "" && item != "" {
+		var owns bool
+		owns, err = c.ownsItem(api.UserFromContext(r.Context()), institution, item)
+		if err != nil {
+			api.WriteError(w, http.StatusInternalServerError, api.CodeInternal, err.Error())
+			return
+		}
+		if !owns {
+			api.WriteError(w, http.StatusNotFound, api.CodeNotFound, "no item "+institution+"/"+item)
+			return
+		}
+		var accessToken string
+		accessToken, err = c.BankClient.AccessToken(institution, item)
+		if err != nil {
+			api.WriteError(w, http.StatusNotFound, api.CodeNotFound, err.Error())
+			return
+		}
+		linkToken, err = plaid_auth.GetUpdateLinkToken(c.BankClient, accessToken)
+	} else {
+		linkToken, err = plaid_auth.GetLinkToken(c.BankClient)
+	}
 	if err != nil {
-		log.Println(err.Error())
-		fmt.Print(err)
+		api.WriteError(w, http.StatusBadGateway, api.CodeUnavailable, err.Error())
+		return
 	}
 	w.Header().Set("Content-Type", "application/json")
-	s := LinkToken{LinkToken: linkToken}
-	json.NewEncoder(w).Encode(s)
+	_ = json.NewEncoder(w).Encode(LinkToken{LinkToken: linkToken})
 }
 
 func (c *Client) exchangePublicToken(w http.ResponseWriter, r *http.Request) {
 	log.Println("exchangePublicToken()")
+	user := api.UserFromContext(r.Context())
 
-	var publicToken PublicToken
-
-	err := json.NewDecoder(r.Body).Decode(&publicToken)
-	if err != nil {
-		log.Println(err.Error())
-		http.Error(w, err.Error(), http.StatusBadRequest)
+	// ?institution=<bank id>&item=<item id> names the config item the token belongs to
+	institution, item := r.URL.Query().Get("institution"), r.URL.Query().Get("item")
+	if !idRe.MatchString(institution) || !idRe.MatchString(item) {
+		api.WriteError(w, http.StatusBadRequest, api.CodeBadRequest, "institution and item query parameters are required")
 		return
 	}
 
-	accessToken, err := plaid_auth.ExchangePublicToken(c.BankClient, publicToken.PublicToken, ctx)
+	// an item linked by another user is not replaced
+	owns, err := c.ownsItem(user, institution, item)
 	if err != nil {
-		log.Println(err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		api.WriteError(w, http.StatusInternalServerError, api.CodeInternal, err.Error())
 		return
 	}
-	err = os.WriteFile(config.PlaidTokensDir+"/"+"AccessToken.txt", []byte(accessToken+"\n"), 0644)
-	if err != nil {
-		log.Printf("could not write access token: %s", err.Error())
-	}
-
-	session, err := store.Get(r, "register")
-	if err != nil {
-		log.Println(err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if _, err := c.BankClient.AccessToken(institution, item); err == nil && !owns {
+		api.WriteError(w, http.StatusForbidden, api.CodeForbidden, "item "+institution+"/"+item+" is already linked")
 		return
 	}
-	session.Values["AccessToken"] = accessToken
-	err = sessions.Save(r, w)
-	if err != nil {
-		log.Println(err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+
+	var publicToken PublicToken
+	if err := json.NewDecoder(r.Body).Decode(&publicToken); err != nil {
+		api.WriteError(w, http.StatusBadRequest, api.CodeBadRequest, err.Error())
 		return
 	}
-}
-
-func (c *Client) getAccounts(w http.ResponseWriter, r *http.Request) {
-	log.Println("getAccounts()")
 
-	session, err := store.Get(r, "register")
+	accessToken, err := plaid_auth.ExchangePublicToken(c.BankClient, publicToken.PublicToken, ctx)
 	if err != nil {
-		log.Println(err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		api.WriteError(w, http.StatusBadGateway, api.CodeUnavailable, err.Error())
 		return
 	}
-	accessToken := fmt.Sprintf("%s", session.Values["AccessToken"])
-	if accessToken == "" {
-		log.Println(err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if err := c.BankClient.SaveAccessToken(institution, item, accessToken); err != nil {
+		api.WriteError(w, http.StatusInternalServerError, api.CodeInternal, "could not save access token: "+err.Error())
 		return
 	}
-
-	balances, err := c.BankClient.GetAccounts(accessToken, ctx)
-	if err != nil {
-		_, _ = w.Write([]byte(err.Error()))
+	if err := c.Users.AddUserItem(&models.UserItem{UserID: user.ID, BankID: institution, ItemID: item}); err != nil {
+		api.WriteError(w, http.StatusInternalServerError, api.CodeInternal, "could not add the item: "+err.Error())
 		return
 	}
-	_ = json.NewEncoder(w).Encode(balances)
-}
-
-func (c *Client) getBalance(w http.ResponseWriter, r *http.Request) {
-	log.Println("getBalance()")
 
-	session, err := store.Get(r, "register")
-	if err != nil {
-		log.Println(err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	// records the Plaid item_id that webhooks for this item are addressed by
+	if status := c.BankClient.CheckItem(institution, item, ctx); status.Status != banking.ItemStatusOK {
+		log.Printf("%s item %s: %s %s", institution, item, status.ErrorCode, status.Message)
 	}
-	accessToken := fmt.Sprintf("%s", session.Values["AccessToken"])
-	if accessToken == "" {
+	if err := c.BankClient.SaveItemStatuses(); err != nil {
 		log.Println(err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
 	}
-
-	balance, err := c.BankClient.GetBalance(accessToken, "fidelity", ctx)
-	if err != nil {
-		_, _ = w.Write([]byte(err.Error()))
-		return
-	}
-	_ = json.NewEncoder(w).Encode(balance)
+	w.WriteHeader(http.StatusNoContent)
 }
 
-func (c *Client) getTransactions(w http.ResponseWriter, r *http.Request) {
-	log.Println("getTransactions()")
-
-	session, err := store.Get(r, "register")
+// readBudgets reads the budget categories from the Budget sheet
+func readBudgets() ([]*sheets_service.BudgetEntry, error) {
+	sheetsProvider, err := newSheetsProvider()
 	if err != nil {
-		log.Println(err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return nil, err
 	}
-	accessToken := fmt.Sprintf("%s", session.Values["AccessToken"])
-	if accessToken == "" {
-		log.Println(err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	sheetsService := sheets_service.New(sheetsProvider)
+	if err := sheetsService.NewBudgetSheet(config); err != nil {
+		return nil, err
 	}
-
-	// start from 2 weeks ago
-	//startDate := weeksAgo(2)
-	//endDate := today()
-	startDate := config.StartDate
-	endDate := config.EndDate
-	transactions, err := client.BankClient.GetTransactions(options.BankIDs, startDate, endDate)
+	sheet, err := sheetsService.ReadBudgetSheet()
 	if err != nil {
-		log.Println(err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		return nil, err
 	}
-	_ = json.NewEncoder(w).Encode(transactions)
+	return sheet.BudgetEntries, nil
 }
 
-func isAccountConnected(w http.ResponseWriter, r *http.Request) {
-	log.Println("isAccountConnected()")
-	session, err := store.Get(r, "register")
+// syncItem adds an item's transactions that are not yet in the database, as queued by a webhook
+func (c *Client) syncItem(bankID, itemID string) error {
+	log.Printf("syncItem(%s, %s)", bankID, itemID)
+
+	w := transactionWindow(0, "")
+	transactions, err := c.BankClient.GetItemTransactions(bankID, itemID, w.StartDate(), w.EndDate())
 	if err != nil {
-		log.Println(err.Error())
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-	accessToken := fmt.Sprintf("%s", session.Values["AccessToken"])
-	type ConnectedResponse struct {
-		Status bool `json:"status"`
-	}
-	if accessToken != "" {
-		//_ = json.NewEncoder(w).Encode("{status: true}")
-		resp := ConnectedResponse{Status: true}
-		_ = json.NewEncoder(w).Encode(resp)
-	} else {
-		resp := ConnectedResponse{Status: false}
-		_ = json.NewEncoder(w).Encode(resp)
+		// ItemErrors are recorded in the item statuses
+		_ = c.BankClient.SaveItemStatuses()
+		return err
 	}
+
+	qHandler := getQueryHandler()
+	transactions = c.BankClient.FormatMerchantNames(transactions, qHandler.GetLookupData())
+	added := saveTransactions(qHandler, transactions)
+	log.Printf("%s item %s: %d new transactions", bankID, itemID, added)
+	return c.BankClient.SaveItemStatuses()
 }
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"register/api/services/sheets_service"
+
+	"github.com/spf13/cobra"
+)
+
+var sheetCmd = &cobra.Command{
+	Use:   "sheet",
+	Short: "Spreadsheet maintenance commands",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var sheetCheckCmd = &cobra.Command{
+	Use:   "check",
+	Short: "Verifies the spreadsheet tabs, ranges, formulas and columns",
+	Long: `Check verifies that the Register, Budget, MonthlyCategories and MonthlyPayees tabs exist,
+that the configured Register and Budget row ranges fit the sheets, that the Register, Cleared
+and Delta formulas (H-J) follow the same pattern on the last row, that every DB column has a
+matching Register column and that the Budget categories match the DB category columns.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		sheetCheck()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(sheetCmd)
+	sheetCmd.AddCommand(sheetCheckCmd)
+}
+
+func sheetCheck() {
+	qHandler := getQueryHandler()
+
+	sheetsProvider, err := newSheetsProvider()
+	checkError(err)
+	sheetsService := sheets_service.New(sheetsProvider)
+
+	fmt.Println("Checking spreadsheet...")
+	report, err := sheetsService.CheckSchema(config, qHandler.GetColumns())
+	checkError(err)
+
+	printSchemaReport(report)
+	if !report.Passed() {
+		os.Exit(1)
+	}
+}
+
+func printSchemaReport(report *sheets_service.SchemaReport) {
+	for _, check := range report.Checks {
+		status := "PASS"
+		if !check.Passed {
+			status = "FAIL"
+		}
+		fmt.Printf("    [%s] %s\n", status, check.Name)
+		for _, d := range check.Details {
+			fmt.Printf("           %s\n", d)
+		}
+	}
+	if report.Passed() {
+		fmt.Println("All checks passed")
+	} else {
+		fmt.Println("Some checks failed")
+	}
+}
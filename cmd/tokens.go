@@ -0,0 +1,162 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"register/pkg/token_store"
+
+	"github.com/spf13/cobra"
+)
+
+var tokensCmd = &cobra.Command{
+	Use:   "tokens",
+	Short: "Plaid access token store commands",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var tokensListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the stored access tokens by institution and item",
+	Run: func(cmd *cobra.Command, args []string) {
+		tokensList()
+	},
+}
+
+var tokensInitKeyCmd = &cobra.Command{
+	Use:   "init-key",
+	Short: "Creates the TokenKeyFile with a new random key for the encrypted token store",
+	Run: func(cmd *cobra.Command, args []string) {
+		tokensInitKey()
+	},
+}
+
+var tokensMigrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Copies the plain text <item>AccessToken.txt files into the encrypted token store",
+	Long: `Migrate copies the token file of every configured item, and of every bank without items
+by its Source, into the encrypted token store; set TokenStore to "encrypted" first. The plain
+text files are left in place so they can be checked and removed by hand.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		tokensMigrate()
+	},
+}
+
+var tokensRotateCmd = &cobra.Command{
+	Use:   "rotate <institution> <item>",
+	Short: "Has Plaid replace an item's access token and stores the new token",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		checkError(getBankingClient().BankClient.RotateAccessToken(args[0], args[1], ctx))
+		fmt.Printf("Rotated the access token of %s/%s\n", args[0], args[1])
+	},
+}
+
+var tokensRemoveCmd = &cobra.Command{
+	Use:   "remove <institution> <item>",
+	Short: "Removes the item at Plaid and deletes its stored access token",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		checkError(getBankingClient().BankClient.RemoveAccessToken(args[0], args[1], ctx))
+		fmt.Printf("Removed %s/%s\n", args[0], args[1])
+	},
+}
+
+var tokensRotateKeyCmd = &cobra.Command{
+	Use:   "rotate-key",
+	Short: "Re-encrypts the token store with a new key and writes it to the TokenKeyFile",
+	Run: func(cmd *cobra.Command, args []string) {
+		tokensRotateKey()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(tokensCmd)
+	tokensCmd.AddCommand(tokensListCmd)
+	tokensCmd.AddCommand(tokensInitKeyCmd)
+	tokensCmd.AddCommand(tokensMigrateCmd)
+	tokensCmd.AddCommand(tokensRotateCmd)
+	tokensCmd.AddCommand(tokensRemoveCmd)
+	tokensCmd.AddCommand(tokensRotateKeyCmd)
+}
+
+func tokensList() {
+	tokens, err := newTokenStore()
+	checkError(err)
+	keys, err := tokens.List()
+	checkError(err)
+	for _, k := range keys {
+		fmt.Println(k.String())
+	}
+}
+
+func tokensInitKey() {
+	if config.TokenKeyFile == "" {
+		checkError(fmt.Errorf("TokenKeyFile is not set in %s", ConfigFile))
+	}
+	if _, err := os.Stat(config.TokenKeyFile); err == nil {
+		checkError(fmt.Errorf("%s already exists; use tokens rotate-key", config.TokenKeyFile))
+	}
+	key, err := token_store.GenerateKey()
+	checkError(err)
+	checkError(os.WriteFile(config.TokenKeyFile, []byte(key+"\n"), 0600))
+	fmt.Printf("Wrote %s\n", config.TokenKeyFile)
+}
+
+func tokensMigrate() {
+	tokens, err := newTokenStore()
+	checkError(err)
+	if _, ok := tokens.(*token_store.FileStore); !ok {
+		checkError(fmt.Errorf("TokenStore is not %q", token_store.EncryptedBackend))
+	}
+	plain := token_store.NewPlainStore(config.PlaidTokensDir)
+
+	for bankID, bank := range config.Banks {
+		for _, itemID := range bankItemIDs(bank) {
+			token, err := plain.Get(bankID, itemID)
+			if errors.Is(err, token_store.ErrNotFound) {
+				continue
+			}
+			checkError(err)
+			checkError(tokens.Put(bankID, itemID, token))
+			fmt.Printf("    %s/%s <- %s\n", bankID, itemID, filepath.Join(config.PlaidTokensDir, itemID+"AccessToken.txt"))
+		}
+	}
+}
+
+func tokensRotateKey() {
+	tokens, err := newTokenStore()
+	checkError(err)
+	fileStore, ok := tokens.(*token_store.FileStore)
+	if !ok {
+		checkError(fmt.Errorf("TokenStore is not %q", token_store.EncryptedBackend))
+	}
+	if config.TokenKeyFile == "" {
+		checkError(fmt.Errorf("TokenKeyFile is not set in %s", ConfigFile))
+	}
+	if os.Getenv(token_store.KeyEnvVar) != "" {
+		checkError(fmt.Errorf("%s is set and takes precedence over the key file; unset it to rotate the key file", token_store.KeyEnvVar))
+	}
+
+	encoded, err := token_store.GenerateKey()
+	checkError(err)
+	newKey, err := base64.StdEncoding.DecodeString(encoded)
+	checkError(err)
+
+	// keep the old key until the store has been re-encrypted with the new one
+	oldKeyFile := config.TokenKeyFile + ".old"
+	checkError(os.Rename(config.TokenKeyFile, oldKeyFile))
+	checkError(os.WriteFile(config.TokenKeyFile, []byte(encoded+"\n"), 0600))
+	if err := fileStore.RotateKey(newKey); err != nil {
+		_ = os.Rename(oldKeyFile, config.TokenKeyFile)
+		checkError(err)
+	}
+	checkError(os.Remove(oldKeyFile))
+	fmt.Printf("Rotated the token store key in %s\n", config.TokenKeyFile)
+}
@@ -18,23 +18,25 @@ package cmd
 
 import (
 	"bufio"
-	"bytes"
+	"errors"
 	"fmt"
 	"math"
 	"os"
-	"os/exec"
 	"strconv"
 	"strings"
 	"time"
 
-	"register/api/providers/sheets_provider"
 	"register/api/services/sheets_service"
+	"register/pkg/alerts"
 	"register/pkg/banking"
 	cfg "register/pkg/config"
 	"register/pkg/csv"
 	"register/pkg/driver"
 	"register/pkg/handler"
 	"register/pkg/models"
+	"register/pkg/ofx"
+	"register/pkg/qif"
+	"register/pkg/window"
 
 	"github.com/spf13/cobra"
 )
@@ -64,17 +66,33 @@ func init() {
 
 	updateCmd.Flags().BoolVarP(&options.Update, "no-updates", "u", false, "If set, no spreadsheet updates performed")
 	updateCmd.Flags().BoolVarP(&options.UseCSVFiles, "csv", "c", false, "Read CSV files; default=false")
+	updateCmd.Flags().StringVar(&ingestPattern, "ingest", "", "directory or glob, relative to FinanceDir, of CSV downloads to detect, read and archive; implies --csv")
+	updateCmd.Flags().BoolVar(&strictCSV, "strict", false, "stop if any CSV row cannot be read; default is to leave the row out with a warning")
+	updateCmd.Flags().StringVar(&updateSince, "since", "", "read transactions since a period ago, eg. 30d, 8w or 3m, the last run (last-run) or a date; default is the StartDate config setting or the year to date")
+	updateCmd.Flags().IntVar(&updateYear, "year", 0, "read the transactions of a calendar year, eg. 2027")
 }
 
+var (
+	ingestPattern string
+	strictCSV     bool
+	updateSince   string
+	updateYear    int
+)
+
 func update(cmd *cobra.Command, args []string) {
 	var (
 		client            *Client
 		csvClient         *csv.Client
+		ofxClient         *ofx.Client
+		qifClient         *qif.Client
+		ingestion         *csv.Ingestion
 		transactions      []*models.Transaction
 		plaidTransactions []*models.Transaction
 		csvTransactions   []*models.Transaction
 		err               error
+		allBanksRead      bool // every bank was read from Plaid, so the window is covered for a last-run update
 	)
+	w := transactionWindow(updateYear, updateSince)
 
 	conn, err := driver.ConnectSQL(&driver.ConnectParams{
 		DBType: driver.DBType(config.DBType),
@@ -87,9 +105,10 @@ func update(cmd *cobra.Command, args []string) {
 	checkError(err)
 	qHandler := handler.NewQueryHandler(conn)
 
-	sheetsProvider, err := sheets_provider.New(options.SpreadsheetID, config)
+	sheetsProvider, err := newSheetsProvider()
 	checkError(err)
 	sheetsService := sheets_service.New(sheetsProvider)
+	sheetsService.Income = incomeSources()
 	checkError(err)
 	err = sheetsService.NewRegisterSheet(config)
 	checkError(err)
@@ -98,28 +117,63 @@ func update(cmd *cobra.Command, args []string) {
 	_, err = sheetsService.ReadRegisterSheet()
 	checkError(err)
 
+	// a closed year's entries are folded into the balance forward row, so its transactions would be new again
+	if closed := sheetsService.ClosedYear(); closed != 0 {
+		w, err = w.AfterYear(closed)
+		checkError(err)
+	}
+
 	client = getBankingClient()
+	var csvMappings map[string]*csv.Mapping
+	if config.CSVMappingsFile != "" {
+		csvMappings, err = csv.LoadMappings(config.CSVMappingsFile)
+		checkError(err)
+	}
 	csvClient = csv.New(csv.ConfigOptions{
 		FinanceDir: config.FinanceDir,
 		Banks:      config.Banks,
+		Mappings:   csvMappings,
 	})
 
-	// always use the CSV file for fidelity
-	fmt.Println("Getting Fidelity transactions (CSV)...")
-	transactions, err = getCSVTransactions(csvClient, []string{"fidelity"})
-	checkError(err)
+	ofxClient = ofx.New(ofx.ConfigOptions{
+		FinanceDir: config.FinanceDir,
+		Banks:      config.Banks,
+		Recorded:   recordedTransactionIDs(qHandler),
+	})
+
+	qifClient = qif.New(qif.ConfigOptions{
+		FinanceDir: config.FinanceDir,
+		Banks:      config.Banks,
+	})
 
-	if options.UseCSVFiles {
-		fmt.Println("Getting Wells Fargo & Chase transactions (CSV)...")
-		csvTransactions, err = getCSVTransactions(csvClient, []string{"chase", "wellsfargo"})
+	if ingestPattern != "" {
+		// every bank's download is detected from the files themselves
+		fmt.Printf("Ingesting statement downloads (%s)...\n", ingestPattern)
+		ingestion, err = csvClient.Ingest(ingestPattern)
 		checkError(err)
+		printIngestion(ingestion)
+		transactions = ingestion.Transactions
+		options.UseCSVFiles = true
 	} else {
-		fmt.Println("Getting Wells Fargo & Chase transactions (Plaid)...")
-		options.BankIDs = []string{"chase", "wellsfargo"}
-		plaidTransactions, err = getTransactions(client, options.BankIDs)
+		// always use the statement download for fidelity: OFX/QFX or QIF when configured, otherwise CSV
+		fmt.Println("Getting Fidelity transactions (file)...")
+		transactions, err = getFileTransactions(csvClient, ofxClient, qifClient, []string{"fidelity"})
 		checkError(err)
+
+		if options.UseCSVFiles {
+			fmt.Println("Getting Wells Fargo & Chase transactions (file)...")
+			csvTransactions, err = getFileTransactions(csvClient, ofxClient, qifClient, []string{"chase", "wellsfargo"})
+			checkError(err)
+		} else {
+			fmt.Printf("Getting Wells Fargo & Chase transactions (Plaid, %s to %s)...\n", w.StartDate(), w.EndDate())
+			options.BankIDs = []string{"chase", "wellsfargo"}
+			plaidTransactions, err = getTransactions(client, options.BankIDs, w)
+			allBanksRead = !checkItemErrors(err)
+		}
 	}
 
+	checkCSVReport(csvClient.Report)
+
 	transactions = append(transactions, csvTransactions...)
 	transactions = append(transactions, plaidTransactions...)
 
@@ -179,16 +233,20 @@ func update(cmd *cobra.Command, args []string) {
 
 	printTransactions(transactions)
 
-	//if !options.Update {
-	//	fmt.Println("Updating transactions table...")
-	//	qHandler.UpdateTransactionTables(transactions)
-	//}
-
 	if len(transactions) == 0 {
 		fmt.Println("No updates needed")
+		if !options.Update {
+			archiveIngestion(csvClient, ingestion)
+		}
 		return
 	}
 
+	// merchants named at the prompts below are new
+	knownMerchants := make(map[string]bool)
+	for _, m := range qHandler.GetMerchants() {
+		knownMerchants[m.Name] = true
+	}
+
 	if needTransactionName(transactions) {
 		fmt.Println("Info needed...")
 		printColumns(qHandler)
@@ -213,17 +271,11 @@ func update(cmd *cobra.Command, args []string) {
 		fmt.Printf("    (%3d) %-12s %-10s %8.2f %-30s %s\n", i+1, r.Source, r.Date, -1*r.Amount, r.Name, r.Note)
 	}
 
-	// add the needed number of rows for transactions
+	// add the needed number of rows for transactions through this provider, so that with the xlsx backend
+	// the workbook it saves below has them
 	fmt.Println("Adding rows...")
-	out, errOut, err := shellout("./register copy -n " + strconv.Itoa(len(transactions)))
-	if err != nil {
-		fmt.Printf("error: %v\n", err)
-		fmt.Println("--- stdout ---")
-		fmt.Println(out)
-		fmt.Println("--- stderr ---")
-		fmt.Println(errOut)
-		return
-	}
+	err = sheetsService.CopyRows(len(transactions))
+	checkError(err)
 
 	fmt.Printf("Updating spreadsheet...\n")
 	columns := qHandler.GetColumns()
@@ -238,27 +290,150 @@ func update(cmd *cobra.Command, args []string) {
 	_, err = sheetsService.WriteCell("G2", fmt.Sprintf("=SUM(G1-I%d)", lastRowUpdated))
 	checkError(err)
 
+	archiveIngestion(csvClient, ingestion)
+
+	checkAlerts(qHandler, sheetsService.BudgetSheet.BudgetEntries, columns, knownMerchants, transactions)
+
+	if _, err := recordEnvelopeEvents(qHandler, sheetsService, columns, transactions); err != nil {
+		fmt.Printf("Warning: envelope events not recorded: %s\n", err.Error())
+	}
+
+	fmt.Println("Updating transactions table...")
+	fmt.Printf("    %d transactions added\n", saveTransactions(qHandler, transactions))
+
 	if !options.UseCSVFiles {
 		fmt.Println("Getting accounts balances...")
 		balances := client.BankClient.GetBalances(options.BankIDs)
 		printBalances(balances)
 		fmt.Println("Updating balances...")
 		updateBalances(sheetsService, balances)
+	} else if ofxBankIDs := getOFXBankIDs(ofxClient, []string{"chase", "wellsfargo"}); len(ofxBankIDs) > 0 {
+		fmt.Println("Getting accounts balances (OFX)...")
+		balances := ofxClient.GetBalances(ofxBankIDs)
+		printBalances(balances)
+		fmt.Println("Updating balances...")
+		updateBalances(sheetsService, balances)
+	}
+
+	if updateYear == 0 && allBanksRead {
+		if err := window.WriteLastRun(lastRunFile(), w.End); err != nil {
+			fmt.Printf("Warning: last run not recorded: %s\n", err.Error())
+		}
+	}
+}
+
+// checkCSVReport prints the CSV ingestion report and, with --strict, stops if any row could not be read
+func checkCSVReport(report *csv.Report) {
+	if len(report.Files) == 0 {
+		return
+	}
+	fmt.Println("CSV ingestion report:")
+	report.Print(os.Stdout)
+	if !report.HasErrors() {
+		return
+	}
+	if strictCSV {
+		fmt.Println("Stopping: CSV rows could not be read (--strict)")
+		os.Exit(1)
+	}
+	fmt.Println("Warning: CSV rows that could not be read were left out")
+}
+
+// saveTransactions adds the transactions written to the Register to the transactions table, which the budget,
+// recurring, forecast, export and envelopes commands read, and returns the number added. Those already in it,
+// eg. added by the webhook server, are skipped.
+func saveTransactions(qHandler *handler.Query, transactions []*models.Transaction) int {
+	recorded := make(map[string]bool)
+	for _, t := range qHandler.GetTransactions() {
+		recorded[transactionRecordID(&t)] = true
+	}
+	var added []*models.Transaction
+	for _, t := range transactions {
+		if !recorded[transactionRecordID(t)] {
+			added = append(added, t)
+		}
+	}
+	if len(added) > 0 {
+		qHandler.UpdateTransactionTables(added)
+	}
+	return len(added)
+}
+
+// recordedTransactionIDs returns the bank transaction IDs in the transactions table, eg. the OFX FITIDs
+// scoped to their bank and account
+func recordedTransactionIDs(qHandler *handler.Query) map[string]bool {
+	recorded := make(map[string]bool)
+	for _, t := range qHandler.GetTransactions() {
+		if t.TransactionID != "" {
+			recorded[t.TransactionID] = true
+		}
+	}
+	return recorded
+}
+
+// transactionRecordID identifies a transaction in the transactions table: the bank's transaction ID, or its
+// Key for transactions read without one, eg. from CSV files
+func transactionRecordID(t *models.Transaction) string {
+	if t.TransactionID != "" {
+		return t.TransactionID
+	}
+	return t.Key
+}
+
+// checkAlerts evaluates the alert rules against the update's transactions and sends any alerts to the
+// configured sinks; alerts that cannot be delivered are printed
+func checkAlerts(qHandler *handler.Query, entries []*sheets_service.BudgetEntry, columns []models.Column,
+	knownMerchants map[string]bool, transactions []*models.Transaction) {
+	triggered := alerts.Evaluate(config.Alerts, alerts.Input{
+		Budget:    entries,
+		Columns:   columns,
+		History:   qHandler.GetTransactions(),
+		New:       transactions,
+		Merchants: knownMerchants,
+	})
+	if len(triggered) == 0 {
+		return
+	}
+	fmt.Println("Alerts:")
+	for _, a := range triggered {
+		fmt.Printf("    %s: %s\n", a.Title, a.Message)
+	}
+	sinks, err := alerts.NewSinks(config.Alerts)
+	if err == nil {
+		err = alerts.Notify(sinks, triggered)
+	}
+	if err != nil {
+		fmt.Printf("Warning: alerts not sent: %s\n", err.Error())
+	}
+}
+
+func printIngestion(ingestion *csv.Ingestion) {
+	for _, f := range ingestion.Files {
+		fmt.Printf("    %-12s %3d transactions  %s\n", f.BankID, f.Transactions, f.File)
+	}
+	for _, f := range ingestion.Skipped {
+		fmt.Printf("    already ingested, skipped: %s\n", f)
+	}
+	for _, f := range ingestion.Unknown {
+		fmt.Printf("    unknown format, skipped: %s\n", f)
 	}
 }
 
-func shellout(command string) (string, string, error) {
-	var stdout bytes.Buffer
-	var stderr bytes.Buffer
-	cmd := exec.Command("bash", "-c", command)
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	err := cmd.Run()
-	return stdout.String(), stderr.String(), err
+// archiveIngestion moves ingested downloads to the archive once the register is up to date
+func archiveIngestion(csvClient *csv.Client, ingestion *csv.Ingestion) {
+	if ingestion == nil {
+		return
+	}
+	fmt.Println("Archiving ingested files...")
+	err := csvClient.Archive(ingestion)
+	checkError(err)
+	for _, file := range ingestion.Kept {
+		fmt.Printf("    Left %s in place, some rows could not be read\n", file)
+	}
 }
 
 func updateBalances(sheetsService *sheets_service.SheetsService, balances map[string]banking.Balance) {
-	if balances[banking.WellsFargoID].Error == nil {
+	if len(balances[banking.WellsFargoID].Accounts) == 0 && balances[banking.WellsFargoID].Error == nil {
 		_, err := sheetsService.WriteCell("G1", balances[banking.WellsFargoID].Amount)
 		checkError(err)
 	}
@@ -266,10 +441,21 @@ func updateBalances(sheetsService *sheets_service.SheetsService, balances map[st
 	//	_, err := sheetsService.WriteCell("AA2", balances[banking.FidelityID].Amount)
 	//	checkError(err)
 	//}
-	if balances[banking.ChaseID].Error == nil {
+	if len(balances[banking.ChaseID].Accounts) == 0 && balances[banking.ChaseID].Error == nil {
 		_, err := sheetsService.WriteCell("AB2", balances[banking.ChaseID].Amount)
 		checkError(err)
 	}
+
+	// banks configured with items write each account's balance to its own cell
+	for _, balance := range balances {
+		for _, account := range balance.Accounts {
+			if account.BalanceCell == "" || account.Error != nil {
+				continue
+			}
+			_, err := sheetsService.WriteCell(account.BalanceCell, account.Amount)
+			checkError(err)
+		}
+	}
 }
 
 func dashes(count int) string {
@@ -280,19 +466,44 @@ func printBalances(balances map[string]banking.Balance) {
 	fmt.Printf("    Wells Fargo: $%8.2f\n", balances[banking.WellsFargoID].Amount)
 	fmt.Printf("    Fidelity:    $%8.2f\n", balances[banking.FidelityID].Amount)
 	fmt.Printf("    Chase:       $%8.2f\n", balances[banking.ChaseID].Amount)
+	for _, id := range []string{banking.WellsFargoID, banking.FidelityID, banking.ChaseID} {
+		for _, account := range balances[id].Accounts {
+			if account.Error != nil {
+				fmt.Printf("        %-20s %s\n", account.AccountName, account.Error.Error())
+				continue
+			}
+			fmt.Printf("        %-20s $%8.2f\n", account.AccountName, account.Amount)
+		}
+	}
 }
 
-func getTransactions(client *Client, bankIDs []string) ([]*models.Transaction, error) {
-	//startDate := weeksAgo(16)
-	//endDate := today()
-	startDate := config.StartDate
-	endDate := config.EndDate
+// checkItemErrors carries on after banks whose items failed, printing how to re-link them, and returns true
+// if any did; other errors exit
+func checkItemErrors(err error) bool {
+	saveErr := client.BankClient.SaveItemStatuses()
+	if saveErr != nil {
+		fmt.Println(saveErr.Error())
+	}
 
-	transactions, err := client.BankClient.GetTransactions(bankIDs, startDate, endDate)
-	if err != nil {
-		return nil, err
+	var itemErrors banking.ItemErrors
+	if !errors.As(err, &itemErrors) {
+		checkError(err)
+		return false
 	}
-	return transactions, nil
+	fmt.Println("Warning: continuing without these banks:")
+	for _, e := range itemErrors {
+		fmt.Printf("    %s\n", e.Error())
+		if e.LoginRequired() {
+			fmt.Printf("        re-link: register server, then GET /api/create_link_token?institution=%s&item=%s\n", e.BankID, e.ItemID)
+		}
+	}
+	return true
+}
+
+// getTransactions reads the banks' transactions from Plaid. With banking.ItemErrors the transactions of the
+// banks that were read are returned with them.
+func getTransactions(client *Client, bankIDs []string, w window.Window) ([]*models.Transaction, error) {
+	return client.BankClient.GetTransactions(bankIDs, w.StartDate(), w.EndDate())
 }
 
 func getCSVTransactions(client *csv.Client, bankIDs []string) ([]*models.Transaction, error) {
@@ -303,6 +514,46 @@ func getCSVTransactions(client *csv.Client, bankIDs []string) ([]*models.Transac
 	return transactions, nil
 }
 
+// getFileTransactions reads each bank's OFX/QFX statement if it has one configured, then its QIF file, otherwise its CSV file
+func getFileTransactions(csvClient *csv.Client, ofxClient *ofx.Client, qifClient *qif.Client, bankIDs []string) ([]*models.Transaction, error) {
+	var csvBankIDs, qifBankIDs []string
+	ofxBankIDs := getOFXBankIDs(ofxClient, bankIDs)
+	for _, id := range bankIDs {
+		switch {
+		case ofxClient.HasFile(id):
+		case qifClient.HasFile(id):
+			qifBankIDs = append(qifBankIDs, id)
+		default:
+			csvBankIDs = append(csvBankIDs, id)
+		}
+	}
+
+	transactions, err := getCSVTransactions(csvClient, csvBankIDs)
+	if err != nil {
+		return nil, err
+	}
+	ofxTransactions, err := ofxClient.GetTransactions(ofxBankIDs)
+	if err != nil {
+		return nil, err
+	}
+	qifTransactions, err := qifClient.GetTransactions(qifBankIDs)
+	if err != nil {
+		return nil, err
+	}
+	transactions = append(transactions, ofxTransactions...)
+	return append(transactions, qifTransactions...), nil
+}
+
+func getOFXBankIDs(ofxClient *ofx.Client, bankIDs []string) []string {
+	var ids []string
+	for _, id := range bankIDs {
+		if ofxClient.HasFile(id) {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
 func printTransactions(trans []*models.Transaction) {
 	fmt.Printf("    (%3s) [%-28s] %-12s %-10s %-8s %-30s %s\n", "Num", "Key", "Source", "Date", "Amount", "Name", "Bank Name")
 	for i, t := range trans {
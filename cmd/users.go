@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"register/pkg/api"
+	"register/pkg/models"
+
+	"github.com/spf13/cobra"
+)
+
+var usersCmd = &cobra.Command{
+	Use:   "users",
+	Short: "API server user commands",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var usersAddCmd = &cobra.Command{
+	Use:   "add <username>",
+	Short: "Adds a user, prompting for the password",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		usersAdd(args[0])
+	},
+}
+
+var usersAdminCmd = &cobra.Command{
+	Use:   "admin <username>",
+	Short: "Lets a user change the merchants and columns through the API",
+	Long: `Admin makes the user an admin. Only admins may add, edit, merge or remove merchants and columns
+through the API; every user may read them. --revoke takes the admin away.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		usersAdmin(args[0], !usersRevoke)
+	},
+}
+
+var usersTokenCmd = &cobra.Command{
+	Use:   "token <username> <name>",
+	Short: "Creates an API token for a user",
+	Long: `Token creates a named API token for the user and prints it. Only its hash is stored, so the
+token cannot be shown again. Requests send it as "Authorization: Bearer <token>".`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		usersToken(args[0], args[1])
+	},
+}
+
+var usersItemsCmd = &cobra.Command{
+	Use:   "items <username> [<bank id> <item id>]",
+	Short: "Lists a user's bank items, or gives the user an item",
+	Long: `Items lists the bank items the user's API requests read. Given a bank and item ID from the
+config, eg. one linked before users were added, the item is given to the user. Items linked
+through the server are given to the user who linked them.`,
+	Args: cobra.RangeArgs(1, 3),
+	Run: func(cmd *cobra.Command, args []string) {
+		usersItems(args)
+	},
+}
+
+var (
+	usersAddAdmin bool
+	usersRevoke   bool
+)
+
+func init() {
+	rootCmd.AddCommand(usersCmd)
+	usersCmd.AddCommand(usersAddCmd)
+	usersCmd.AddCommand(usersAdminCmd)
+	usersCmd.AddCommand(usersTokenCmd)
+	usersCmd.AddCommand(usersItemsCmd)
+	usersAddCmd.Flags().BoolVar(&usersAddAdmin, "admin", false, "let the user change the merchants and columns")
+	usersAdminCmd.Flags().BoolVar(&usersRevoke, "revoke", false, "take the admin away")
+}
+
+func usersAdd(username string) {
+	password := readString("Password: ")
+	if len(password) < 12 {
+		checkError(fmt.Errorf("the password must be at least 12 characters"))
+	}
+	hash, err := api.HashPassword(password)
+	checkError(err)
+
+	qHandler := getQueryHandler()
+	checkError(qHandler.MigrateUsers())
+	checkError(qHandler.CreateUser(&models.User{Username: username, PasswordHash: hash, Admin: usersAddAdmin}))
+	fmt.Printf("Added user %s\n", username)
+}
+
+func usersAdmin(username string, admin bool) {
+	qHandler := getQueryHandler()
+	checkError(qHandler.MigrateUsers())
+	checkError(qHandler.SetUserAdmin(username, admin))
+	if admin {
+		fmt.Printf("%s is an admin\n", username)
+	} else {
+		fmt.Printf("%s is not an admin\n", username)
+	}
+}
+
+func usersToken(username, name string) {
+	qHandler := getQueryHandler()
+	user, err := qHandler.GetUser(username)
+	checkError(err)
+
+	token, hash, err := api.NewAPIToken()
+	checkError(err)
+	checkError(qHandler.CreateAPIToken(&models.APIToken{UserID: user.ID, Name: name, TokenHash: hash}))
+	fmt.Printf("API token %s for %s (shown once):\n%s\n", name, username, token)
+}
+
+func usersItems(args []string) {
+	qHandler := getQueryHandler()
+	user, err := qHandler.GetUser(args[0])
+	checkError(err)
+
+	if len(args) == 3 {
+		bankID, itemID := args[1], args[2]
+		bank, ok := config.Banks[bankID]
+		if !ok {
+			checkError(fmt.Errorf("unknown bank %s", bankID))
+		}
+		if !slices.Contains(bankItemIDs(bank), itemID) {
+			checkError(fmt.Errorf("bank %s has no item %s", bankID, itemID))
+		}
+		checkError(qHandler.AddUserItem(&models.UserItem{UserID: user.ID, BankID: bankID, ItemID: itemID}))
+	} else if len(args) != 1 {
+		checkError(fmt.Errorf("give both a bank id and an item id"))
+	}
+
+	items, err := qHandler.GetUserItems(user.ID)
+	checkError(err)
+	for _, i := range items {
+		fmt.Printf("    %-12s %s\n", i.BankID, i.ItemID)
+	}
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"register/api/services/sheets_service"
+
+	"github.com/spf13/cobra"
+)
+
+var yearCmd = &cobra.Command{
+	Use:   "year",
+	Short: "Year end commands",
+	Long:  ``,
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+	},
+}
+
+var yearCloseCmd = &cobra.Command{
+	Use:   "close",
+	Short: "Archives the Register and monthly tabs for a finished year and starts new ones",
+	Long: `Close copies the Register to a "Register <year>" tab, then folds the year's entries in the
+Register into one balance forward row holding their register balances and category totals, so
+the running balances carry into the new year. Entries of later years stay. The MonthlyCategories
+and MonthlyPayees tabs are renamed "<tab> <year>" and new ones are started with the next year's
+entries. Monthly --year aggregates the archived years with the current one.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		closeYear()
+	},
+}
+
+var yearOptions struct {
+	Year int
+	Yes  bool
+}
+
+func init() {
+	rootCmd.AddCommand(yearCmd)
+	yearCmd.AddCommand(yearCloseCmd)
+
+	yearCloseCmd.Flags().IntVar(&yearOptions.Year, "year", time.Now().Year()-1, "the year to close")
+	yearCloseCmd.Flags().BoolVarP(&yearOptions.Yes, "yes", "y", false, "close without asking")
+}
+
+func closeYear() {
+	year := yearOptions.Year
+	if year >= time.Now().Year() {
+		checkError(fmt.Errorf("%d has not finished", year))
+	}
+	if !yearOptions.Yes {
+		answer := readString(fmt.Sprintf("Close %d, archiving its Register and monthly tabs? [y/N] ", year))
+		if !strings.EqualFold(strings.TrimSpace(answer), "y") {
+			return
+		}
+	}
+
+	sheetsProvider, err := newSheetsProvider()
+	checkError(err)
+	sheetsService := sheets_service.New(sheetsProvider)
+	sheetsService.Income = incomeSources()
+	checkError(sheetsService.NewRegisterSheet(config))
+	fmt.Println("Reading Register...")
+	_, err = sheetsService.ReadRegisterSheet()
+	checkError(err)
+
+	cols := getQueryHandler().GetColumns()
+	fmt.Printf("Closing %d...\n", year)
+	closed, err := sheetsService.CloseYear(year, cols)
+	checkError(err)
+	fmt.Printf("Folded %d entries into %s %d\n", closed.Folded, sheets_service.BalanceForwardName, year)
+	for _, tab := range closed.Archived {
+		fmt.Printf("    archived %s\n", tab)
+	}
+
+	catAgg, payeeAgg := aggregateRegisters(sheetsService, cols, []int{year + 1})
+	fmt.Println("Starting monthly tabs...")
+	checkError(sheetsService.UpdateMonthlyCategories(sheets_service.MonthlyCategoriesTabName, catAgg, cols))
+	checkError(sheetsService.UpdateMonthlyPayees(sheets_service.MonthlyPayeesTabName, payeeAgg))
+}
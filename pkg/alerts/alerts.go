@@ -0,0 +1,156 @@
+package alerts
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"register/api/services/sheets_service"
+	"register/pkg/budget"
+	"register/pkg/models"
+)
+
+// Alert rules
+const (
+	RuleCategoryPercent  = "category_percent"
+	RuleLargeTransaction = "large_transaction"
+	RuleNewMerchant      = "new_merchant"
+	RuleDuplicate        = "duplicate"
+)
+
+// Alert is one rule triggered by an update
+type Alert struct {
+	Rule    string `json:"rule"`
+	Title   string `json:"title"`
+	Message string `json:"message"`
+	Key     string `json:"key,omitempty"` // the transaction that triggered it; empty for category alerts
+}
+
+// Input is what the rules are evaluated against
+type Input struct {
+	Budget    []*sheets_service.BudgetEntry
+	Columns   []models.Column
+	History   []models.Transaction  // the transactions stored before the update
+	New       []*models.Transaction // the transactions the update added
+	Merchants map[string]bool       // the merchant names known before the update
+}
+
+// Evaluate returns the alerts the update's new transactions trigger. Purchases are transactions in a
+// category column; category alerts are for the months and categories of the new purchases only, so
+// an update that adds nothing to a category alerts nothing for it.
+func Evaluate(rules models.AlertRules, in Input) []Alert {
+	columns := make(map[int]models.Column)
+	for _, c := range in.Columns {
+		columns[c.ColumnIndex] = c
+	}
+	// a new transaction already stored, eg. by the webhook server, is left out of the history by its bank
+	// transaction ID; keys are not unique, a double charge has the same key as the first
+	newIDs := make(map[string]bool)
+	for _, t := range in.New {
+		if t.TransactionID != "" {
+			newIDs[t.TransactionID] = true
+		}
+	}
+	var all []models.Transaction
+	for _, t := range in.History {
+		if t.TransactionID == "" || !newIDs[t.TransactionID] {
+			all = append(all, t)
+		}
+	}
+	history := len(all)
+	for _, t := range in.New {
+		all = append(all, *t)
+	}
+
+	var alerts []Alert
+	for i, t := range in.New {
+		if !columns[t.ColumnIndex].IsCategory || t.Budget >= 0 {
+			continue
+		}
+		amount := -t.Budget
+		if rules.LargeTransaction > 0 && amount >= rules.LargeTransaction {
+			alerts = append(alerts, Alert{
+				Rule:    RuleLargeTransaction,
+				Title:   fmt.Sprintf("Large purchase: %s", t.Name),
+				Message: fmt.Sprintf("%s %s $%.2f from %s", t.Date, t.Name, amount, t.Source),
+				Key:     t.Key,
+			})
+		}
+		if rules.NewMerchant && !in.Merchants[t.Name] {
+			alerts = append(alerts, Alert{
+				Rule:    RuleNewMerchant,
+				Title:   fmt.Sprintf("New merchant: %s", t.Name),
+				Message: fmt.Sprintf("%s %s (%s) $%.2f from %s", t.Date, t.Name, t.BankName, amount, t.Source),
+				Key:     t.Key,
+			})
+		}
+		if rules.DuplicateDays > 0 {
+			if d, ok := findDuplicate(t, history+i, all, rules.DuplicateDays); ok {
+				alerts = append(alerts, Alert{
+					Rule:    RuleDuplicate,
+					Title:   fmt.Sprintf("Possible duplicate charge: %s", t.Name),
+					Message: fmt.Sprintf("%s %s $%.2f, also charged %s", t.Date, t.Name, amount, d.Date),
+					Key:     t.Key,
+				})
+			}
+		}
+	}
+	if rules.CategoryPercent > 0 {
+		alerts = append(alerts, categoryAlerts(rules.CategoryPercent, in, columns, all)...)
+	}
+	return alerts
+}
+
+// findDuplicate returns another charge of t's amount by t's merchant within days of it; self is t's index in all
+func findDuplicate(t *models.Transaction, self int, all []models.Transaction, days int) (models.Transaction, bool) {
+	date, err := time.Parse(models.RegisterDateFormat, t.Date)
+	if err != nil || t.Name == "" {
+		return models.Transaction{}, false
+	}
+	for i, o := range all {
+		if i == self || o.Name != t.Name || o.Budget != t.Budget {
+			continue
+		}
+		oDate, err := time.Parse(models.RegisterDateFormat, o.Date)
+		if err == nil && math.Abs(date.Sub(oDate).Hours()) <= float64(days*24) {
+			return o, true
+		}
+	}
+	return models.Transaction{}, false
+}
+
+func categoryAlerts(percent float64, in Input, columns map[int]models.Column, all []models.Transaction) []Alert {
+	// the months and categories of the new purchases
+	touched := make(map[string]map[string]bool)
+	var months []time.Time
+	for _, t := range in.New {
+		date, err := time.Parse(models.RegisterDateFormat, t.Date)
+		if err != nil || !columns[t.ColumnIndex].IsCategory {
+			continue
+		}
+		month := date.Format(budget.MonthFormat)
+		if touched[month] == nil {
+			touched[month] = make(map[string]bool)
+			months = append(months, time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC))
+		}
+		touched[month][columns[t.ColumnIndex].Name] = true
+	}
+	sort.Slice(months, func(i, j int) bool { return months[i].Before(months[j]) })
+
+	var alerts []Alert
+	for _, month := range months {
+		report := budget.NewReport(in.Budget, in.Columns, all, month)
+		for _, l := range report.Lines {
+			if !touched[report.Month][l.Category] || l.Budgeted <= 0 || l.Spent < l.Budgeted*percent/100 {
+				continue
+			}
+			alerts = append(alerts, Alert{
+				Rule:    RuleCategoryPercent,
+				Title:   fmt.Sprintf("%s at %.0f%% of budget", l.Category, 100*l.Spent/l.Budgeted),
+				Message: fmt.Sprintf("%s %s: $%.2f spent of $%.2f, $%.2f remaining", report.Month, l.Category, l.Spent, l.Budgeted, l.Remaining),
+			})
+		}
+	}
+	return alerts
+}
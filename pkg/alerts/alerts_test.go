@@ -0,0 +1,105 @@
+package alerts
+
+import (
+	"reflect"
+	"testing"
+
+	"register/api/services/sheets_service"
+	"register/pkg/models"
+)
+
+func TestEvaluate(t *testing.T) {
+	in := func(newTrans ...*models.Transaction) Input {
+		return Input{
+			Budget: []*sheets_service.BudgetEntry{{Category: "Grocery", Monthly: 400}, {Category: "Dining Out", Monthly: 200}},
+			Columns: []models.Column{
+				{Name: "Grocery", ColumnIndex: 12, IsCategory: true},
+				{Name: "Dining Out", ColumnIndex: 11, IsCategory: true},
+				{Name: "Deposit", ColumnIndex: 5},
+			},
+			History: []models.Transaction{
+				{Key: "h1", Date: "09/02/26", Name: "Safeway", ColumnIndex: 12, Budget: -300},
+				{Key: "h2", TransactionID: "t2", Date: "09/04/26", Name: "Netflix", ColumnIndex: 11, Budget: -15.99},
+				{Key: "h3", Date: "08/20/26", Name: "Netflix", ColumnIndex: 11, Budget: -15.99},
+			},
+			New:       newTrans,
+			Merchants: map[string]bool{"Safeway": true, "Netflix": true},
+		}
+	}
+	rules := models.AlertRules{CategoryPercent: 80, LargeTransaction: 250, NewMerchant: true, DuplicateDays: 3}
+
+	tests := []struct {
+		name  string
+		rules models.AlertRules
+		in    Input
+		want  []Alert
+	}{
+		{
+			name:  "Test category over percent",
+			rules: rules,
+			in:    in(&models.Transaction{Key: "n1", Date: "09/10/26", Name: "Safeway", ColumnIndex: 12, Budget: -25}),
+			want: []Alert{{Rule: RuleCategoryPercent, Title: "Grocery at 81% of budget",
+				Message: "2026-09 Grocery: $325.00 spent of $400.00, $75.00 remaining"}},
+		},
+		{
+			name:  "Test category under percent and untouched categories",
+			rules: rules,
+			in:    in(&models.Transaction{Key: "n1", Date: "09/10/26", Name: "Netflix", ColumnIndex: 11, Budget: -9}),
+		},
+		{
+			name:  "Test large transaction from a new merchant",
+			rules: models.AlertRules{LargeTransaction: 250, NewMerchant: true},
+			in:    in(&models.Transaction{Key: "n1", Date: "09/10/26", Name: "Cafe Luxe", BankName: "CAFE LUXE 42", Source: "Chase", ColumnIndex: 11, Budget: -260}),
+			want: []Alert{
+				{Rule: RuleLargeTransaction, Title: "Large purchase: Cafe Luxe", Message: "09/10/26 Cafe Luxe $260.00 from Chase", Key: "n1"},
+				{Rule: RuleNewMerchant, Title: "New merchant: Cafe Luxe", Message: "09/10/26 Cafe Luxe (CAFE LUXE 42) $260.00 from Chase", Key: "n1"},
+			},
+		},
+		{
+			name:  "Test duplicate charge within days",
+			rules: models.AlertRules{DuplicateDays: 3},
+			in:    in(&models.Transaction{Key: "n1", Date: "09/06/26", Name: "Netflix", ColumnIndex: 11, Budget: -15.99}),
+			want: []Alert{{Rule: RuleDuplicate, Title: "Possible duplicate charge: Netflix",
+				Message: "09/06/26 Netflix $15.99, also charged 09/04/26", Key: "n1"}},
+		},
+		{
+			name:  "Test same charge outside days",
+			rules: models.AlertRules{DuplicateDays: 3},
+			in:    in(&models.Transaction{Key: "n1", Date: "09/18/26", Name: "Netflix", ColumnIndex: 11, Budget: -15.99}),
+		},
+		{
+			name:  "Test deposits and non-category columns are not purchases",
+			rules: rules,
+			in:    in(&models.Transaction{Key: "n1", Date: "09/10/26", Name: "Salary", ColumnIndex: 5, Budget: 5000, Deposit: 5000}),
+		},
+		{
+			name:  "Test stored copy of a new transaction is not a duplicate",
+			rules: models.AlertRules{DuplicateDays: 3},
+			in:    in(&models.Transaction{Key: "h2", TransactionID: "t2", Date: "09/04/26", Name: "Netflix", ColumnIndex: 11, Budget: -15.99}),
+		},
+		{
+			name:  "Test same day double charge with the same key",
+			rules: models.AlertRules{DuplicateDays: 3},
+			in:    in(&models.Transaction{Key: "h2", TransactionID: "t9", Date: "09/04/26", Name: "Netflix", ColumnIndex: 11, Budget: -15.99}),
+			want: []Alert{{Rule: RuleDuplicate, Title: "Possible duplicate charge: Netflix",
+				Message: "09/04/26 Netflix $15.99, also charged 09/04/26", Key: "h2"}},
+		},
+		{
+			name:  "Test double charge in one update",
+			rules: models.AlertRules{DuplicateDays: 3},
+			in: in(&models.Transaction{Key: "n1", Date: "09/12/26", Name: "Chipotle", ColumnIndex: 11, Budget: -12.5},
+				&models.Transaction{Key: "n1", Date: "09/12/26", Name: "Chipotle", ColumnIndex: 11, Budget: -12.5}),
+			want: []Alert{
+				{Rule: RuleDuplicate, Title: "Possible duplicate charge: Chipotle", Message: "09/12/26 Chipotle $12.50, also charged 09/12/26", Key: "n1"},
+				{Rule: RuleDuplicate, Title: "Possible duplicate charge: Chipotle", Message: "09/12/26 Chipotle $12.50, also charged 09/12/26", Key: "n1"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Evaluate(tt.rules, tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
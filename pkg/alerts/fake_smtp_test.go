@@ -0,0 +1,91 @@
+package alerts
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+)
+
+// FakeSMTPServer is a local stand-in mail server that accepts every message without TLS or auth and keeps
+// it, for testing an SMTPSink
+type FakeSMTPServer struct {
+	Addr     string
+	listener net.Listener
+	mu       sync.Mutex
+	messages []string
+}
+
+// NewFakeSMTPServer starts a FakeSMTPServer on a free localhost port
+func NewFakeSMTPServer() (*FakeSMTPServer, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &FakeSMTPServer{Addr: l.Addr().String(), listener: l}
+	go s.serve()
+	return s, nil
+}
+
+// Messages returns the data of the messages received
+func (s *FakeSMTPServer) Messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string{}, s.messages...)
+}
+
+// Close stops the server
+func (s *FakeSMTPServer) Close() error {
+	return s.listener.Close()
+}
+
+func (s *FakeSMTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *FakeSMTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 localhost fake SMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			reply("250 localhost")
+		case cmd == "DATA":
+			reply("354 end with <CRLF>.<CRLF>")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(l, "."))
+			}
+			s.mu.Lock()
+			s.messages = append(s.messages, data.String())
+			s.mu.Unlock()
+			reply("250 OK")
+		case cmd == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			// MAIL, RCPT, RSET and NOOP
+			reply("250 OK")
+		}
+	}
+}
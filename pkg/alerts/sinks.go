@@ -0,0 +1,186 @@
+package alerts
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"register/pkg/models"
+)
+
+// Sink delivers alerts
+type Sink interface {
+	Send(alerts []Alert) error
+}
+
+// NewSink returns the sink a sink config describes
+func NewSink(cfg models.AlertSink) (Sink, error) {
+	switch cfg.Type {
+	case models.AlertSinkSMTP:
+		if cfg.Addr == "" || cfg.From == "" || len(cfg.To) == 0 {
+			return nil, errors.New("smtp alert sink needs addr, from and to")
+		}
+		return &SMTPSink{Addr: cfg.Addr, Username: cfg.Username, Password: cfg.Password, From: cfg.From, To: cfg.To}, nil
+	case models.AlertSinkWebhook:
+		if cfg.URL == "" {
+			return nil, errors.New("webhook alert sink needs a url")
+		}
+		return &WebhookSink{URL: cfg.URL, Client: &http.Client{Timeout: 10 * time.Second}}, nil
+	case models.AlertSinkCommand:
+		if len(cfg.Command) == 0 {
+			return nil, errors.New("command alert sink needs a command")
+		}
+		return &CommandSink{Command: cfg.Command}, nil
+	case models.AlertSinkFile:
+		if cfg.File == "" {
+			return nil, errors.New("file alert sink needs a file")
+		}
+		return &FileSink{File: cfg.File}, nil
+	}
+	return nil, fmt.Errorf("unknown alert sink type: %q; use smtp, webhook, command or file", cfg.Type)
+}
+
+// NewSinks returns the sinks of the alert rules
+func NewSinks(rules models.AlertRules) ([]Sink, error) {
+	var sinks []Sink
+	for _, cfg := range rules.Sinks {
+		sink, err := NewSink(cfg)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, sink)
+	}
+	return sinks, nil
+}
+
+// Notify sends the alerts to every sink, carrying on past sinks that fail
+func Notify(sinks []Sink, alerts []Alert) error {
+	if len(alerts) == 0 {
+		return nil
+	}
+	var errs []error
+	for _, sink := range sinks {
+		if err := sink.Send(alerts); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// SMTPSink emails the alerts, one message per update
+type SMTPSink struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+	To       []string
+}
+
+// Send ...
+func (s *SMTPSink) Send(alerts []Alert) error {
+	var auth smtp.Auth
+	if s.Username != "" {
+		host, _, _ := net.SplitHostPort(s.Addr)
+		auth = smtp.PlainAuth("", s.Username, s.Password, host)
+	}
+	subject := alerts[0].Title
+	if len(alerts) > 1 {
+		subject = fmt.Sprintf("%d register alerts", len(alerts))
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n",
+		headerValue(s.From), headerValue(strings.Join(s.To, ", ")), headerValue(subject))
+	for _, a := range alerts {
+		fmt.Fprintf(&msg, "%s\r\n    %s\r\n", a.Title, a.Message)
+	}
+	if err := smtp.SendMail(s.Addr, auth, s.From, s.To, msg.Bytes()); err != nil {
+		return fmt.Errorf("could not email alerts: %s", err.Error())
+	}
+	return nil
+}
+
+// headerValue keeps a value, eg. a merchant name from the bank, on its header line so it cannot add headers
+func headerValue(v string) string {
+	return strings.Join(strings.FieldsFunc(v, func(r rune) bool { return r == '\r' || r == '\n' }), " ")
+}
+
+// WebhookSink posts the alerts as JSON, {"alerts": [...]}
+type WebhookSink struct {
+	URL    string
+	Client *http.Client
+}
+
+// Send ...
+func (s *WebhookSink) Send(alerts []Alert) error {
+	body, err := json.Marshal(struct {
+		Alerts []Alert `json:"alerts"`
+	}{alerts})
+	if err != nil {
+		return err
+	}
+	resp, err := s.Client.Post(s.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("could not post alerts: %s", err.Error())
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("could not post alerts: %s returned %s", s.URL, resp.Status)
+	}
+	return nil
+}
+
+// CommandSink runs a command once per alert with the alert's title and message as its last arguments, eg.
+// notify-send for desktop notifications
+type CommandSink struct {
+	Command []string
+}
+
+// Send ...
+func (s *CommandSink) Send(alerts []Alert) error {
+	for _, a := range alerts {
+		args := append(append([]string{}, s.Command[1:]...), a.Title, a.Message)
+		if out, err := exec.Command(s.Command[0], args...).CombinedOutput(); err != nil {
+			return fmt.Errorf("could not run %s: %s: %s", s.Command[0], err.Error(), strings.TrimSpace(string(out)))
+		}
+	}
+	return nil
+}
+
+// FileSink appends the alerts to a file as JSON lines, with the time they were sent
+type FileSink struct {
+	File string
+	Now  func() time.Time
+}
+
+// Send ...
+func (s *FileSink) Send(alerts []Alert) error {
+	f, err := os.OpenFile(s.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("could not open alerts file: %s", err.Error())
+	}
+	defer f.Close()
+
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	enc := json.NewEncoder(f)
+	for _, a := range alerts {
+		line := struct {
+			Time time.Time `json:"time"`
+			Alert
+		}{now(), a}
+		if err := enc.Encode(line); err != nil {
+			return fmt.Errorf("could not write alerts file: %s", err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package alerts
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"register/pkg/models"
+)
+
+var testAlerts = []Alert{
+	{Rule: RuleLargeTransaction, Title: "Large purchase: Cafe Luxe", Message: "09/10/26 Cafe Luxe $260.00 from Chase", Key: "n1"},
+	{Rule: RuleCategoryPercent, Title: "Grocery at 81% of budget", Message: "2026-09 Grocery: $325.00 spent of $400.00"},
+}
+
+func TestSMTPSink_Send(t *testing.T) {
+	server, err := NewFakeSMTPServer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	sink, err := NewSink(models.AlertSink{Type: models.AlertSinkSMTP, Addr: server.Addr, From: "register@localhost", To: []string{"me@localhost"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Send(testAlerts); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	msgs := server.Messages()
+	if len(msgs) != 1 || !strings.Contains(msgs[0], "Subject: 2 register alerts") || !strings.Contains(msgs[0], "    09/10/26 Cafe Luxe $260.00 from Chase") {
+		t.Errorf("Send() sent %q", msgs)
+	}
+}
+
+func Test_headerValue(t *testing.T) {
+	if got := headerValue("Large purchase: EVIL\r\nBcc: victim@example.com"); got != "Large purchase: EVIL Bcc: victim@example.com" {
+		t.Errorf("headerValue() = %q", got)
+	}
+}
+
+func TestWebhookSink_Send(t *testing.T) {
+	var got struct {
+		Alerts []Alert `json:"alerts"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(models.AlertSink{Type: models.AlertSinkWebhook, URL: server.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Send(testAlerts); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(got.Alerts) != 2 || got.Alerts[0] != testAlerts[0] {
+		t.Errorf("Send() posted %+v", got.Alerts)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	sink, _ = NewSink(models.AlertSink{Type: models.AlertSinkWebhook, URL: failing.URL})
+	if err := sink.Send(testAlerts); err == nil {
+		t.Error("Send() to a failing webhook error = nil")
+	}
+}
+
+func TestCommandSink_Send(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "notify.txt")
+	// a stand-in for notify-send that records its arguments
+	sink, err := NewSink(models.AlertSink{Type: models.AlertSinkCommand, Command: []string{"sh", "-c", `printf '%s|%s\n' "$1" "$2" >> "$0"`, out}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sink.Send(testAlerts); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	got, _ := os.ReadFile(out)
+	want := "Large purchase: Cafe Luxe|09/10/26 Cafe Luxe $260.00 from Chase\n" +
+		"Grocery at 81% of budget|2026-09 Grocery: $325.00 spent of $400.00\n"
+	if string(got) != want {
+		t.Errorf("Send() ran %q, want %q", got, want)
+	}
+
+	sink, _ = NewSink(models.AlertSink{Type: models.AlertSinkCommand, Command: []string{"false"}})
+	if err := sink.Send(testAlerts); err == nil {
+		t.Error("Send() with a failing command error = nil")
+	}
+}
+
+func TestFileSink_Send(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "alerts.jsonl")
+	now := time.Date(2026, time.September, 10, 8, 0, 0, 0, time.UTC)
+	sink := &FileSink{File: file, Now: func() time.Time { return now }}
+	for i := 0; i < 2; i++ {
+		if err := sink.Send(testAlerts[:1]); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	f, _ := os.Open(file)
+	defer f.Close()
+	got, _ := io.ReadAll(f)
+	line := `{"time":"2026-09-10T08:00:00Z","rule":"large_transaction","title":"Large purchase: Cafe Luxe","message":"09/10/26 Cafe Luxe $260.00 from Chase","key":"n1"}` + "\n"
+	if string(got) != line+line {
+		t.Errorf("Send() wrote %q", got)
+	}
+}
+
+func TestNewSink(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     models.AlertSink
+		wantErr bool
+	}{
+		{name: "Test file", cfg: models.AlertSink{Type: models.AlertSinkFile, File: "alerts.jsonl"}},
+		{name: "Test smtp without recipients", cfg: models.AlertSink{Type: models.AlertSinkSMTP, Addr: "localhost:25", From: "a@b"}, wantErr: true},
+		{name: "Test webhook without url", cfg: models.AlertSink{Type: models.AlertSinkWebhook}, wantErr: true},
+		{name: "Test unknown type", cfg: models.AlertSink{Type: "pager"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewSink(tt.cfg); (err != nil) != tt.wantErr {
+				t.Errorf("NewSink() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"net/http"
+
+	"register/api/services/sheets_service"
+	"register/pkg/banking"
+	"register/pkg/models"
+	"register/pkg/window"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/cors"
+)
+
+// Prefix is the path the API is served under
+const Prefix = "/api/v1"
+
+// Store is the database the API reads; handler.Query is one
+type Store interface {
+	GetUser(username string) (*models.User, error)
+	GetUserByTokenHash(hash string) (*models.User, error)
+	GetUserItems(userID uint) ([]models.UserItem, error)
+	AddUserItem(item *models.UserItem) error
+	GetMerchants() []models.Merchant
+	GetMerchant(id int) (*models.Merchant, error)
+	SaveMerchant(m *models.Merchant) error
+	DeleteMerchant(id int) error
+	MergeMerchants(intoID int, fromIDs []int) error
+	GetColumns() []models.Column
+	GetColumn(id int) (*models.Column, error)
+	SaveColumn(col *models.Column) error
+	DeleteColumn(id int) error
+}
+
+// Bank reads the user's items from Plaid; banking.Client is one
+type Bank interface {
+	GetItemTransactions(bankID, itemID, startDate, endDate string) ([]*models.Transaction, error)
+	GetItemBalance(bankID, itemID string) banking.Balance
+}
+
+// BudgetSource returns the budget categories, eg. read from the Budget sheet
+type BudgetSource func() ([]*sheets_service.BudgetEntry, error)
+
+// WindowSource returns the transactions window used when a request gives none, eg. the year to date; it
+// is called for each request so the window moves with the date
+type WindowSource func() window.Window
+
+// ConfigOptions ...
+type ConfigOptions struct {
+	Store   Store
+	Bank    Bank
+	Budgets BudgetSource
+	Window  WindowSource
+}
+
+// Server is the authenticated JSON API
+type Server struct {
+	store   Store
+	bank    Bank
+	budgets BudgetSource
+	window  WindowSource
+}
+
+// New returns a Server
+func New(o ConfigOptions) *Server {
+	return &Server{
+		store:   o.Store,
+		bank:    o.Bank,
+		budgets: o.Budgets,
+		window:  o.Window,
+	}
+}
+
+// Register adds the API routes to r, each requiring an authenticated user. Changing the merchants and
+// columns requires an admin.
+func (s *Server) Register(r *mux.Router) {
+	sr := r.PathPrefix(Prefix).Subrouter()
+	sr.Use(s.Authenticate)
+	sr.HandleFunc("/me", s.getMe).Methods("GET")
+	sr.HandleFunc("/items", s.getItems).Methods("GET")
+	sr.HandleFunc("/transactions", s.getTransactions).Methods("GET")
+	sr.HandleFunc("/balances", s.getBalances).Methods("GET")
+	sr.HandleFunc("/merchants", s.getMerchants).Methods("GET")
+	sr.HandleFunc("/merchants", s.requireAdmin(s.createMerchant)).Methods("POST")
+	sr.HandleFunc("/merchants/{id:[0-9]+}", s.getMerchant).Methods("GET")
+	sr.HandleFunc("/merchants/{id:[0-9]+}", s.requireAdmin(s.updateMerchant)).Methods("PUT")
+	sr.HandleFunc("/merchants/{id:[0-9]+}", s.requireAdmin(s.deleteMerchant)).Methods("DELETE")
+	sr.HandleFunc("/merchants/{id:[0-9]+}/merge", s.requireAdmin(s.mergeMerchants)).Methods("POST")
+	sr.HandleFunc("/columns", s.getColumns).Methods("GET")
+	sr.HandleFunc("/columns", s.requireAdmin(s.createColumn)).Methods("POST")
+	sr.HandleFunc("/columns/{id:[0-9]+}", s.getColumn).Methods("GET")
+	sr.HandleFunc("/columns/{id:[0-9]+}", s.requireAdmin(s.updateColumn)).Methods("PUT")
+	sr.HandleFunc("/columns/{id:[0-9]+}", s.requireAdmin(s.deleteColumn)).Methods("DELETE")
+	sr.HandleFunc("/budgets", s.getBudgets).Methods("GET")
+	sr.NotFoundHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, CodeNotFound, "no such endpoint "+r.URL.Path)
+	})
+	sr.MethodNotAllowedHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusMethodNotAllowed, CodeBadRequest, r.Method+" is not allowed on "+r.URL.Path)
+	})
+}
+
+// CORS allows the API to be called from allowedOrigins only. With none, cross-origin requests are not allowed.
+func CORS(allowedOrigins []string, h http.Handler) http.Handler {
+	if len(allowedOrigins) == 0 {
+		return h
+	}
+	return cors.New(cors.Options{
+		AllowedOrigins: allowedOrigins,
+		AllowedMethods: []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodDelete},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+		MaxAge:         600,
+	}).Handler(h)
+}
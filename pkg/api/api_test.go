@@ -0,0 +1,272 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"register/api/services/sheets_service"
+	"register/pkg/banking"
+	"register/pkg/models"
+	"register/pkg/window"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// fakeStore has users "alice", with the chase item and an API token, and "bob", with the citi item
+type fakeStore struct {
+	users     map[string]*models.User
+	tokens    map[string]*models.User
+	items     map[uint][]models.UserItem
+	merchants []models.Merchant
+	columns   []models.Column
+}
+
+func newFakeStore(t *testing.T) (*fakeStore, string) {
+	hash, err := HashPassword("secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, tokenHash, err := NewAPIToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice := &models.User{Model: gorm.Model{ID: 1}, Username: "alice", PasswordHash: hash}
+	bob := &models.User{Model: gorm.Model{ID: 2}, Username: "bob", PasswordHash: hash}
+	return &fakeStore{
+		users:  map[string]*models.User{"alice": alice, "bob": bob},
+		tokens: map[string]*models.User{tokenHash: alice},
+		items: map[uint][]models.UserItem{
+			1: {{UserID: 1, BankID: "chase", ItemID: "login1"}},
+			2: {{UserID: 2, BankID: "citi", ItemID: "Citi"}},
+		},
+		merchants: []models.Merchant{{ID: 7, BankName: "NETFLIX.COM", Name: "Netflix", ColumnID: 3}},
+		columns:   []models.Column{{ID: 3, Name: "Entertainment", ColumnIndex: 12, Letter: "M", IsCategory: true}},
+	}, token
+}
+
+func (f *fakeStore) GetUser(username string) (*models.User, error) {
+	if u, ok := f.users[username]; ok {
+		return u, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+func (f *fakeStore) GetUserByTokenHash(hash string) (*models.User, error) {
+	if u, ok := f.tokens[hash]; ok {
+		return u, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+func (f *fakeStore) GetUserItems(userID uint) ([]models.UserItem, error) {
+	return f.items[userID], nil
+}
+func (f *fakeStore) AddUserItem(item *models.UserItem) error {
+	f.items[item.UserID] = append(f.items[item.UserID], *item)
+	return nil
+}
+func (f *fakeStore) GetMerchants() []models.Merchant {
+	return f.merchants
+}
+func (f *fakeStore) GetColumns() []models.Column {
+	return f.columns
+}
+
+// fakeBank has one transaction for chase and a login required error for citi
+type fakeBank struct{}
+
+func (fakeBank) GetItemTransactions(bankID, itemID, startDate, endDate string) ([]*models.Transaction, error) {
+	if bankID == "citi" {
+		return nil, banking.ItemErrors{{BankID: bankID, ItemID: itemID, Err: errors.New("ITEM_LOGIN_REQUIRED")}}
+	}
+	return []*models.Transaction{{Key: "chase:01/05/26:12.50", Source: "Chase", Date: "01/05/26", BankName: "NETFLIX", Amount: 12.5}}, nil
+}
+func (fakeBank) GetItemBalance(bankID, itemID string) banking.Balance {
+	return banking.Balance{BankName: "Chase", Amount: 100}
+}
+
+func newTestServer(t *testing.T, origins []string) (*httptest.Server, string) {
+	store, token := newFakeStore(t)
+	s := New(ConfigOptions{
+		Store: store,
+		Bank:  fakeBank{},
+		Window: func() window.Window {
+			return window.Window{Start: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2026, time.January, 31, 0, 0, 0, 0, time.UTC)}
+		},
+		Budgets: func() ([]*sheets_service.BudgetEntry, error) {
+			return []*sheets_service.BudgetEntry{{Category: "Groceries", Monthly: 800}}, nil
+		},
+	})
+	r := mux.NewRouter()
+	s.Register(r)
+	return httptest.NewServer(CORS(origins, r)), token
+}
+
+func TestServer(t *testing.T) {
+	ts, token := newTestServer(t, nil)
+	defer ts.Close()
+
+	tests := []struct {
+		name       string
+		path       string
+		auth       func(r *http.Request)
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Test no credentials",
+			path:       "/api/v1/me",
+			auth:       func(r *http.Request) {},
+			wantStatus: http.StatusUnauthorized,
+			wantBody:   `{"error":{"code":"unauthorized","message":"an API token or username and password is required"}}`,
+		},
+		{
+			name:       "Test wrong password",
+			path:       "/api/v1/me",
+			auth:       func(r *http.Request) { r.SetBasicAuth("alice", "wrong") },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "Test unknown API token",
+			path:       "/api/v1/me",
+			auth:       func(r *http.Request) { r.Header.Set("Authorization", "Bearer reg_unknown") },
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "Test API token",
+			path:       "/api/v1/me",
+			auth:       func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+token) },
+			wantStatus: http.StatusOK,
+			wantBody:   `{"id":1,"username":"alice","items":[{"bankID":"chase","itemID":"login1"}]}`,
+		},
+		{
+			name:       "Test transactions of the user's items",
+			path:       "/api/v1/transactions",
+			auth:       func(r *http.Request) { r.SetBasicAuth("alice", "secret") },
+			wantStatus: http.StatusOK,
+			wantBody: `{"transactions":[{"key":"chase:01/05/26:12.50","source":"Chase","date":"01/05/26","name":"",` +
+				`"bankName":"NETFLIX","amount":12.5}],"itemErrors":[]}`,
+		},
+		{
+			name:       "Test transactions of an item that needs re-linking",
+			path:       "/api/v1/transactions?start=2026-01-01&end=2026-01-15",
+			auth:       func(r *http.Request) { r.SetBasicAuth("bob", "secret") },
+			wantStatus: http.StatusOK,
+			wantBody: `{"transactions":[],"itemErrors":[{"bankID":"citi","itemID":"Citi","message":"ITEM_LOGIN_REQUIRED",` +
+				`"loginRequired":false}]}`,
+		},
+		{
+			name:       "Test transactions bad date",
+			path:       "/api/v1/transactions?start=01/01/26",
+			auth:       func(r *http.Request) { r.SetBasicAuth("alice", "secret") },
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":{"code":"bad_request","message":"start and end must be dates, YYYY-MM-DD: 01/01/26"}}`,
+		},
+		{
+			name:       "Test balances",
+			path:       "/api/v1/balances",
+			auth:       func(r *http.Request) { r.SetBasicAuth("alice", "secret") },
+			wantStatus: http.StatusOK,
+			wantBody:   `[{"bankID":"chase","itemID":"login1","bankName":"Chase","amount":100}]`,
+		},
+		{
+			name:       "Test merchants",
+			path:       "/api/v1/merchants",
+			auth:       func(r *http.Request) { r.SetBasicAuth("alice", "secret") },
+			wantStatus: http.StatusOK,
+			wantBody:   `[{"id":7,"bankName":"NETFLIX.COM","name":"Netflix","columnID":3,"taxDeductible":false}]`,
+		},
+		{
+			name:       "Test budgets",
+			path:       "/api/v1/budgets",
+			auth:       func(r *http.Request) { r.SetBasicAuth("alice", "secret") },
+			wantStatus: http.StatusOK,
+			wantBody:   `[{"category":"Groceries","weekly":0,"every2Weeks":0,"twiceMonthly":0,"monthly":800,"yearly":0}]`,
+		},
+		{
+			name:       "Test unknown endpoint",
+			path:       "/api/v1/accounts",
+			auth:       func(r *http.Request) { r.SetBasicAuth("alice", "secret") },
+			wantStatus: http.StatusNotFound,
+			wantBody:   `{"error":{"code":"not_found","message":"no such endpoint /api/v1/accounts"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, ts.URL+tt.path, nil)
+			tt.auth(req)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", ct)
+			}
+			var body json.RawMessage
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatalf("response is not JSON: %v", err)
+			}
+			if tt.wantBody != "" && string(body) != tt.wantBody {
+				t.Errorf("body = %s, want %s", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestCORS(t *testing.T) {
+	tests := []struct {
+		name    string
+		origins []string
+		origin  string
+		want    string
+	}{
+		{
+			name:    "Test allowed origin",
+			origins: []string{"https://register.example.com"},
+			origin:  "https://register.example.com",
+			want:    "https://register.example.com",
+		},
+		{
+			name:    "Test other origin",
+			origins: []string{"https://register.example.com"},
+			origin:  "https://evil.example.com",
+			want:    "",
+		},
+		{
+			name:   "Test no origins configured",
+			origin: "https://register.example.com",
+			want:   "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ts, _ := newTestServer(t, tt.origins)
+			defer ts.Close()
+
+			req, _ := http.NewRequest(http.MethodOptions, ts.URL+"/api/v1/me", nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Access-Control-Request-Method", "GET")
+			req.Header.Set("Access-Control-Request-Headers", "Authorization")
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if got := resp.Header.Get("Access-Control-Allow-Origin"); got != tt.want {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.want)
+			}
+			if tt.want != "" && !strings.Contains(resp.Header.Get("Access-Control-Allow-Headers"), "Authorization") {
+				t.Errorf("Access-Control-Allow-Headers = %q", resp.Header.Get("Access-Control-Allow-Headers"))
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"register/pkg/models"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TokenPrefix starts every API token, so a leaked one is easy to recognize
+const TokenPrefix = "reg_"
+
+type contextKey int
+
+const userKey contextKey = iota
+
+// HashPassword returns the bcrypt hash stored for a password
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	return string(hash), err
+}
+
+// NewAPIToken returns a random API token, shown to the user once, and the hash that is stored
+func NewAPIToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	token = TokenPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return token, HashToken(token), nil
+}
+
+// HashToken returns the SHA-256 of an API token
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// UserFromContext returns the user authenticated by Authenticate
+func UserFromContext(ctx context.Context) *models.User {
+	user, _ := ctx.Value(userKey).(*models.User)
+	return user
+}
+
+// Authenticate requires an API token, "Authorization: Bearer <token>", or the user's password by basic auth.
+// The user is put in the request context.
+func (s *Server) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user := s.authenticate(r)
+		if user == nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="register", Basic realm="register"`)
+			writeError(w, http.StatusUnauthorized, CodeUnauthorized, "an API token or username and password is required")
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey, user)))
+	})
+}
+
+// requireAdmin allows the authenticated user to call next only if the user is an admin
+func (s *Server) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if user := UserFromContext(r.Context()); user == nil || !user.Admin {
+			writeError(w, http.StatusForbidden, CodeForbidden, "changing merchants and columns requires an admin")
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (s *Server) authenticate(r *http.Request) *models.User {
+	if username, password, ok := r.BasicAuth(); ok {
+		user, err := s.store.GetUser(username)
+		if err != nil || bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil {
+			return nil
+		}
+		return user
+	}
+
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || !strings.HasPrefix(token, TokenPrefix) {
+		return nil
+	}
+	user, err := s.store.GetUserByTokenHash(HashToken(token))
+	if err != nil {
+		return nil
+	}
+	return user
+}
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"register/pkg/models"
+	"register/pkg/repository"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+// Merge names the merchants merged into another
+type Merge struct {
+	From []int `json:"from"`
+}
+
+// storeError writes the error response for an error of the merchants and columns store
+func storeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		writeError(w, http.StatusNotFound, CodeNotFound, err.Error())
+	case errors.Is(err, repository.ErrUnknownColumn):
+		writeError(w, http.StatusBadRequest, CodeBadRequest, err.Error())
+	case errors.Is(err, repository.ErrColumnInUse):
+		writeError(w, http.StatusConflict, CodeConflict, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, CodeInternal, err.Error())
+	}
+}
+
+// readJSON decodes the request body into v, writing the error response if it cannot
+func readJSON(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, CodeBadRequest, "invalid JSON body: "+err.Error())
+		return false
+	}
+	return true
+}
+
+func pathID(r *http.Request) int {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	return id
+}
+
+func validMerchant(w http.ResponseWriter, m Merchant) bool {
+	if m.BankName == "" || m.Name == "" || m.ColumnID <= 0 {
+		writeError(w, http.StatusBadRequest, CodeBadRequest, "bankName, name and columnID are required")
+		return false
+	}
+	return true
+}
+
+func (s *Server) getMerchant(w http.ResponseWriter, r *http.Request) {
+	m, err := s.store.GetMerchant(pathID(r))
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toMerchant(*m))
+}
+
+func (s *Server) createMerchant(w http.ResponseWriter, r *http.Request) {
+	var in Merchant
+	if !readJSON(w, r, &in) || !validMerchant(w, in) {
+		return
+	}
+	m := &models.Merchant{BankName: in.BankName, Name: in.Name, ColumnID: in.ColumnID, TaxDeductible: in.TaxDeductible}
+	if err := s.store.SaveMerchant(m); err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, toMerchant(*m))
+}
+
+func (s *Server) updateMerchant(w http.ResponseWriter, r *http.Request) {
+	var in Merchant
+	if !readJSON(w, r, &in) || !validMerchant(w, in) {
+		return
+	}
+	m := &models.Merchant{ID: pathID(r), BankName: in.BankName, Name: in.Name, ColumnID: in.ColumnID, TaxDeductible: in.TaxDeductible}
+	if err := s.store.SaveMerchant(m); err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toMerchant(*m))
+}
+
+func (s *Server) deleteMerchant(w http.ResponseWriter, r *http.Request) {
+	if err := s.store.DeleteMerchant(pathID(r)); err != nil {
+		storeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// mergeMerchants maps the bank names of the merchants in the body to the merchant of the path
+func (s *Server) mergeMerchants(w http.ResponseWriter, r *http.Request) {
+	var in Merge
+	if !readJSON(w, r, &in) {
+		return
+	}
+	if len(in.From) == 0 {
+		writeError(w, http.StatusBadRequest, CodeBadRequest, "from must list the merchants to merge")
+		return
+	}
+	if err := s.store.MergeMerchants(pathID(r), in.From); err != nil {
+		storeError(w, err)
+		return
+	}
+	s.getMerchants(w, r)
+}
+
+func (s *Server) getColumn(w http.ResponseWriter, r *http.Request) {
+	c, err := s.store.GetColumn(pathID(r))
+	if err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, toColumn(*c))
+}
+
+func (s *Server) saveColumn(w http.ResponseWriter, r *http.Request, id int, status int) {
+	var in Column
+	if !readJSON(w, r, &in) {
+		return
+	}
+	if in.Name == "" {
+		writeError(w, http.StatusBadRequest, CodeBadRequest, "name is required")
+		return
+	}
+	c := &models.Column{ID: id, Name: in.Name, Color: in.Color, ColumnIndex: in.ColumnIndex, Letter: in.Letter, IsCategory: in.IsCategory}
+	if err := s.store.SaveColumn(c); err != nil {
+		storeError(w, err)
+		return
+	}
+	writeJSON(w, status, toColumn(*c))
+}
+
+func (s *Server) createColumn(w http.ResponseWriter, r *http.Request) {
+	s.saveColumn(w, r, 0, http.StatusCreated)
+}
+
+func (s *Server) updateColumn(w http.ResponseWriter, r *http.Request) {
+	s.saveColumn(w, r, pathID(r), http.StatusOK)
+}
+
+func (s *Server) deleteColumn(w http.ResponseWriter, r *http.Request) {
+	if err := s.store.DeleteColumn(pathID(r)); err != nil {
+		storeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,261 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"register/pkg/models"
+	"register/pkg/repository"
+
+	"github.com/gorilla/mux"
+	"gorm.io/gorm"
+)
+
+func (f *fakeStore) GetMerchant(id int) (*models.Merchant, error) {
+	for _, m := range f.merchants {
+		if m.ID == id {
+			return &m, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+func (f *fakeStore) SaveMerchant(m *models.Merchant) error {
+	if _, err := f.GetColumn(m.ColumnID); err != nil {
+		return repository.ErrUnknownColumn
+	}
+	if m.ID == 0 {
+		m.ID = len(f.merchants) + 100
+		f.merchants = append(f.merchants, *m)
+		return nil
+	}
+	for i := range f.merchants {
+		if f.merchants[i].ID == m.ID {
+			f.merchants[i] = *m
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+func (f *fakeStore) DeleteMerchant(id int) error {
+	n := len(f.merchants)
+	f.merchants = slices.DeleteFunc(f.merchants, func(m models.Merchant) bool { return m.ID == id })
+	if len(f.merchants) == n {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+func (f *fakeStore) MergeMerchants(intoID int, fromIDs []int) error {
+	into, err := f.GetMerchant(intoID)
+	if err != nil {
+		return err
+	}
+	for _, id := range fromIDs {
+		from, err := f.GetMerchant(id)
+		if err != nil {
+			return err
+		}
+		for i := range f.merchants {
+			if f.merchants[i].Name == from.Name {
+				f.merchants[i].Name, f.merchants[i].ColumnID = into.Name, into.ColumnID
+			}
+		}
+	}
+	return nil
+}
+func (f *fakeStore) GetColumn(id int) (*models.Column, error) {
+	for _, c := range f.columns {
+		if c.ID == id {
+			return &c, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+func (f *fakeStore) SaveColumn(col *models.Column) error {
+	if col.ID == 0 {
+		col.ID = len(f.columns) + 100
+		f.columns = append(f.columns, *col)
+		return nil
+	}
+	for i := range f.columns {
+		if f.columns[i].ID == col.ID {
+			f.columns[i] = *col
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+func (f *fakeStore) DeleteColumn(id int) error {
+	for _, m := range f.merchants {
+		if m.ColumnID == id {
+			return repository.ErrColumnInUse
+		}
+	}
+	n := len(f.columns)
+	f.columns = slices.DeleteFunc(f.columns, func(c models.Column) bool { return c.ID == id })
+	if len(f.columns) == n {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func TestServer_catalog(t *testing.T) {
+	store, token := newFakeStore(t)
+	store.merchants = append(store.merchants,
+		models.Merchant{ID: 8, BankName: "NETFLIX INC", Name: "Netflix Inc", ColumnID: 4},
+		models.Merchant{ID: 9, BankName: "SAFEWAY", Name: "Safeway", ColumnID: 4},
+	)
+	store.columns = append(store.columns, models.Column{ID: 4, Name: "Groceries"})
+	store.users["alice"].Admin = true
+	r := mux.NewRouter()
+	New(ConfigOptions{Store: store}).Register(r)
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	// each step runs on the store left by the steps before it
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		username   string // signs in by password rather than alice's token
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "Test add merchant not admin",
+			method:     http.MethodPost,
+			path:       "/api/v1/merchants",
+			body:       `{"bankName":"HULU","name":"Hulu","columnID":3}`,
+			username:   "bob",
+			wantStatus: http.StatusForbidden,
+			wantBody:   `{"error":{"code":"forbidden","message":"changing merchants and columns requires an admin"}}`,
+		},
+		{
+			name:       "Test remove column not admin",
+			method:     http.MethodDelete,
+			path:       "/api/v1/columns/4",
+			username:   "bob",
+			wantStatus: http.StatusForbidden,
+		},
+		{
+			name:       "Test get merchant not admin",
+			method:     http.MethodGet,
+			path:       "/api/v1/merchants/7",
+			username:   "bob",
+			wantStatus: http.StatusOK,
+			wantBody:   `{"id":7,"bankName":"NETFLIX.COM","name":"Netflix","columnID":3,"taxDeductible":false}`,
+		},
+		{
+			name:       "Test add merchant",
+			method:     http.MethodPost,
+			path:       "/api/v1/merchants",
+			body:       `{"bankName":"HULU","name":"Hulu","columnID":3}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"id":103,"bankName":"HULU","name":"Hulu","columnID":3,"taxDeductible":false}`,
+		},
+		{
+			name:       "Test add merchant unknown column",
+			method:     http.MethodPost,
+			path:       "/api/v1/merchants",
+			body:       `{"bankName":"HULU","name":"Hulu","columnID":99}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":{"code":"bad_request","message":"no such column"}}`,
+		},
+		{
+			name:       "Test add merchant missing name",
+			method:     http.MethodPost,
+			path:       "/api/v1/merchants",
+			body:       `{"bankName":"HULU","columnID":3}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":{"code":"bad_request","message":"bankName, name and columnID are required"}}`,
+		},
+		{
+			name:       "Test edit merchant",
+			method:     http.MethodPut,
+			path:       "/api/v1/merchants/9",
+			body:       `{"bankName":"SAFEWAY #123","name":"Safeway","columnID":4}`,
+			wantStatus: http.StatusOK,
+			wantBody:   `{"id":9,"bankName":"SAFEWAY #123","name":"Safeway","columnID":4,"taxDeductible":false}`,
+		},
+		{
+			name:       "Test edit missing merchant",
+			method:     http.MethodPut,
+			path:       "/api/v1/merchants/50",
+			body:       `{"bankName":"X","name":"X","columnID":4}`,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name:       "Test merge merchants",
+			method:     http.MethodPost,
+			path:       "/api/v1/merchants/7/merge",
+			body:       `{"from":[8]}`,
+			wantStatus: http.StatusOK,
+			wantBody: `[{"id":7,"bankName":"NETFLIX.COM","name":"Netflix","columnID":3,"taxDeductible":false},` +
+				`{"id":8,"bankName":"NETFLIX INC","name":"Netflix","columnID":3,"taxDeductible":false},` +
+				`{"id":9,"bankName":"SAFEWAY #123","name":"Safeway","columnID":4,"taxDeductible":false},` +
+				`{"id":103,"bankName":"HULU","name":"Hulu","columnID":3,"taxDeductible":false}]`,
+		},
+		{
+			name:       "Test remove column in use",
+			method:     http.MethodDelete,
+			path:       "/api/v1/columns/4",
+			wantStatus: http.StatusConflict,
+			wantBody:   `{"error":{"code":"conflict","message":"column has merchants mapped to it"}}`,
+		},
+		{
+			name:       "Test remove merchant",
+			method:     http.MethodDelete,
+			path:       "/api/v1/merchants/9",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Test remove column",
+			method:     http.MethodDelete,
+			path:       "/api/v1/columns/4",
+			wantStatus: http.StatusNoContent,
+		},
+		{
+			name:       "Test add column",
+			method:     http.MethodPost,
+			path:       "/api/v1/columns",
+			body:       `{"name":"Travel","color":"blue","columnIndex":20,"letter":"U","isCategory":true}`,
+			wantStatus: http.StatusCreated,
+			wantBody:   `{"id":101,"name":"Travel","color":"blue","columnIndex":20,"letter":"U","isCategory":true}`,
+		},
+		{
+			name:       "Test unknown field",
+			method:     http.MethodPut,
+			path:       "/api/v1/columns/3",
+			body:       `{"name":"Fun","colour":"red"}`,
+			wantStatus: http.StatusBadRequest,
+			wantBody:   `{"error":{"code":"bad_request","message":"invalid JSON body: json: unknown field \"colour\""}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, ts.URL+tt.path, strings.NewReader(tt.body))
+			if tt.username != "" {
+				req.SetBasicAuth(tt.username, "secret")
+			} else {
+				req.Header.Set("Authorization", "Bearer "+token)
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if got := strings.TrimSpace(string(body)); tt.wantBody != "" && got != tt.wantBody {
+				t.Errorf("body = %s, want %s", got, tt.wantBody)
+			}
+		})
+	}
+}
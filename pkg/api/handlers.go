@@ -0,0 +1,246 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"register/pkg/banking"
+	"register/pkg/models"
+)
+
+const dateFormat = "2006-01-02"
+
+// Item is one of the user's bank items
+type Item struct {
+	BankID string `json:"bankID"`
+	ItemID string `json:"itemID"`
+}
+
+// Me is the authenticated user
+type Me struct {
+	ID       uint   `json:"id"`
+	Username string `json:"username"`
+	Items    []Item `json:"items"`
+}
+
+// Transaction ...
+type Transaction struct {
+	Key       string  `json:"key"`
+	Source    string  `json:"source"`
+	Date      string  `json:"date"`
+	Name      string  `json:"name"`
+	BankName  string  `json:"bankName"`
+	Amount    float64 `json:"amount"` // Plaid's sign: positive is money out
+	AccountID string  `json:"accountID,omitempty"`
+	Note      string  `json:"note,omitempty"`
+}
+
+// ItemError is an item that could not be read
+type ItemError struct {
+	BankID        string `json:"bankID"`
+	ItemID        string `json:"itemID"`
+	Message       string `json:"message"`
+	LoginRequired bool   `json:"loginRequired"`
+}
+
+// Transactions are the transactions of the user's healthy items and the errors of the others
+type Transactions struct {
+	Transactions []Transaction `json:"transactions"`
+	ItemErrors   []ItemError   `json:"itemErrors"`
+}
+
+// Balance is the balance of an item, that of its first register account, and of each of its accounts
+type Balance struct {
+	BankID    string    `json:"bankID,omitempty"`
+	ItemID    string    `json:"itemID,omitempty"`
+	BankName  string    `json:"bankName,omitempty"`
+	AccountID string    `json:"accountID,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	Amount    float64   `json:"amount"`
+	Error     string    `json:"error,omitempty"`
+	Accounts  []Balance `json:"accounts,omitempty"`
+}
+
+// Merchant maps a bank's transaction name to a register name and column
+type Merchant struct {
+	ID            int    `json:"id"`
+	BankName      string `json:"bankName"`
+	Name          string `json:"name"`
+	ColumnID      int    `json:"columnID"`
+	TaxDeductible bool   `json:"taxDeductible"`
+}
+
+// Column is a register column
+type Column struct {
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Color       string `json:"color"`
+	ColumnIndex int    `json:"columnIndex"`
+	Letter      string `json:"letter"`
+	IsCategory  bool   `json:"isCategory"`
+}
+
+// Budget is a budget category's amounts
+type Budget struct {
+	Category     string  `json:"category"`
+	Weekly       float64 `json:"weekly"`
+	Every2Weeks  float64 `json:"every2Weeks"`
+	TwiceMonthly float64 `json:"twiceMonthly"`
+	Monthly      float64 `json:"monthly"`
+	Yearly       float64 `json:"yearly"`
+}
+
+func (s *Server) userItems(w http.ResponseWriter, r *http.Request) ([]models.UserItem, bool) {
+	items, err := s.store.GetUserItems(UserFromContext(r.Context()).ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, CodeInternal, "could not read the user's items: "+err.Error())
+		return nil, false
+	}
+	return items, true
+}
+
+func (s *Server) getMe(w http.ResponseWriter, r *http.Request) {
+	user := UserFromContext(r.Context())
+	items, ok := s.userItems(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, Me{ID: user.ID, Username: user.Username, Items: toItems(items)})
+}
+
+func (s *Server) getItems(w http.ResponseWriter, r *http.Request) {
+	items, ok := s.userItems(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, toItems(items))
+}
+
+// getTransactions reads the transactions of the user's items, ?start=YYYY-MM-DD&end=YYYY-MM-DD or the
+// configured window
+func (s *Server) getTransactions(w http.ResponseWriter, r *http.Request) {
+	start, end := r.URL.Query().Get("start"), r.URL.Query().Get("end")
+	if start == "" || end == "" {
+		win := s.window()
+		if start == "" {
+			start = win.StartDate()
+		}
+		if end == "" {
+			end = win.EndDate()
+		}
+	}
+	for _, d := range []string{start, end} {
+		if _, err := time.Parse(dateFormat, d); err != nil {
+			writeError(w, http.StatusBadRequest, CodeBadRequest, "start and end must be dates, YYYY-MM-DD: "+d)
+			return
+		}
+	}
+	items, ok := s.userItems(w, r)
+	if !ok {
+		return
+	}
+
+	resp := Transactions{Transactions: []Transaction{}, ItemErrors: []ItemError{}}
+	for _, item := range items {
+		trans, err := s.bank.GetItemTransactions(item.BankID, item.ItemID, start, end)
+		var itemErrors banking.ItemErrors
+		if errors.As(err, &itemErrors) {
+			for _, e := range itemErrors {
+				resp.ItemErrors = append(resp.ItemErrors, ItemError{
+					BankID: e.BankID, ItemID: e.ItemID, Message: e.Err.Error(), LoginRequired: e.LoginRequired(),
+				})
+			}
+		} else if err != nil {
+			resp.ItemErrors = append(resp.ItemErrors, ItemError{BankID: item.BankID, ItemID: item.ItemID, Message: err.Error()})
+		}
+		for _, t := range trans {
+			resp.Transactions = append(resp.Transactions, Transaction{
+				Key: t.Key, Source: t.Source, Date: t.Date, Name: t.Name, BankName: t.BankName,
+				Amount: t.Amount, AccountID: t.AccountID, Note: t.Note,
+			})
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) getBalances(w http.ResponseWriter, r *http.Request) {
+	items, ok := s.userItems(w, r)
+	if !ok {
+		return
+	}
+	balances := make([]Balance, 0, len(items))
+	for _, item := range items {
+		b := toBalance(s.bank.GetItemBalance(item.BankID, item.ItemID))
+		b.BankID, b.ItemID = item.BankID, item.ItemID
+		balances = append(balances, b)
+	}
+	writeJSON(w, http.StatusOK, balances)
+}
+
+func (s *Server) getMerchants(w http.ResponseWriter, r *http.Request) {
+	merchants := s.store.GetMerchants()
+	resp := make([]Merchant, 0, len(merchants))
+	for _, m := range merchants {
+		resp = append(resp, toMerchant(m))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) getColumns(w http.ResponseWriter, r *http.Request) {
+	columns := s.store.GetColumns()
+	resp := make([]Column, 0, len(columns))
+	for _, c := range columns {
+		resp = append(resp, toColumn(c))
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) getBudgets(w http.ResponseWriter, r *http.Request) {
+	if s.budgets == nil {
+		writeError(w, http.StatusServiceUnavailable, CodeUnavailable, "no budget is configured")
+		return
+	}
+	entries, err := s.budgets()
+	if err != nil {
+		writeError(w, http.StatusBadGateway, CodeUnavailable, "could not read the budget: "+err.Error())
+		return
+	}
+	resp := make([]Budget, 0, len(entries))
+	for _, e := range entries {
+		resp = append(resp, Budget{
+			Category: e.Category, Weekly: e.Weekly, Every2Weeks: e.Every2Weeks,
+			TwiceMonthly: e.TwiceMonthly, Monthly: e.Monthly, Yearly: e.Yearly,
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func toItems(items []models.UserItem) []Item {
+	resp := make([]Item, 0, len(items))
+	for _, i := range items {
+		resp = append(resp, Item{BankID: i.BankID, ItemID: i.ItemID})
+	}
+	return resp
+}
+
+func toBalance(b banking.Balance) Balance {
+	resp := Balance{BankName: b.BankName, AccountID: b.AccountID, Name: b.AccountName, Amount: b.Amount}
+	if b.Error != nil {
+		resp.Error = b.Error.Error()
+	}
+	for _, a := range b.Accounts {
+		ab := toBalance(a)
+		ab.BankName = ""
+		resp.Accounts = append(resp.Accounts, ab)
+	}
+	return resp
+}
+
+func toMerchant(m models.Merchant) Merchant {
+	return Merchant{ID: m.ID, BankName: m.BankName, Name: m.Name, ColumnID: m.ColumnID, TaxDeductible: m.TaxDeductible}
+}
+
+func toColumn(c models.Column) Column {
+	return Column{ID: c.ID, Name: c.Name, Color: c.Color, ColumnIndex: c.ColumnIndex, Letter: c.Letter, IsCategory: c.IsCategory}
+}
@@ -0,0 +1,148 @@
+package banking
+
+import (
+	"fmt"
+
+	"github.com/plaid/plaid-go/v15/plaid"
+	"golang.org/x/net/context"
+	"register/pkg/config"
+	"register/pkg/models"
+)
+
+// accessToken reads the token stored for a bank's item; a bank without items is stored under its Source
+func (c *Client) accessToken(bankID, itemID string) (string, error) {
+	if c.Tokens == nil {
+		return "", fmt.Errorf("no token store for %s item %s", bankID, itemID)
+	}
+	return c.Tokens.Get(bankID, itemID)
+}
+
+// getItemTransactions reads the transactions of each item's register accounts, tagged by account
+func (c *Client) getItemTransactions(bank config.Bank, startDate, endDate string) ([]*models.Transaction, ItemErrors) {
+	var transactions []*models.Transaction
+	var itemErrors ItemErrors
+	for _, item := range bank.Items {
+		accounts := registerAccounts(item)
+		if len(accounts) == 0 {
+			continue
+		}
+		accessToken, err := c.accessToken(bank.ID, item.ID)
+		if err != nil {
+			itemErrors = append(itemErrors, c.itemFailed(bank.ID, item.ID, err))
+			continue
+		}
+
+		accountIDs := make([]string, 0, len(accounts))
+		for id := range accounts {
+			accountIDs = append(accountIDs, id)
+		}
+		transResp, err := c.getPlaidTransactions(accessToken, accountIDs, startDate, endDate)
+		if err != nil {
+			itemErrors = append(itemErrors, c.itemFailed(bank.ID, item.ID, err))
+			continue
+		}
+		c.itemSucceeded(bank.ID, item.ID)
+		for _, trans := range transResp {
+			if account, ok := accounts[trans.AccountId]; ok {
+				transactions = append(transactions, buildAccountTransaction(account, trans))
+			}
+		}
+	}
+	return transactions, itemErrors
+}
+
+// getItemBalances reads the balance of every account of the bank's items. The bank's balance is that of its
+// first register account.
+func (c *Client) getItemBalances(bank config.Bank, ctx context.Context) Balance {
+	balance := Balance{BankName: bank.Name, Error: fmt.Errorf("%s has no register account", bank.Name)}
+	for _, item := range bank.Items {
+		var plaidAccounts []plaid.AccountBase
+		accessToken, err := c.accessToken(bank.ID, item.ID)
+		if err == nil {
+			accountIDs := make([]string, 0, len(item.Accounts))
+			for _, a := range item.Accounts {
+				accountIDs = append(accountIDs, a.ID)
+			}
+			plaidAccounts, err = c.getPlaidBalances(accessToken, accountIDs, ctx)
+		}
+		if err != nil {
+			err = fmt.Errorf("error with %s item %s\n%s", bank.ID, item.ID, err.Error())
+		}
+
+		for _, a := range item.Accounts {
+			ab := accountBalance(bank.Name, a, plaidAccounts, err)
+			balance.Accounts = append(balance.Accounts, ab)
+			if a.Register && balance.AccountID == "" {
+				balance.AccountID, balance.AccountName = ab.AccountID, ab.AccountName
+				balance.Amount, balance.Error = ab.Amount, ab.Error
+			}
+		}
+	}
+	return balance
+}
+
+func accountBalance(bankName string, a models.Account, plaidAccounts []plaid.AccountBase, err error) Balance {
+	b := Balance{
+		BankName:    bankName,
+		AccountID:   a.ID,
+		AccountName: a.Name,
+		BalanceCell: a.BalanceCell,
+		Error:       err,
+	}
+	if err != nil {
+		return b
+	}
+	b.Error = fmt.Errorf("account %s (%s) not returned by Plaid", a.Name, a.ID)
+	for _, p := range plaidAccounts {
+		if p.AccountId == a.ID {
+			amount, err := currentBalance(p)
+			if err == nil {
+				b.Amount = *amount
+			}
+			b.Error = err
+		}
+	}
+	return b
+}
+
+// registerAccounts returns the item's accounts that feed the register, by account ID
+func registerAccounts(item models.Item) map[string]models.Account {
+	accounts := make(map[string]models.Account)
+	for _, a := range item.Accounts {
+		if a.Register {
+			accounts[a.ID] = a
+		}
+	}
+	return accounts
+}
+
+// buildAccountTransaction writes the transaction to the register columns configured for its account
+func buildAccountTransaction(account models.Account, p plaid.Transaction) *models.Transaction {
+	var tran *models.Transaction
+	if account.Columns == models.AccountColumnsCredit {
+		tran = buildCreditTransaction(account.Source, p)
+		tran.MemberName = p.GetAccountOwner()
+	} else {
+		tran = buildCheckingTransaction(account.Source, p)
+	}
+	tran.AccountID = account.ID
+	return tran
+}
+
+// GetItemBalance returns the balances of one bank item's accounts
+func (c *Client) GetItemBalance(bankID, itemID string) Balance {
+	bank, ok := c.Banks[bankID]
+	if !ok {
+		return Balance{Error: fmt.Errorf("unknown bank %s", bankID)}
+	}
+	if len(bank.Items) == 0 {
+		return c.GetBalances([]string{bankID})[bankID]
+	}
+	for _, item := range bank.Items {
+		if item.ID == itemID {
+			bank.Items = []models.Item{item}
+			return c.getItemBalances(bank, context.Background())
+		}
+	}
+	return Balance{BankName: bank.Name, Error: fmt.Errorf("%s has no item %s", bankID, itemID)}
+}
@@ -0,0 +1,78 @@
+package banking
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/plaid/plaid-go/v15/plaid"
+	"register/pkg/models"
+)
+
+func Test_buildAccountTransaction(t *testing.T) {
+	type args struct {
+		account models.Account
+		p       plaid.Transaction
+	}
+	tests := []struct {
+		name string
+		args args
+		want *models.Transaction
+	}{
+		{
+			name: "Test savings account withdrawal",
+			args: args{
+				account: models.Account{ID: "sav1", Source: "WFSavings", Columns: models.AccountColumnsChecking, Register: true},
+				p:       plaid.Transaction{TransactionId: "t1", AccountId: "sav1", Date: "2026-01-05", Name: "TRANSFER", Amount: 100},
+			},
+			want: &models.Transaction{
+				Key: "wfsavings:01/05/26:100.00", TransactionID: "t1", AccountID: "sav1", Source: "WFSavings", Date: "01/05/26",
+				Name: "TRANSFER", BankName: "TRANSFER", Amount: 100, Withdrawal: 100, Budget: -100,
+			},
+		},
+		{
+			name: "Test second card under the same login",
+			args: args{
+				account: models.Account{ID: "card2", Source: "ChaseAmazon", Columns: models.AccountColumnsCredit, Register: true},
+				p:       plaid.Transaction{TransactionId: "t2", AccountId: "card2", Date: "2026-01-06", Name: "AMAZON", Amount: 30},
+			},
+			want: &models.Transaction{
+				Key: "chaseamazon:01/06/26:30.00", TransactionID: "t2", AccountID: "card2", Source: "ChaseAmazon", Date: "01/06/26",
+				Name: "AMAZON", BankName: "AMAZON", Amount: 30, CreditPurchase: 30, CreditCard: 30, Budget: -30,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildAccountTransaction(tt.args.account, tt.args.p); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildAccountTransaction() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_registerAccounts(t *testing.T) {
+	item := models.Item{ID: "chase", Accounts: []models.Account{
+		{ID: "card1", Register: true},
+		{ID: "card2", Register: true},
+		{ID: "savings", Register: false},
+	}}
+	got := registerAccounts(item)
+	if len(got) != 2 || got["card1"].ID != "card1" || got["card2"].ID != "card2" {
+		t.Errorf("registerAccounts() = %v", got)
+	}
+}
+
+func Test_accountBalance(t *testing.T) {
+	current := plaid.NewNullableFloat64(plaid.PtrFloat64(1234.56))
+	plaidAccounts := []plaid.AccountBase{
+		{AccountId: "chk", Name: "Checking", Balances: plaid.AccountBalance{Current: *current}},
+	}
+	got := accountBalance("Wells Fargo", models.Account{ID: "chk", Name: "Checking", BalanceCell: "G1"}, plaidAccounts, nil)
+	if got.Error != nil || got.Amount != 1234.56 || got.BalanceCell != "G1" {
+		t.Errorf("accountBalance() = %+v", got)
+	}
+	got = accountBalance("Wells Fargo", models.Account{ID: "sav", Name: "Savings"}, plaidAccounts, nil)
+	if got.Error == nil {
+		t.Errorf("accountBalance() of an account Plaid did not return = %+v, want an error", got)
+	}
+}
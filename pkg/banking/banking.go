@@ -18,30 +18,32 @@ package banking
 
 import (
 	"bytes"
+	"crypto/ecdsa"
 	"fmt"
 	"os"
 	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/plaid/plaid-go/v15/plaid"
 	"golang.org/x/net/context"
 	"register/pkg/config"
+	"register/pkg/income"
 	"register/pkg/models"
+	"register/pkg/token_store"
 )
 
 const (
-	PayCheckBankName = "NOVA BEER LLC"
-	PayCheckName     = "50/50 Taphouse Paycheck"
-	WellsFargoID     = "wellsfargo"
-	FidelityID       = "fidelity"
-	ChaseID          = "chase"
-	BankOfAmericaID  = "boa"
-	CitiID           = "citi"
-	AllyID           = "ally"
-	ETradeID         = "etrade"
-	BettermentID     = "betterment"
+	WellsFargoID    = "wellsfargo"
+	FidelityID      = "fidelity"
+	ChaseID         = "chase"
+	BankOfAmericaID = "boa"
+	CitiID          = "citi"
+	AllyID          = "ally"
+	ETradeID        = "etrade"
+	BettermentID    = "betterment"
 )
 
 type PlaidHttpBodyResponse struct {
@@ -59,9 +61,13 @@ type ClientOptions struct {
 	PlaidSecret      string
 	PlaidEnvironment plaid.Environment
 	PlaidTokensDir   string
+	Tokens           token_store.Store
+	ItemStatusFile   string
+	PlaidWebhookURL  string
 	UserID           string
 	Banks            map[string]config.Bank
 	BankReToName     map[string]string
+	Income           *income.Sources // nil for the default income source
 	Debug            bool
 	Verbose          bool
 }
@@ -72,17 +78,29 @@ type Client struct {
 	Secret       string
 	Environment  plaid.Environment
 	TokensDir    string
+	Tokens       token_store.Store
+	StatusFile   string
+	WebhookURL   string
+	statuses     map[string]*ItemStatus
+	statusMu     sync.Mutex
+	webhookKeys  map[string]*ecdsa.PublicKey
+	keysMu       sync.Mutex
 	UserID       string
 	Banks        map[string]config.Bank
 	BankReToName map[string]string
+	Income       *income.Sources
 	Debug        bool
 	Verbose      bool
 }
 
 type Balance struct {
-	BankName string
-	Amount   float64
-	Error    error
+	BankName    string
+	AccountID   string
+	AccountName string
+	BalanceCell string
+	Amount      float64
+	Error       error
+	Accounts    []Balance // each configured account of a bank with items; Amount is the first register account's
 }
 
 func NewClient(o *ClientOptions) *Client {
@@ -91,9 +109,13 @@ func NewClient(o *ClientOptions) *Client {
 		Secret:       o.PlaidSecret,
 		Environment:  o.PlaidEnvironment,
 		TokensDir:    o.PlaidTokensDir,
+		Tokens:       o.Tokens,
+		StatusFile:   o.ItemStatusFile,
+		WebhookURL:   o.PlaidWebhookURL,
 		UserID:       o.UserID,
 		Banks:        o.Banks,
 		BankReToName: o.BankReToName,
+		Income:       o.Income,
 		Debug:        o.Debug,
 		Verbose:      o.Debug,
 	}
@@ -128,10 +150,16 @@ func (c *Client) GetBalances(bankIDs []string) map[string]Balance {
 
 	for _, id := range bankIDs {
 		bank := c.Banks[id]
+		if len(bank.Items) > 0 {
+			balances[id] = c.getItemBalances(bank, ctx)
+			continue
+		}
 
-		tok, err := os.ReadFile(c.TokensDir + "/" + bank.Source + "AccessToken.txt")
-		checkError(err)
-		accessToken := strings.ReplaceAll(string(tok), "\n", "")
+		accessToken, err := c.accessToken(id, bank.Source)
+		if err != nil {
+			balances[id] = Balance{BankName: bank.Name, Error: err}
+			continue
+		}
 
 		amount, err := c.GetBalance(accessToken, id, ctx)
 		if err != nil {
@@ -150,11 +178,30 @@ func (c *Client) GetBalances(bankIDs []string) map[string]Balance {
 	return balances
 }
 
+// GetBalance returns the current balance of the bank's AccountID, or of the item's first account if none is configured
 func (c *Client) GetBalance(accessToken string, bankID string, ctx context.Context) (*float64, error) {
+	var accountIDs []string
+	if id := c.Banks[bankID].AccountID; id != "" {
+		accountIDs = []string{id}
+	}
+	accounts, err := c.getPlaidBalances(accessToken, accountIDs, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error with %s\n%s", bankID, err.Error())
+	}
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("no accounts returned for %s", bankID)
+	}
+	return currentBalance(accounts[0])
+}
+
+// getPlaidBalances returns the item's accounts with their balances, only accountIDs if given
+func (c *Client) getPlaidBalances(accessToken string, accountIDs []string, ctx context.Context) ([]plaid.AccountBase, error) {
 	balancesGetReq := plaid.NewAccountsBalanceGetRequest(accessToken)
-	balancesGetReq.SetOptions(plaid.AccountsBalanceGetRequestOptions{
-		AccountIds: &[]string{c.Banks[bankID].AccountID},
-	})
+	if len(accountIDs) > 0 {
+		balancesGetReq.SetOptions(plaid.AccountsBalanceGetRequestOptions{
+			AccountIds: &accountIDs,
+		})
+	}
 
 	balancesGetResp, httpResponse, err := c.PlaidClient.PlaidApi.AccountsBalanceGet(ctx).AccountsBalanceGetRequest(
 		*balancesGetReq,
@@ -165,13 +212,17 @@ func (c *Client) GetBalance(accessToken string, bankID string, ctx context.Conte
 		if err2 != nil {
 			return nil, err2
 		}
-		return nil, fmt.Errorf("error with %s\n%s: %s", bankID, err.Error(), buf.Bytes())
+		return nil, fmt.Errorf("%s: %s", err.Error(), buf.Bytes())
 	}
-	nullFloat64 := balancesGetResp.Accounts[0].Balances.Current
-	if !nullFloat64.IsSet() {
-		return nil, fmt.Errorf("available balance is not set")
+	return balancesGetResp.Accounts, nil
+}
+
+func currentBalance(account plaid.AccountBase) (*float64, error) {
+	nullFloat64 := account.Balances.Current
+	if !nullFloat64.IsSet() || nullFloat64.Get() == nil {
+		return nil, fmt.Errorf("current balance of %s is not set", account.Name)
 	}
-	return nullFloat64.Get(), err
+	return nullFloat64.Get(), nil
 }
 
 func (c *Client) GetBankStatus(bankID string) (*plaid.Institution, error) {
@@ -195,30 +246,59 @@ func (c *Client) GetBankStatus(bankID string) (*plaid.Institution, error) {
 	return &resp.Institution, nil
 }
 
+// GetTransactions reads the transactions of each bank's items. An item that fails, eg. because its login
+// expired, is returned in ItemErrors along with the transactions of the healthy items, and its status is recorded.
 func (c *Client) GetTransactions(bankIDs []string, startDate, endDate string) ([]*models.Transaction, error) {
 	var transactions []*models.Transaction
-	var errors string
+	var itemErrors ItemErrors
 	for _, bankID := range bankIDs {
 		bankConfig := c.Banks[bankID]
 		fmt.Printf("    %s...", bankConfig.Name)
 
-		transResp, err := c.getPlaidTransactions(bankConfig, startDate, endDate)
-		if err != nil {
-			errors += err.Error()
-			continue
+		var bankTransactions []*models.Transaction
+		var errs ItemErrors
+		if len(bankConfig.Items) > 0 {
+			bankTransactions, errs = c.getItemTransactions(bankConfig, startDate, endDate)
+		} else {
+			bankTransactions, errs = c.getBankTransactions(bankConfig, startDate, endDate)
 		}
+		transactions = append(transactions, bankTransactions...)
+		itemErrors = append(itemErrors, errs...)
+		if len(errs) > 0 {
+			fmt.Println("failed")
+		} else {
+			fmt.Println("done")
+		}
+	}
+	if len(itemErrors) > 0 {
+		return transactions, itemErrors
+	}
+	return transactions, nil
+}
 
-		for _, trans := range transResp {
-			// skip CC payment transaction as these will show up as checking account payments
-			register := c.buildTransaction(bankConfig.ID, trans)
-			transactions = append(transactions, register)
-			//printPlaidTransaction(t, bankID)
-			//printTransaction(r)
+// getBankTransactions reads the transactions of a bank without items; its token is stored under its Source
+func (c *Client) getBankTransactions(bankConfig config.Bank, startDate, endDate string) ([]*models.Transaction, ItemErrors) {
+	accessToken := bankConfig.AccessToken
+	if accessToken == "" {
+		var err error
+		accessToken, err = c.accessToken(bankConfig.ID, bankConfig.Source)
+		if err != nil {
+			return nil, ItemErrors{c.itemFailed(bankConfig.ID, bankConfig.Source, err)}
 		}
-		fmt.Println("done")
 	}
-	if errors != "" {
-		return nil, fmt.Errorf("%s", errors)
+	transResp, err := c.getPlaidTransactions(accessToken, nil, startDate, endDate)
+	if err != nil {
+		return nil, ItemErrors{c.itemFailed(bankConfig.ID, bankConfig.Source, err)}
+	}
+	c.itemSucceeded(bankConfig.ID, bankConfig.Source)
+
+	var transactions []*models.Transaction
+	for _, trans := range transResp {
+		// skip CC payment transaction as these will show up as checking account payments
+		register := c.buildTransaction(bankConfig.ID, trans)
+		transactions = append(transactions, register)
+		//printPlaidTransaction(t, bankID)
+		//printTransaction(r)
 	}
 	return transactions, nil
 }
@@ -231,11 +311,12 @@ func printTransaction(t *models.Transaction) {
 	fmt.Printf("[%-28s] %6.2f %6.2f %6.2f %6.2f %6.2f %6.2f\n", t.Key, t.Amount, t.Withdrawal, t.Deposit, t.CreditPurchase, t.Budget, t.CreditCard)
 }
 
-func (c *Client) getPlaidTransactions(bankConfig config.Bank, startDate, endDate string) ([]plaid.Transaction, error) {
+// getPlaidTransactions returns the item's transactions, only those of accountIDs if given
+func (c *Client) getPlaidTransactions(accessToken string, accountIDs []string, startDate, endDate string) ([]plaid.Transaction, error) {
 	ctx := context.Background()
 
 	request := plaid.NewTransactionsGetRequest(
-		bankConfig.AccessToken,
+		accessToken,
 		startDate,
 		endDate,
 	)
@@ -243,17 +324,15 @@ func (c *Client) getPlaidTransactions(bankConfig config.Bank, startDate, endDate
 		Count:  plaid.PtrInt32(100),
 		Offset: plaid.PtrInt32(0),
 	}
+	if len(accountIDs) > 0 {
+		options.AccountIds = &accountIDs
+	}
 	request.SetOptions(options)
 
 	resp, httpResp, err := c.PlaidClient.PlaidApi.TransactionsGet(ctx).TransactionsGetRequest(*request).Execute()
 
 	if err != nil {
-		buf := new(bytes.Buffer)
-		_, err2 := buf.ReadFrom(httpResp.Body)
-		if err2 != nil {
-			return nil, err2
-		}
-		return nil, fmt.Errorf("%s\n%s", err.Error(), buf.String())
+		return nil, plaidError(err, httpResp)
 	}
 	return resp.Transactions, nil
 
@@ -284,58 +363,71 @@ func (c *Client) getPlaidTransactions(bankConfig config.Bank, startDate, endDate
 }
 
 func (c *Client) buildTransaction(bankID string, p plaid.Transaction) *models.Transaction {
-	tran := &models.Transaction{
-		Date:     readDateValue(p.Date),
-		Name:     "",
-		BankName: p.Name,
-	}
-
 	switch bankID {
 	case WellsFargoID:
-		if p.CheckNumber.IsSet() {
-			tran.Source = *p.CheckNumber.Get()
-			tran.IsCheck = true
-			tran.Name = "CHECK"
-			tran.BankName = "CHECK"
-		} else {
-			tran.Source = "WellsFargo"
-			if tran.Name == "" {
-				tran.Name = tran.BankName
-			}
-		}
-
-		tran.Amount = p.Amount
-		if p.Amount < 0 {
-			tran.Deposit = -1 * p.Amount // covert to positive
-			tran.Key = fmt.Sprintf("%s:%s:%.2f", strings.ToLower(tran.Source), readDateValue(p.Date), tran.Deposit)
-		} else {
-			tran.Withdrawal = p.Amount
-			tran.Key = fmt.Sprintf("%s:%s:%.2f", strings.ToLower(tran.Source), readDateValue(p.Date), tran.Withdrawal)
-		}
-		tran.Budget = -1 * p.Amount
+		return buildCheckingTransaction("WellsFargo", p)
 	case FidelityID:
-		tran.Source = "Fidelity"
-		tran.Amount = p.Amount         // amount stays as is (positive)
-		cc := p.Amount                 // keep positive
-		tran.CreditPurchase = p.Amount // keep positive
-		tran.CreditCard = p.Amount     // keep positive
-		tran.Budget = -1 * p.Amount    // budget category column negative
-		tran.Key = fmt.Sprintf("%s:%s:%.2f", strings.ToLower(tran.Source), tran.Date, cc)
-		if tran.Name == "" {
-			tran.Name = tran.BankName
-		}
+		return buildCreditTransaction("Fidelity", p)
 	case ChaseID:
-		tran.Source = "Chase"
-		tran.Amount = p.Amount         // amount stays as is (positive)
-		cc := p.Amount                 // keep positive
-		tran.CreditPurchase = p.Amount // keep positive
-		tran.CreditCard = p.Amount     // keep positive
-		tran.Budget = -1 * p.Amount    // budget category column negative
-		tran.Key = fmt.Sprintf("%s:%s:%.2f", strings.ToLower(tran.Source), tran.Date, cc)
+		return buildCreditTransaction("Chase", p)
+	case CitiID:
+		tran := buildCreditTransaction("Citi", p)
+		tran.MemberName = p.GetAccountOwner() // the cardholder, when Plaid knows it
+		return tran
+	}
+	return newTransaction(p)
+}
+
+func newTransaction(p plaid.Transaction) *models.Transaction {
+	return &models.Transaction{
+		TransactionID: p.TransactionId,
+		AccountID:     p.AccountId,
+		Date:          readDateValue(p.Date),
+		Name:          "",
+		BankName:      p.Name,
+	}
+}
+
+// buildCheckingTransaction fills the Withdrawal or Deposit column; checks take their number as the Source
+func buildCheckingTransaction(source string, p plaid.Transaction) *models.Transaction {
+	tran := newTransaction(p)
+	if p.CheckNumber.IsSet() {
+		tran.Source = *p.CheckNumber.Get()
+		tran.IsCheck = true
+		tran.Name = "CHECK"
+		tran.BankName = "CHECK"
+	} else {
+		tran.Source = source
 		if tran.Name == "" {
 			tran.Name = tran.BankName
 		}
 	}
+
+	tran.Amount = p.Amount
+	if p.Amount < 0 {
+		tran.Deposit = -1 * p.Amount // covert to positive
+		tran.Key = fmt.Sprintf("%s:%s:%.2f", strings.ToLower(tran.Source), readDateValue(p.Date), tran.Deposit)
+	} else {
+		tran.Withdrawal = p.Amount
+		tran.Key = fmt.Sprintf("%s:%s:%.2f", strings.ToLower(tran.Source), readDateValue(p.Date), tran.Withdrawal)
+	}
+	tran.Budget = -1 * p.Amount
+	return tran
+}
+
+// buildCreditTransaction fills the Credit Purchases and Credit Card columns
+func buildCreditTransaction(source string, p plaid.Transaction) *models.Transaction {
+	tran := newTransaction(p)
+	tran.Source = source
+	tran.Amount = p.Amount         // amount stays as is (positive)
+	cc := p.Amount                 // keep positive
+	tran.CreditPurchase = p.Amount // keep positive
+	tran.CreditCard = p.Amount     // keep positive
+	tran.Budget = -1 * p.Amount    // budget category column negative
+	tran.Key = fmt.Sprintf("%s:%s:%.2f", strings.ToLower(tran.Source), tran.Date, cc)
+	if tran.Name == "" {
+		tran.Name = tran.BankName
+	}
 	return tran
 }
 
@@ -361,10 +453,10 @@ func (c *Client) FormatMerchantNames(trans []*models.Transaction, lookup []*mode
 			trans[i].ColumnIndex = 10
 			trans[i].IsCategory = false
 			trans[i].TaxDeductible = false
-		} else if strings.Contains(t.BankName, PayCheckBankName) {
-			trans[i].Name = PayCheckName
-			trans[i].Color = "green"
-			trans[i].ColumnIndex = 42
+		} else if src := c.Income.Match(t.BankName); src != nil && t.Deposit > 0 {
+			trans[i].Name = src.Name
+			trans[i].Color = src.Color
+			trans[i].ColumnIndex = src.ColumnIndex
 			trans[i].IsCategory = false
 			trans[i].TaxDeductible = false
 		} else {
@@ -0,0 +1,242 @@
+package banking
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/plaid/plaid-go/v15/plaid"
+	"golang.org/x/net/context"
+)
+
+// Item statuses recorded per bank item
+const (
+	ItemStatusOK            = "ok"
+	ItemStatusLoginRequired = "login_required" // the user must re-link the item with an update-mode link token
+	ItemStatusError         = "error"
+
+	// ItemStatusFileName records the item statuses in PlaidTokensDir
+	ItemStatusFileName = "item_status.json"
+)
+
+// relinkErrorCodes are the Plaid error codes fixed by re-authenticating the item in Link update mode
+var relinkErrorCodes = map[string]bool{
+	"ITEM_LOGIN_REQUIRED":      true,
+	"INVALID_CREDENTIALS":      true,
+	"INVALID_MFA":              true,
+	"ITEM_LOCKED":              true,
+	"USER_SETUP_REQUIRED":      true,
+	"PENDING_EXPIRATION":       true,
+	"INVALID_UPDATED_USERNAME": true,
+}
+
+// APIError is an error response from the Plaid API
+type APIError struct {
+	PlaidHttpBodyResponse
+	StatusCode int
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s: %s (HTTP %d, request %s)", e.ErrorType, e.ErrorCode, e.ErrorMessage, e.StatusCode, e.RequestId)
+}
+
+// ItemError is the failure of one bank item
+type ItemError struct {
+	BankID string
+	ItemID string
+	Err    error
+	status *ItemStatus
+}
+
+func (e *ItemError) Error() string {
+	return fmt.Sprintf("%s item %s: %s", e.BankID, e.ItemID, e.Err.Error())
+}
+
+func (e *ItemError) Unwrap() error {
+	return e.Err
+}
+
+// LoginRequired returns true if the item must be re-linked
+func (e *ItemError) LoginRequired() bool {
+	var apiErr *APIError
+	return errors.As(e.Err, &apiErr) && relinkErrorCodes[apiErr.ErrorCode]
+}
+
+// ItemErrors are the items that failed while the others were read
+type ItemErrors []*ItemError
+
+func (e ItemErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// ItemStatus is the last known health of a bank item
+type ItemStatus struct {
+	BankID      string    `json:"bankID"`
+	ItemID      string    `json:"itemID"`
+	PlaidItemID string    `json:"plaidItemID,omitempty"` // Plaid's item_id, which webhooks are addressed by
+	Status      string    `json:"status"`
+	ErrorCode   string    `json:"errorCode,omitempty"`
+	Message     string    `json:"message,omitempty"`
+	CheckedAt   time.Time `json:"checkedAt"`
+}
+
+// plaidError parses the Plaid error body, if there was a response, into an APIError
+func plaidError(err error, httpResp *http.Response) error {
+	if httpResp == nil {
+		return err
+	}
+	buf := new(bytes.Buffer)
+	if _, err2 := buf.ReadFrom(httpResp.Body); err2 != nil {
+		return err2
+	}
+	apiErr := &APIError{StatusCode: httpResp.StatusCode}
+	if json.Unmarshal(buf.Bytes(), &apiErr.PlaidHttpBodyResponse) != nil || apiErr.ErrorCode == "" {
+		return fmt.Errorf("%s\n%s", err.Error(), buf.String())
+	}
+	return apiErr
+}
+
+// CheckItem asks Plaid for an item's health and records its status
+func (c *Client) CheckItem(bankID, itemID string, ctx context.Context) *ItemStatus {
+	accessToken, err := c.accessToken(bankID, itemID)
+	if err != nil {
+		return c.itemFailed(bankID, itemID, err).status
+	}
+	resp, httpResp, err := c.PlaidClient.PlaidApi.ItemGet(ctx).ItemGetRequest(*plaid.NewItemGetRequest(accessToken)).Execute()
+	if err != nil {
+		return c.itemFailed(bankID, itemID, plaidError(err, httpResp)).status
+	}
+
+	item := resp.GetItem()
+	var status *ItemStatus
+	if itemErr, ok := item.GetErrorOk(); ok && itemErr != nil && itemErr.ErrorCode != "" {
+		status = c.itemFailed(bankID, itemID, &APIError{PlaidHttpBodyResponse: PlaidHttpBodyResponse{
+			ErrorCode:    itemErr.ErrorCode,
+			ErrorMessage: itemErr.ErrorMessage,
+			ErrorType:    string(itemErr.ErrorType),
+		}}).status
+	} else {
+		status = c.itemSucceeded(bankID, itemID)
+	}
+	c.statusMu.Lock()
+	status.PlaidItemID = item.GetItemId()
+	c.statusMu.Unlock()
+	return status
+}
+
+// ItemStatuses returns the recorded item statuses, those of this run over those saved in StatusFile, sorted
+func (c *Client) ItemStatuses() ([]*ItemStatus, error) {
+	saved, err := c.readItemStatuses()
+	if err != nil {
+		return nil, err
+	}
+	c.statusMu.Lock()
+	for k, s := range c.statuses {
+		if old, ok := saved[k]; ok && s.PlaidItemID == "" {
+			s.PlaidItemID = old.PlaidItemID
+		}
+		saved[k] = s
+	}
+	c.statusMu.Unlock()
+	statuses := make([]*ItemStatus, 0, len(saved))
+	for _, s := range saved {
+		statuses = append(statuses, s)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statusKey(statuses[i].BankID, statuses[i].ItemID) < statusKey(statuses[j].BankID, statuses[j].ItemID)
+	})
+	return statuses, nil
+}
+
+// SaveItemStatuses writes the statuses recorded in this run to StatusFile, keeping the other items' statuses
+func (c *Client) SaveItemStatuses() error {
+	c.statusMu.Lock()
+	recorded := len(c.statuses)
+	c.statusMu.Unlock()
+	if c.StatusFile == "" || recorded == 0 {
+		return nil
+	}
+	statuses, err := c.ItemStatuses()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(statuses, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(c.StatusFile, data, 0600); err != nil {
+		return fmt.Errorf("could not write item status file %s: %s", c.StatusFile, err.Error())
+	}
+	return nil
+}
+
+func (c *Client) readItemStatuses() (map[string]*ItemStatus, error) {
+	saved := make(map[string]*ItemStatus)
+	if c.StatusFile == "" {
+		return saved, nil
+	}
+	data, err := os.ReadFile(c.StatusFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return saved, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read item status file %s: %s", c.StatusFile, err.Error())
+	}
+	var statuses []*ItemStatus
+	if err := json.Unmarshal(data, &statuses); err != nil {
+		return nil, fmt.Errorf("could not parse item status file %s: %s", c.StatusFile, err.Error())
+	}
+	for _, s := range statuses {
+		saved[statusKey(s.BankID, s.ItemID)] = s
+	}
+	return saved, nil
+}
+
+// itemFailed records the item's status from err and returns it as an ItemError
+func (c *Client) itemFailed(bankID, itemID string, err error) *ItemError {
+	itemErr := &ItemError{BankID: bankID, ItemID: itemID, Err: err}
+	status := &ItemStatus{BankID: bankID, ItemID: itemID, Status: ItemStatusError, Message: err.Error(), CheckedAt: time.Now()}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		status.ErrorCode = apiErr.ErrorCode
+		status.Message = apiErr.ErrorMessage
+	}
+	if itemErr.LoginRequired() {
+		status.Status = ItemStatusLoginRequired
+	}
+	itemErr.status = c.setStatus(status)
+	return itemErr
+}
+
+func (c *Client) itemSucceeded(bankID, itemID string) *ItemStatus {
+	return c.setStatus(&ItemStatus{BankID: bankID, ItemID: itemID, Status: ItemStatusOK, CheckedAt: time.Now()})
+}
+
+// setStatus records the item's status, keeping the Plaid item_id already known for it
+func (c *Client) setStatus(status *ItemStatus) *ItemStatus {
+	c.statusMu.Lock()
+	defer c.statusMu.Unlock()
+	if c.statuses == nil {
+		c.statuses = make(map[string]*ItemStatus)
+	}
+	k := statusKey(status.BankID, status.ItemID)
+	if old, ok := c.statuses[k]; ok && status.PlaidItemID == "" {
+		status.PlaidItemID = old.PlaidItemID
+	}
+	c.statuses[k] = status
+	return status
+}
+
+func statusKey(bankID, itemID string) string {
+	return bankID + "/" + itemID
+}
@@ -0,0 +1,132 @@
+package banking
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/plaid/plaid-go/v15/plaid"
+	"register/pkg/config"
+	"register/pkg/token_store"
+)
+
+// fakeTokens is a token_store.Store in memory
+type fakeTokens map[string]string
+
+func (f fakeTokens) Get(institution, itemID string) (string, error) {
+	if t, ok := f[institution+"/"+itemID]; ok {
+		return t, nil
+	}
+	return "", token_store.ErrNotFound
+}
+func (f fakeTokens) Put(institution, itemID, token string) error {
+	f[institution+"/"+itemID] = token
+	return nil
+}
+func (f fakeTokens) Remove(institution, itemID string) error {
+	delete(f, institution+"/"+itemID)
+	return nil
+}
+func (f fakeTokens) List() ([]token_store.Key, error) {
+	return nil, nil
+}
+
+// newFakePlaid answers /transactions/get with ITEM_LOGIN_REQUIRED for the "expired" access token and one
+// transaction otherwise
+func newFakePlaid(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			AccessToken string `json:"access_token"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		if req.AccessToken == "expired" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"error_type":"ITEM_ERROR","error_code":"ITEM_LOGIN_REQUIRED",
+				"error_message":"the login details of this item have changed","display_message":null,"request_id":"req1"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"accounts":[],"total_transactions":1,"request_id":"req2",
+			"item":{"item_id":"item1","available_products":[],"billed_products":[],"webhook":null,"error":null,"consent_expiration_time":null,"update_type":"background"},
+			"transactions":[{"transaction_id":"t1","account_id":"acct1","amount":12.5,"iso_currency_code":"USD",
+			"unofficial_currency_code":null,"category":null,"category_id":null,"check_number":null,"date":"2026-01-05",
+			"datetime":null,"authorized_date":null,"authorized_datetime":null,"location":{},"name":"NETFLIX",
+			"merchant_name":null,"payment_meta":{},"payment_channel":"online","pending":false,"pending_transaction_id":null,
+			"account_owner":null,"transaction_code":null}]}`))
+	}))
+}
+
+func TestClient_GetTransactions_carriesOnPastFailedItems(t *testing.T) {
+	ts := newFakePlaid(t)
+	defer ts.Close()
+
+	c := NewClient(&ClientOptions{
+		PlaidEnvironment: plaid.Environment(ts.URL),
+		Tokens:           fakeTokens{"wellsfargo/WellsFargo": "expired", "chase/Chase": "good"},
+		ItemStatusFile:   filepath.Join(t.TempDir(), ItemStatusFileName),
+		Banks: map[string]config.Bank{
+			"wellsfargo": {ID: "wellsfargo", Name: "Wells Fargo", Source: "WellsFargo"},
+			"chase":      {ID: "chase", Name: "Chase", Source: "Chase"},
+		},
+	})
+	trans, err := c.GetTransactions([]string{"wellsfargo", "chase"}, "2026-01-01", "2026-01-31")
+
+	itemErrors, ok := err.(ItemErrors)
+	if !ok || len(itemErrors) != 1 || itemErrors[0].BankID != "wellsfargo" || !itemErrors[0].LoginRequired() {
+		t.Fatalf("GetTransactions() error = %v, want ITEM_LOGIN_REQUIRED for wellsfargo", err)
+	}
+	if len(trans) != 1 || trans[0].Key != "chase:01/05/26:12.50" {
+		t.Errorf("GetTransactions() = %v, want the chase transaction", trans)
+	}
+
+	if err := c.SaveItemStatuses(); err != nil {
+		t.Fatalf("SaveItemStatuses() error = %v", err)
+	}
+	saved := NewClient(&ClientOptions{ItemStatusFile: c.StatusFile})
+	statuses, err := saved.ItemStatuses()
+	if err != nil || len(statuses) != 2 {
+		t.Fatalf("ItemStatuses() = %v, %v", statuses, err)
+	}
+	if statuses[0].BankID != "chase" || statuses[0].Status != ItemStatusOK {
+		t.Errorf("ItemStatuses()[0] = %+v", statuses[0])
+	}
+	if statuses[1].Status != ItemStatusLoginRequired || statuses[1].ErrorCode != "ITEM_LOGIN_REQUIRED" {
+		t.Errorf("ItemStatuses()[1] = %+v", statuses[1])
+	}
+}
+
+func Test_plaidError(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want string
+	}{
+		{
+			name: "Test Plaid error body",
+			body: `{"error_type":"INVALID_INPUT","error_code":"INVALID_ACCESS_TOKEN","error_message":"bad token","request_id":"r1"}`,
+			want: "INVALID_INPUT INVALID_ACCESS_TOKEN: bad token (HTTP 400, request r1)",
+		},
+		{
+			name: "Test other body",
+			body: `<html>bad gateway</html>`,
+			want: "400 Bad Request\n<html>bad gateway</html>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: 400, Body: io.NopCloser(strings.NewReader(tt.body))}
+			got := plaidError(errString("400 Bad Request"), resp)
+			if got.Error() != tt.want {
+				t.Errorf("plaidError() = %q, want %q", got.Error(), tt.want)
+			}
+		})
+	}
+}
+
+type errString string
+
+func (e errString) Error() string { return string(e) }
@@ -0,0 +1,49 @@
+package banking
+
+import (
+	"fmt"
+
+	"github.com/plaid/plaid-go/v15/plaid"
+	"golang.org/x/net/context"
+)
+
+// SaveAccessToken stores a newly exchanged access token for a bank's item
+func (c *Client) SaveAccessToken(bankID, itemID, accessToken string) error {
+	if c.Tokens == nil {
+		return fmt.Errorf("no token store for %s item %s", bankID, itemID)
+	}
+	return c.Tokens.Put(bankID, itemID, accessToken)
+}
+
+// RotateAccessToken has Plaid invalidate an item's access token and stores the new one in its place
+func (c *Client) RotateAccessToken(bankID, itemID string, ctx context.Context) error {
+	accessToken, err := c.accessToken(bankID, itemID)
+	if err != nil {
+		return err
+	}
+	req := plaid.NewItemAccessTokenInvalidateRequest(accessToken)
+	resp, httpResp, err := c.PlaidClient.PlaidApi.ItemAccessTokenInvalidate(ctx).ItemAccessTokenInvalidateRequest(*req).Execute()
+	if err != nil {
+		return plaidError(err, httpResp)
+	}
+	return c.Tokens.Put(bankID, itemID, resp.GetNewAccessToken())
+}
+
+// RemoveAccessToken removes the item at Plaid, which invalidates its access token, and deletes the stored token
+func (c *Client) RemoveAccessToken(bankID, itemID string, ctx context.Context) error {
+	accessToken, err := c.accessToken(bankID, itemID)
+	if err != nil {
+		return err
+	}
+	req := plaid.NewItemRemoveRequest(accessToken)
+	_, httpResp, err := c.PlaidClient.PlaidApi.ItemRemove(ctx).ItemRemoveRequest(*req).Execute()
+	if err != nil {
+		return plaidError(err, httpResp)
+	}
+	return c.Tokens.Remove(bankID, itemID)
+}
+
+// AccessToken returns the stored access token of a bank's item
+func (c *Client) AccessToken(bankID, itemID string) (string, error) {
+	return c.accessToken(bankID, itemID)
+}
@@ -0,0 +1,109 @@
+package banking
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/plaid/plaid-go/v15/plaid"
+	"golang.org/x/net/context"
+	"register/pkg/models"
+)
+
+// WebhookVerificationKey returns Plaid's public key for a webhook JWT key ID. Keys are cached; an expired key
+// is an error.
+func (c *Client) WebhookVerificationKey(kid string) (*ecdsa.PublicKey, error) {
+	c.keysMu.Lock()
+	defer c.keysMu.Unlock()
+	if key, ok := c.webhookKeys[kid]; ok {
+		return key, nil
+	}
+
+	ctx := context.Background()
+	req := plaid.NewWebhookVerificationKeyGetRequest(kid)
+	resp, httpResp, err := c.PlaidClient.PlaidApi.WebhookVerificationKeyGet(ctx).WebhookVerificationKeyGetRequest(*req).Execute()
+	if err != nil {
+		return nil, plaidError(err, httpResp)
+	}
+	jwk := resp.GetKey()
+	if expired := jwk.ExpiredAt.Get(); jwk.ExpiredAt.IsSet() && expired != nil {
+		return nil, fmt.Errorf("webhook verification key %s expired at %s", kid, time.Unix(int64(*expired), 0).Format(time.RFC3339))
+	}
+	key, err := ecdsaPublicKey(jwk)
+	if err != nil {
+		return nil, err
+	}
+	if c.webhookKeys == nil {
+		c.webhookKeys = make(map[string]*ecdsa.PublicKey)
+	}
+	c.webhookKeys[kid] = key
+	return key, nil
+}
+
+// ecdsaPublicKey reads a P-256 JWK
+func ecdsaPublicKey(jwk plaid.JWKPublicKey) (*ecdsa.PublicKey, error) {
+	if jwk.Kty != "EC" || jwk.Crv != "P-256" {
+		return nil, fmt.Errorf("webhook verification key %s is %s %s, want EC P-256", jwk.Kid, jwk.Kty, jwk.Crv)
+	}
+	x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+	if err != nil {
+		return nil, fmt.Errorf("webhook verification key %s: %s", jwk.Kid, err.Error())
+	}
+	y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+	if err != nil {
+		return nil, fmt.Errorf("webhook verification key %s: %s", jwk.Kid, err.Error())
+	}
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+}
+
+// FindItem returns the bank and config item of a Plaid item_id, as recorded by CheckItem
+func (c *Client) FindItem(plaidItemID string) (bankID, itemID string, ok bool) {
+	statuses, err := c.ItemStatuses()
+	if err != nil {
+		return "", "", false
+	}
+	for _, s := range statuses {
+		if s.PlaidItemID == plaidItemID {
+			return s.BankID, s.ItemID, true
+		}
+	}
+	return "", "", false
+}
+
+// RecordItemError records an item error reported by Plaid, eg. by an ITEM_ERROR webhook, and saves the statuses
+func (c *Client) RecordItemError(bankID, itemID, errorCode, message string) error {
+	c.itemFailed(bankID, itemID, &APIError{PlaidHttpBodyResponse: PlaidHttpBodyResponse{
+		ErrorCode:    errorCode,
+		ErrorMessage: message,
+	}})
+	return c.SaveItemStatuses()
+}
+
+// GetItemTransactions returns the transactions of one bank item, as when a webhook reports it has new ones
+func (c *Client) GetItemTransactions(bankID, itemID, startDate, endDate string) ([]*models.Transaction, error) {
+	bank, ok := c.Banks[bankID]
+	if !ok {
+		return nil, fmt.Errorf("unknown bank %s", bankID)
+	}
+
+	var transactions []*models.Transaction
+	var itemErrors ItemErrors
+	if len(bank.Items) == 0 {
+		transactions, itemErrors = c.getBankTransactions(bank, startDate, endDate)
+	} else {
+		for _, item := range bank.Items {
+			if item.ID == itemID {
+				bank.Items = []models.Item{item}
+				transactions, itemErrors = c.getItemTransactions(bank, startDate, endDate)
+				break
+			}
+		}
+	}
+	if len(itemErrors) > 0 {
+		return transactions, itemErrors
+	}
+	return transactions, nil
+}
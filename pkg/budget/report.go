@@ -0,0 +1,184 @@
+package budget
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"register/api/services/sheets_service"
+	"register/pkg/models"
+)
+
+// Format ...
+type Format string
+
+const (
+	// Table ...
+	Table Format = "table"
+	// CSV ...
+	CSV Format = "csv"
+	// JSON ...
+	JSON Format = "json"
+
+	MonthFormat = "2006-01"
+
+	colorRed   = "\033[31m"
+	colorReset = "\033[0m"
+)
+
+// ParseFormat ...
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case Table, CSV, JSON:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown report format: %s; use table, csv or json", s)
+}
+
+// Line is one budget category's budgeted, spent and remaining amounts for the month
+type Line struct {
+	Category  string  `json:"category"`
+	Budgeted  float64 `json:"budgeted"`
+	Spent     float64 `json:"spent"`
+	Remaining float64 `json:"remaining"`
+	Over      bool    `json:"over"`
+}
+
+// Report compares a month's budget with its spending, by category column
+type Report struct {
+	Month string `json:"month"`
+	Lines []Line `json:"lines"`
+	Total Line   `json:"total"`
+}
+
+// MonthlyAmount returns a budget entry's amount for the month. The Budget sheet holds the same budget in
+// each period column, so one column is used: Monthly if set, otherwise the first non-zero one pro-rated,
+// weekly and every-2-weeks amounts by the month's days, twice-monthly amounts doubled and yearly amounts
+// divided by 12
+func MonthlyAmount(e *sheets_service.BudgetEntry, month time.Time) float64 {
+	days := float64(daysIn(month))
+	switch {
+	case e.Monthly != 0:
+		return e.Monthly
+	case e.Weekly != 0:
+		return e.Weekly * days / 7
+	case e.Every2Weeks != 0:
+		return e.Every2Weeks * days / 14
+	case e.TwiceMonthly != 0:
+		return 2 * e.TwiceMonthly
+	}
+	return e.Yearly / 12
+}
+
+func daysIn(month time.Time) int {
+	return time.Date(month.Year(), month.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// NewReport totals the month's spending per category column and compares it with the budget. Spending
+// is the negated Budget amount of the transactions, so refunds reduce it. Categories with neither a
+// budget nor spending are left out.
+func NewReport(entries []*sheets_service.BudgetEntry, columns []models.Column, trans []models.Transaction, month time.Time) *Report {
+	budgets := make(map[string]*sheets_service.BudgetEntry)
+	for _, e := range entries {
+		budgets[e.Category] = e
+	}
+
+	spent := make(map[int]float64)
+	for _, t := range trans {
+		date, err := time.Parse(models.RegisterDateFormat, t.Date)
+		if err != nil || date.Year() != month.Year() || date.Month() != month.Month() {
+			continue
+		}
+		spent[t.ColumnIndex] -= t.Budget
+	}
+
+	var categories []models.Column
+	for _, c := range columns {
+		if c.IsCategory {
+			categories = append(categories, c)
+		}
+	}
+	sort.SliceStable(categories, func(i, j int) bool {
+		return categories[i].ColumnIndex < categories[j].ColumnIndex
+	})
+
+	r := &Report{Month: month.Format(MonthFormat), Lines: []Line{}, Total: Line{Category: "Total"}}
+	for _, c := range categories {
+		l := Line{Category: c.Name, Spent: round(spent[c.ColumnIndex])}
+		if e, ok := budgets[c.Name]; ok {
+			l.Budgeted = round(MonthlyAmount(e, month))
+		}
+		if l.Budgeted == 0 && l.Spent == 0 {
+			continue
+		}
+		l.finish()
+		r.Lines = append(r.Lines, l)
+		r.Total.Budgeted += l.Budgeted
+		r.Total.Spent += l.Spent
+	}
+	r.Total.Budgeted, r.Total.Spent = round(r.Total.Budgeted), round(r.Total.Spent)
+	r.Total.finish()
+	return r
+}
+
+func (l *Line) finish() {
+	l.Remaining = round(l.Budgeted - l.Spent)
+	l.Over = l.Remaining < 0
+}
+
+func round(f float64) float64 {
+	return math.Round(f*100) / 100
+}
+
+// rows are the category lines followed by the total
+func (r *Report) rows() []Line {
+	return append(append([]Line{}, r.Lines...), r.Total)
+}
+
+// Write writes the report in the format; color highlights over-budget table rows in red
+func Write(w io.Writer, r *Report, format Format, color bool) error {
+	switch format {
+	case CSV:
+		return writeCSV(w, r)
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	}
+	return writeTable(w, r, color)
+}
+
+func writeTable(w io.Writer, r *Report, color bool) error {
+	if _, err := fmt.Fprintf(w, "Budget %s\n    %-30s %12s %12s %12s\n", r.Month, "Category", "Budgeted", "Spent", "Remaining"); err != nil {
+		return err
+	}
+	for _, l := range r.rows() {
+		row := fmt.Sprintf("    %-30s %12.2f %12.2f %12.2f", l.Category, l.Budgeted, l.Spent, l.Remaining)
+		switch {
+		case l.Over && color:
+			row = colorRed + row + " over" + colorReset
+		case l.Over:
+			row += " over"
+		}
+		if _, err := fmt.Fprintln(w, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSV(w io.Writer, r *Report) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"Month", "Category", "Budgeted", "Spent", "Remaining", "Over"})
+	for _, l := range r.rows() {
+		_ = cw.Write([]string{r.Month, l.Category, fmt.Sprintf("%.2f", l.Budgeted), fmt.Sprintf("%.2f", l.Spent),
+			fmt.Sprintf("%.2f", l.Remaining), fmt.Sprintf("%t", l.Over)})
+	}
+	cw.Flush()
+	return cw.Error()
+}
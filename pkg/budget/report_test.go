@@ -0,0 +1,165 @@
+package budget
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	"register/api/services/sheets_service"
+	"register/pkg/models"
+)
+
+func TestMonthlyAmount(t *testing.T) {
+	september := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		entry sheets_service.BudgetEntry
+		month time.Time
+		want  float64
+	}{
+		{name: "Test monthly", entry: sheets_service.BudgetEntry{Monthly: 300}, month: september, want: 300},
+		{name: "Test weekly over 30 days", entry: sheets_service.BudgetEntry{Weekly: 70}, month: september, want: 300},
+		{name: "Test weekly over 28 days", entry: sheets_service.BudgetEntry{Weekly: 70}, month: time.Date(2026, time.February, 1, 0, 0, 0, 0, time.UTC), want: 280},
+		{name: "Test every 2 weeks", entry: sheets_service.BudgetEntry{Every2Weeks: 140}, month: september, want: 300},
+		{name: "Test twice monthly", entry: sheets_service.BudgetEntry{TwiceMonthly: 150}, month: september, want: 300},
+		{name: "Test yearly", entry: sheets_service.BudgetEntry{Yearly: 1200}, month: september, want: 100},
+		{name: "Test every period column set", entry: sheets_service.BudgetEntry{Weekly: 100, Every2Weeks: 200, TwiceMonthly: 216.67, Monthly: 433.33, Yearly: 5200}, month: september, want: 433.33},
+		{name: "Test weekly before the other periods", entry: sheets_service.BudgetEntry{Weekly: 70, Every2Weeks: 140, TwiceMonthly: 151.67}, month: september, want: 300},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MonthlyAmount(&tt.entry, tt.month); got != tt.want {
+				t.Errorf("MonthlyAmount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func testReport() *Report {
+	entries := []*sheets_service.BudgetEntry{
+		{Category: "Dining Out", Monthly: 200},
+		{Category: "Grocery", Weekly: 70},
+		{Category: "Gifts", Monthly: 50},
+	}
+	columns := []models.Column{
+		{Name: "Grocery", ColumnIndex: 12, IsCategory: true},
+		{Name: "Dining Out", ColumnIndex: 11, IsCategory: true},
+		{Name: "Gifts", ColumnIndex: 13, IsCategory: true},
+		{Name: "Travel", ColumnIndex: 14, IsCategory: true},
+		{Name: "Deposit", ColumnIndex: 5},
+	}
+	trans := []models.Transaction{
+		{Date: "09/03/26", ColumnIndex: 11, Budget: -150},
+		{Date: "09/20/26", ColumnIndex: 11, Budget: -75.5},
+		{Date: "09/21/26", ColumnIndex: 11, Budget: 10}, // refund
+		{Date: "09/05/26", ColumnIndex: 12, Budget: -120},
+		{Date: "08/31/26", ColumnIndex: 12, Budget: -500},
+		{Date: "09/10/26", ColumnIndex: 5, Deposit: 2000},
+	}
+	return NewReport(entries, columns, trans, time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC))
+}
+
+func TestNewReport(t *testing.T) {
+	want := &Report{
+		Month: "2026-09",
+		Lines: []Line{
+			{Category: "Dining Out", Budgeted: 200, Spent: 215.5, Remaining: -15.5, Over: true},
+			{Category: "Grocery", Budgeted: 300, Spent: 120, Remaining: 180},
+			{Category: "Gifts", Budgeted: 50, Remaining: 50},
+		},
+		Total: Line{Category: "Total", Budgeted: 550, Spent: 335.5, Remaining: 214.5},
+	}
+	if got := testReport(); !reflect.DeepEqual(got, want) {
+		t.Errorf("NewReport() = %+v, want %+v", got, want)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		color  bool
+		want   string
+	}{
+		{
+			name:   "Test table",
+			format: Table,
+			want: "Budget 2026-09\n" +
+				"    Category                           Budgeted        Spent    Remaining\n" +
+				"    Dining Out                           200.00       215.50       -15.50 over\n" +
+				"    Grocery                              300.00       120.00       180.00\n" +
+				"    Gifts                                 50.00         0.00        50.00\n" +
+				"    Total                                550.00       335.50       214.50\n",
+		},
+		{
+			name:   "Test colored table",
+			format: Table,
+			color:  true,
+			want: "Budget 2026-09\n" +
+				"    Category                           Budgeted        Spent    Remaining\n" +
+				"\033[31m    Dining Out                           200.00       215.50       -15.50 over\033[0m\n" +
+				"    Grocery                              300.00       120.00       180.00\n" +
+				"    Gifts                                 50.00         0.00        50.00\n" +
+				"    Total                                550.00       335.50       214.50\n",
+		},
+		{
+			name:   "Test CSV",
+			format: CSV,
+			want: "Month,Category,Budgeted,Spent,Remaining,Over\n" +
+				"2026-09,Dining Out,200.00,215.50,-15.50,true\n" +
+				"2026-09,Grocery,300.00,120.00,180.00,false\n" +
+				"2026-09,Gifts,50.00,0.00,50.00,false\n" +
+				"2026-09,Total,550.00,335.50,214.50,false\n",
+		},
+		{
+			name:   "Test JSON",
+			format: JSON,
+			want: `{
+  "month": "2026-09",
+  "lines": [
+    {
+      "category": "Dining Out",
+      "budgeted": 200,
+      "spent": 215.5,
+      "remaining": -15.5,
+      "over": true
+    },
+    {
+      "category": "Grocery",
+      "budgeted": 300,
+      "spent": 120,
+      "remaining": 180,
+      "over": false
+    },
+    {
+      "category": "Gifts",
+      "budgeted": 50,
+      "spent": 0,
+      "remaining": 50,
+      "over": false
+    }
+  ],
+  "total": {
+    "category": "Total",
+    "budgeted": 550,
+    "spent": 335.5,
+    "remaining": 214.5,
+    "over": false
+  }
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Write(&buf, testReport(), tt.format, tt.color); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Write() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,28 +1,33 @@
 package csv
 
 import (
-	//"bytes"
+	"bytes"
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"regexp"
 	"register/pkg/config"
 	"register/pkg/models"
 	"strconv"
 	"strings"
-
-	"github.com/gocarina/gocsv"
 )
 
 // ConfigOptions ...
 type ConfigOptions struct {
 	FinanceDir string
 	Banks      map[string]config.Bank
+	Mappings   map[string]*Mapping
 }
 
 // Client ...
 type Client struct {
 	FinanceDir string
 	Banks      map[string]config.Bank
+	Mappings   map[string]*Mapping // CSV descriptions by bank ID; a mapping takes precedence over the built-in readers
+	Report     *Report             // rows read, skipped and failed for every file read
 }
 
 // FidelityVisa ...
@@ -70,6 +75,7 @@ type WellsFargo struct {
 	Amount      string `csv:"AMOUNT"`
 	CheckNum    string `csv:"CHECK #"`
 	Status      string `csv:"STATUS"`
+	Line        int    `csv:"-"`
 }
 
 // Row ...
@@ -89,6 +95,8 @@ func New(o ConfigOptions) *Client {
 	return &Client{
 		FinanceDir: o.FinanceDir,
 		Banks:      o.Banks,
+		Mappings:   o.Mappings,
+		Report:     &Report{},
 	}
 }
 
@@ -97,7 +105,14 @@ func (c *Client) GetTransactions(bankIDs []string) ([]*models.Transaction, error
 	var err error
 
 	for _, bankID := range bankIDs {
-		if bankID == "wellsfargo" {
+		if m, ok := c.Mappings[bankID]; ok {
+			fmt.Printf("    %s\n", c.Banks[bankID].Name)
+			t, err = c.readMappedCSV(bankID, m)
+			if err != nil {
+				return nil, fmt.Errorf("could not read CSV file: %s", err.Error())
+			}
+			trans = append(trans, t...)
+		} else if bankID == "wellsfargo" {
 			fmt.Printf("    Wells Fargo\n")
 			t, err = c.readWellsFargoCSV()
 			if err != nil {
@@ -119,6 +134,13 @@ func (c *Client) GetTransactions(bankIDs []string) ([]*models.Transaction, error
 				return nil, fmt.Errorf("could not read CSV file: %s", err.Error())
 			}
 			trans = append(trans, t...)
+		} else if bankID == "citi" {
+			fmt.Printf("    Costco Citi Visa\n")
+			t, err = c.readCostcoCitiCSV()
+			if err != nil {
+				return nil, fmt.Errorf("could not read CSV file: %s", err.Error())
+			}
+			trans = append(trans, t...)
 		} else if bankID == "boa" {
 			fmt.Printf("    Bank of America\n")
 			trans = append(trans, t...)
@@ -134,67 +156,181 @@ func (c *Client) GetTransactions(bankIDs []string) ([]*models.Transaction, error
 	return trans, nil
 }
 
-func (c *Client) readWellsFargoCSV() ([]*models.Transaction, error) {
-	bank := c.Banks["wellsfargo"]
+func (c *Client) readMappedCSV(bankID string, m *Mapping) ([]*models.Transaction, error) {
+	bank := c.Banks[bankID]
 	csvFile := c.FinanceDir + "/" + bank.CSVFileName
-
-	fileBytes, err := readFileContents(csvFile)
-
-	// must add header row to the file if not present
-	if !doWellsFargoHeadsExist(fileBytes) {
-		err = addWellsFargoHeads(csvFile, fileBytes)
-		if err != nil {
-			return nil, fmt.Errorf("could not write csv file: %s", err.Error())
-		}
+	fileBytes, err := myReadFile(csvFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file %s: %s", csvFile, err.Error())
 	}
+	trans, err := readMappedCSV(bytes.NewReader(fileBytes), m, bankID, bank.Source, c.Report.NewFileReport(csvFile, bankID))
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %s", csvFile, err.Error())
+	}
+	return trans, nil
+}
 
-	return readWellsFargoCSVRows(csvFile, bank.ID)
+func (c *Client) readWellsFargoCSV() ([]*models.Transaction, error) {
+	bank := c.Banks["wellsfargo"]
+	csvFile := c.FinanceDir + "/" + bank.CSVFileName
+	return readWellsFargoCSVRows(csvFile, bank.ID, c.Report.NewFileReport(csvFile, bank.ID))
 }
 
 func (c *Client) readFidelityCSV() ([]*models.Transaction, error) {
 	bank := c.Banks["fidelity"]
-	trans, err := readFidelityCSVRows(c.FinanceDir+"/"+bank.CSVFileName, bank.ID)
+	csvFile := c.FinanceDir + "/" + bank.CSVFileName
+	trans, err := readFidelityCSVRows(csvFile, bank.ID, c.Report.NewFileReport(csvFile, bank.ID))
 	return trans, err
 }
 
 func (c *Client) readChaseCSV() ([]*models.Transaction, error) {
 	bank := c.Banks["chase"]
-	trans, err := readChaseCSVRows(c.FinanceDir+"/"+bank.CSVFileName, bank.ID)
+	csvFile := c.FinanceDir + "/" + bank.CSVFileName
+	trans, err := readChaseCSVRows(csvFile, bank.ID, c.Report.NewFileReport(csvFile, bank.ID))
+	return trans, err
+}
+
+func (c *Client) readCostcoCitiCSV() ([]*models.Transaction, error) {
+	bank := c.Banks["citi"]
+	csvFile := c.FinanceDir + "/" + bank.CSVFileName
+	trans, err := readCostcoCitiCSVRows(csvFile, bank.ID, c.Report.NewFileReport(csvFile, bank.ID))
 	return trans, err
 }
 
 func (c *Client) readBankOfAmericaCSV() ([]*models.Transaction, error) {
 	bank := c.Banks["boa"]
-	trans, err := readBankOfAmericaCSVRows(c.FinanceDir+"/"+bank.CSVFileName, bank.ID)
+	csvFile := c.FinanceDir + "/" + bank.CSVFileName
+	trans, err := readBankOfAmericaCSVRows(csvFile, bank.ID, c.Report.NewFileReport(csvFile, bank.ID))
 	return trans, err
 }
 
-func readWellsFargoCSVRows(csvFile string, bankId string) ([]*models.Transaction, error) {
-	csvFilePtr, err := os.Open(csvFile)
+func readWellsFargoCSVRows(csvFile string, bankId string, report *FileReport) ([]*models.Transaction, error) {
+	fileBytes, err := readFileContents(csvFile)
 	if err != nil {
 		return nil, fmt.Errorf("could not open file %s: %s", csvFile, err.Error())
 	}
-	defer csvFilePtr.Close()
+	wellsFargo, err := parseWellsFargoCSV(fileBytes, report)
+	if err != nil {
+		return nil, fmt.Errorf("could not read CSV file %s: %s", csvFile, err.Error())
+	}
+	return processWellsFargoData(wellsFargo, bankId, report), nil
+}
 
+// parseWellsFargoCSV reads a Wells Fargo download from memory. The download has no header and its columns are
+// "date","amount","*","check #","description". A header row is skipped; if it names the DESCRIPTION column the
+// columns are read by name, otherwise by position, which also covers files given a placeholder header by
+// earlier versions. Malformed rows fail in the report; without a report they fail the file.
+func parseWellsFargoCSV(fileBytes []byte, report *FileReport) ([]*WellsFargo, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(fileBytes, []byte("\ufeff"))))
+	reader.FieldsPerRecord = -1
+
+	cols := map[string]int{"DATE": 0, "AMOUNT": 1, "CHECK #": 3, "DESCRIPTION": 4, "STATUS": -1}
 	var wellsFargo []*WellsFargo
-	if err := gocsv.UnmarshalFile(csvFilePtr, &wellsFargo); err != nil {
-		return nil, fmt.Errorf("could not unmarshal CSV file %s: %s", csvFile, err.Error())
+	var malformed []string
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// the csv reader reports the line of a quoting error itself
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) && report != nil {
+				report.RowsRead++
+				report.fail(parseErr.Line, "%s", parseErr.Err.Error())
+				continue
+			}
+			malformed = append(malformed, err.Error())
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		if first && strings.EqualFold(strings.TrimSpace(record[0]), "date") {
+			named := make(map[string]int)
+			for i, h := range record {
+				named[strings.ToUpper(strings.TrimSpace(h))] = i
+			}
+			if _, ok := named["DESCRIPTION"]; ok {
+				for name := range cols {
+					if i, ok := named[name]; ok {
+						cols[name] = i
+					} else {
+						cols[name] = -1
+					}
+				}
+			}
+			continue
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+
+		if report != nil {
+			report.RowsRead++
+		}
+		wf, err := wellsFargoRow(record, cols)
+		if err != nil {
+			malformed = append(malformed, fmt.Sprintf("line %d: %s", line, err.Error()))
+			report.fail(line, "%s", err.Error())
+			continue
+		}
+		wf.Line = line
+		wellsFargo = append(wellsFargo, wf)
+	}
+	if report == nil && len(malformed) > 0 {
+		return nil, fmt.Errorf("malformed rows: %s", strings.Join(malformed, "; "))
 	}
-	return processWellsFargoData(wellsFargo, bankId), nil
+	return wellsFargo, nil
 }
 
-func processWellsFargoData(wellsFargo []*WellsFargo, bankId string) []*models.Transaction {
+func wellsFargoRow(record []string, cols map[string]int) (*WellsFargo, error) {
+	for _, name := range []string{"DATE", "AMOUNT", "DESCRIPTION"} {
+		if cols[name] < 0 || cols[name] >= len(record) {
+			return nil, fmt.Errorf("expected at least %d columns, got %d", cols[name]+1, len(record))
+		}
+	}
+	get := func(name string) string {
+		if i := cols[name]; i >= 0 && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	wf := &WellsFargo{
+		Date:        get("DATE"),
+		Amount:      get("AMOUNT"),
+		CheckNum:    get("CHECK #"),
+		Description: get("DESCRIPTION"),
+		Status:      get("STATUS"),
+	}
+	if !regexp.MustCompile(`^\d\d/\d\d/(\d\d)?\d\d$`).MatchString(wf.Date) {
+		return nil, fmt.Errorf("invalid date %q", wf.Date)
+	}
+	if _, err := strconv.ParseFloat(wf.Amount, 64); err != nil {
+		return nil, fmt.Errorf("invalid amount %q", wf.Amount)
+	}
+	return wf, nil
+}
+
+func processWellsFargoData(wellsFargo []*WellsFargo, bankId string, report *FileReport) []*models.Transaction {
 	var trans []*models.Transaction
 	for _, wf := range wellsFargo {
-		amount := 0.0
-		if s, err := strconv.ParseFloat(wf.Amount, 64); err == nil {
-			amount = s
+		// a row that does not parse is reported rather than written as a zero-dollar transaction
+		amount, err := strconv.ParseFloat(wf.Amount, 64)
+		if err != nil {
+			report.fail(wf.Line, "invalid amount %q", wf.Amount)
+			continue
+		}
+		date, err := parseDateValue(wf.Date)
+		if err != nil {
+			report.fail(wf.Line, "%s", err.Error())
+			continue
 		}
 
 		t := &models.Transaction{
-			Key:      fmt.Sprintf("%s:%s:%.2f", bankId, readDateValue(wf.Date), -amount),
+			Key:      fmt.Sprintf("%s:%s:%.2f", bankId, date, -amount),
 			Source:   "WellsFargo",
-			Date:     readDateValue(wf.Date),
+			Date:     date,
 			Amount:   amount,
 			BankName: wf.Description,
 			Budget:   amount,
@@ -208,7 +344,12 @@ func processWellsFargoData(wellsFargo []*WellsFargo, bankId string) []*models.Tr
 			t.Deposit = 0
 			t.Budget = amount
 		}
-		t = processCheck(wf.CheckNum, t)
+		// the check number column is only set for checks; the description also reads "CHECK # 110"
+		if wf.CheckNum != "" {
+			t = processCheck("CHECK # "+wf.CheckNum, t)
+		} else {
+			t = processCheck(wf.Description, t)
+		}
 		trans = append(trans, t)
 	}
 	return trans
@@ -226,29 +367,7 @@ func processCheck(name string, t *models.Transaction) *models.Transaction {
 	return t
 }
 
-func doWellsFargoHeadsExist(fileBytes []byte) bool {
-	dateHeader := ""
-	for i := range []int8{0, 1, 2, 3, 4, 5} {
-		dateHeader += string(fileBytes[i])
-	}
-	if dateHeader == "\"DATE\"" {
-		return true
-	}
-	return false
-}
-
-func addWellsFargoHeads(fileName string, fileBytes []byte) error {
-	headerBytes := []byte(`"date","amount","dummy1","dummy2","name"`)
-	headerBytes = append(headerBytes, []byte("\n")...)
-	fileBytes = append(headerBytes, fileBytes...)
-	err := os.WriteFile(fileName, fileBytes, os.ModePerm)
-	if err != nil {
-		return fmt.Errorf("could not write csv file: %s", err.Error())
-	}
-	return nil
-}
-
-func readFidelityCSVRows(csvFile string, bankId string) ([]*models.Transaction, error) {
+func readFidelityCSVRows(csvFile string, bankId string, report *FileReport) ([]*models.Transaction, error) {
 	csvFilePtr, err := os.Open(csvFile)
 	if err != nil {
 		return nil, fmt.Errorf("could not open file %s: %s", csvFile, err.Error())
@@ -256,20 +375,30 @@ func readFidelityCSVRows(csvFile string, bankId string) ([]*models.Transaction,
 	defer csvFilePtr.Close()
 
 	var fidelity []*FidelityVisa
-	if err := gocsv.UnmarshalFile(csvFilePtr, &fidelity); err != nil {
+	if err := unmarshalRows(csvFilePtr, &fidelity, report); err != nil {
 		return nil, fmt.Errorf("could not unmarshal CSV file %s: %s", csvFile, err.Error())
 	}
 
-	return processFidelityData(fidelity, bankId), nil
+	return processFidelityData(fidelity, bankId, report), nil
 }
 
-func processFidelityData(fidelity []*FidelityVisa, bankId string) []*models.Transaction {
+func processFidelityData(fidelity []*FidelityVisa, bankId string, report *FileReport) []*models.Transaction {
 	var trans []*models.Transaction
-	for _, f := range fidelity {
+	for i, f := range fidelity {
+		line := i + 2 // after the header
+		if report.hasError(line) {
+			continue
+		}
+		date, err := parseDateValue(f.Date)
+		if err != nil {
+			report.fail(line, "%s", err.Error())
+			continue
+		}
+
 		t := &models.Transaction{
 			//Key:            fmt.Sprintf("%s:%s:%.2f", bankId, readDateValue(f.Date), f.Amount),
 			Source:         "Fidelity",
-			Date:           readDateValue(f.Date),
+			Date:           date,
 			BankName:       f.Name,
 			Amount:         f.Amount,      // amount stays as is
 			CreditPurchase: -1 * f.Amount, // convert to positive
@@ -282,7 +411,7 @@ func processFidelityData(fidelity []*FidelityVisa, bankId string) []*models.Tran
 	return trans
 }
 
-func readChaseCSVRows(csvFile string, bankId string) ([]*models.Transaction, error) {
+func readChaseCSVRows(csvFile string, bankId string, report *FileReport) ([]*models.Transaction, error) {
 	csvFilePtr, err := os.Open(csvFile)
 	if err != nil {
 		return nil, fmt.Errorf("could not open file %s: %s", csvFile, err.Error())
@@ -290,25 +419,35 @@ func readChaseCSVRows(csvFile string, bankId string) ([]*models.Transaction, err
 	defer csvFilePtr.Close()
 
 	var chase []*ChaseVisa
-	if err := gocsv.UnmarshalFile(csvFilePtr, &chase); err != nil {
+	if err := unmarshalRows(csvFilePtr, &chase, report); err != nil {
 		return nil, fmt.Errorf("could not unmarshal CSV file %s: %s", csvFile, err.Error())
 	}
-	return processChaseData(chase, bankId), nil
+	return processChaseData(chase, bankId, report), nil
 }
 
-func processChaseData(chase []*ChaseVisa, bankId string) []*models.Transaction {
+func processChaseData(chase []*ChaseVisa, bankId string, report *FileReport) []*models.Transaction {
 	var trans []*models.Transaction
-	for _, c := range chase {
+	for i, c := range chase {
+		line := i + 2 // after the header
+		if report.hasError(line) {
+			continue
+		}
 		re := regexp.MustCompile(`(payment\s+thank you)`)
 		m := re.FindStringSubmatch(strings.ToLower(c.Description))
 		if len(m) > 0 {
+			report.skip(line, "card payment")
+			continue
+		}
+		date, err := parseDateValue(c.TransactionDate)
+		if err != nil {
+			report.fail(line, "%s", err.Error())
 			continue
 		}
 
 		t := &models.Transaction{
-			Key:            fmt.Sprintf("%s:%s:%.2f", bankId, readDateValue(c.TransactionDate), -c.Amount),
+			Key:            fmt.Sprintf("%s:%s:%.2f", bankId, date, -c.Amount),
 			Source:         "Chase",
-			Date:           readDateValue(c.TransactionDate),
+			Date:           date,
 			Amount:         c.Amount,
 			CreditPurchase: c.Amount,
 			CreditCard:     -1 * c.Amount,
@@ -320,7 +459,64 @@ func processChaseData(chase []*ChaseVisa, bankId string) []*models.Transaction {
 	return trans
 }
 
-func readBankOfAmericaCSVRows(csvFile string, bankId string) ([]*models.Transaction, error) {
+func readCostcoCitiCSVRows(csvFile string, bankId string, report *FileReport) ([]*models.Transaction, error) {
+	csvFilePtr, err := os.Open(csvFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not open file %s: %s", csvFile, err.Error())
+	}
+	defer csvFilePtr.Close()
+
+	var citi []*CostcoCitiVisa
+	if err := unmarshalRows(csvFilePtr, &citi, report); err != nil {
+		return nil, fmt.Errorf("could not unmarshal CSV file %s: %s", csvFile, err.Error())
+	}
+	return processCostcoCitiData(citi, bankId, report), nil
+}
+
+func processCostcoCitiData(citi []*CostcoCitiVisa, bankId string, report *FileReport) []*models.Transaction {
+	var trans []*models.Transaction
+	for i, c := range citi {
+		line := i + 2 // after the header
+		if report.hasError(line) {
+			continue
+		}
+		// pending rows change or disappear before they clear
+		if strings.EqualFold(strings.TrimSpace(c.Status), "pending") {
+			report.skip(line, "pending")
+			continue
+		}
+		// skip CC payment transaction as these will show up as checking account payments
+		re := regexp.MustCompile(`(autopay|online payment|payment\s*,?\s*thank you)`)
+		m := re.FindStringSubmatch(strings.ToLower(c.Description))
+		if len(m) > 0 {
+			report.skip(line, "card payment")
+			continue
+		}
+		date, err := parseDateValue(c.Date)
+		if err != nil {
+			report.fail(line, "%s", err.Error())
+			continue
+		}
+
+		// Debit is a purchase (positive); Credit is a refund, exported negative or positive depending on the download
+		purchase := c.Debit - math.Abs(c.Credit)
+		t := &models.Transaction{
+			Source:         "Citi",
+			Date:           date,
+			BankName:       c.Description,
+			MemberName:     strings.TrimSpace(c.MemberName),
+			Amount:         purchase,      // positive, as Plaid reports a purchase
+			CreditPurchase: purchase,      // keep positive
+			CreditCard:     purchase,      // keep positive
+			Budget:         -1 * purchase, // budget category column negative
+		}
+		t.Key = fmt.Sprintf("%s:%s:%.2f", bankId, t.Date, t.CreditCard)
+		trans = append(trans, t)
+	}
+	return trans
+}
+
+func readBankOfAmericaCSVRows(csvFile string, bankId string, report *FileReport) ([]*models.Transaction, error) {
 	csvFilePtr, err := os.Open(csvFile)
 	if err != nil {
 		return nil, fmt.Errorf("could not open file %s: %s", csvFile, err.Error())
@@ -328,27 +524,37 @@ func readBankOfAmericaCSVRows(csvFile string, bankId string) ([]*models.Transact
 	defer csvFilePtr.Close()
 
 	var boa []*BankOfAmerica
-	if err := gocsv.UnmarshalFile(csvFilePtr, &boa); err != nil {
+	if err := unmarshalRows(csvFilePtr, &boa, report); err != nil {
 		return nil, fmt.Errorf("could not unmarshal CSV file %s: %s", csvFile, err.Error())
 	}
 
-	return processBankOfAmericaData(boa, bankId), nil
+	return processBankOfAmericaData(boa, bankId, report), nil
 }
 
-func processBankOfAmericaData(boa []*BankOfAmerica, bankId string) []*models.Transaction {
+func processBankOfAmericaData(boa []*BankOfAmerica, bankId string, report *FileReport) []*models.Transaction {
 	var trans []*models.Transaction
-	for _, b := range boa {
+	for i, b := range boa {
+		line := i + 2 // after the header
+		if report.hasError(line) {
+			continue
+		}
 		// skip CC payment transaction as these will show up as checking account payments
 		re := regexp.MustCompile(`(ba electronic payment)`)
 		m := re.FindStringSubmatch(strings.ToLower(b.Payee))
 		if len(m) > 0 {
+			report.skip(line, "card payment")
+			continue
+		}
+		date, err := parseDateValue(b.PostedDate)
+		if err != nil {
+			report.fail(line, "%s", err.Error())
 			continue
 		}
 
 		t := &models.Transaction{
-			Key:        fmt.Sprintf("%s:%s:%.2f", bankId, readDateValue(b.PostedDate), b.Amount),
+			Key:        fmt.Sprintf("%s:%s:%.2f", bankId, date, b.Amount),
 			Source:     bankId,
-			Date:       readDateValue(b.PostedDate),
+			Date:       date,
 			Amount:     -b.Amount,
 			CreditCard: -b.Amount,
 			BankName:   b.Payee,
@@ -358,30 +564,30 @@ func processBankOfAmericaData(boa []*BankOfAmerica, bankId string) []*models.Tra
 	return trans
 }
 
+// readDateValue converts MM/DD/YYYY, MM/DD/YY or YYYY-MM-DD to the register's MM/DD/YY; "" if it is neither
 func readDateValue(date string) string {
-	var d string
+	d, _ := parseDateValue(date)
+	return d
+}
+
+func parseDateValue(date string) (string, error) {
 	re := regexp.MustCompile(`(\d\d)/(\d\d)/(20)?(\d\d)`)
-	m := re.FindAllStringSubmatch(date, -1)
-	if m != nil {
-		mm, _ := strconv.Atoi(m[0][1])
-		dd, _ := strconv.Atoi(m[0][2])
-		yy, _ := strconv.Atoi(m[0][4])
-		d = fmt.Sprintf("%02d/%02d/%02d", mm, dd, yy)
-	} else {
-		re := regexp.MustCompile(`(20)?(\d\d)-(\d\d)-(\d\d)`)
-		m := re.FindAllStringSubmatch(date, -1)
-		mm, _ := strconv.Atoi(m[0][3])
-		dd, _ := strconv.Atoi(m[0][4])
-		yy, _ := strconv.Atoi(m[0][2])
-		d = fmt.Sprintf("%02d/%02d/%02d", mm, dd, yy)
+	if m := re.FindStringSubmatch(date); m != nil {
+		mm, _ := strconv.Atoi(m[1])
+		dd, _ := strconv.Atoi(m[2])
+		yy, _ := strconv.Atoi(m[4])
+		return fmt.Sprintf("%02d/%02d/%02d", mm, dd, yy), nil
 	}
-	return d
+	re = regexp.MustCompile(`(20)?(\d\d)-(\d\d)-(\d\d)`)
+	if m := re.FindStringSubmatch(date); m != nil {
+		mm, _ := strconv.Atoi(m[3])
+		dd, _ := strconv.Atoi(m[4])
+		yy, _ := strconv.Atoi(m[2])
+		return fmt.Sprintf("%02d/%02d/%02d", mm, dd, yy), nil
+	}
+	return "", fmt.Errorf("invalid date %q", date)
 }
 
 func readFileContents(path string) ([]byte, error) {
-	var err error
-	if contents, err := myReadFile(path); err == nil {
-		return contents, nil
-	}
-	return nil, err
+	return myReadFile(path)
 }
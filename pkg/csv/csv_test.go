@@ -2,7 +2,6 @@ package csv
 
 import (
 	"bytes"
-	"encoding/json"
 	"io/ioutil"
 	"os"
 	"reflect"
@@ -14,7 +13,6 @@ import (
 
 const (
 	FinanceDir = "/Users/rob/Dropbox/Finances/"
-	JSONDIR    = "/Users/rob/ws/go/src/register/api/services/sheets_service/json/"
 )
 
 type FakeReadFiler struct {
@@ -47,7 +45,7 @@ func TestNew(t *testing.T) {
 	}
 }
 
-func TestClient_ReadCSVFiles(t *testing.T) {
+func TestClient_GetTransactions(t *testing.T) {
 	type fields struct {
 		FinanceDir string
 		Banks      map[string]cfg.Bank
@@ -55,6 +53,7 @@ func TestClient_ReadCSVFiles(t *testing.T) {
 	tests := []struct {
 		name    string
 		fields  fields
+		bankIDs []string
 		want    []*models.Transaction
 		wantErr bool
 	}{
@@ -66,73 +65,45 @@ func TestClient_ReadCSVFiles(t *testing.T) {
 				FinanceDir: tt.fields.FinanceDir,
 				Banks:      tt.fields.Banks,
 			}
-			got, err := c.ReadCSVFiles()
+			got, err := c.GetTransactions(tt.bankIDs)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("ReadCSVFiles() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("GetTransactions() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("ReadCSVFiles() got = %v, want %v", got, tt.want)
+				t.Errorf("GetTransactions() got = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
 func TestClient_readWellsFargoCSV(t *testing.T) {
-	config, err := cfg.ReadConfig("../../config/config.json")
-	if err != nil {
-		t.Fatalf(err.Error())
-	}
-
-	j, err := os.ReadFile(JSONDIR + "wells_fargo_transactions.json")
-	checkTestingError(t, err)
-	var trans []*models.Transaction
-	err = json.Unmarshal(j, &trans)
-	checkTestingError(t, err)
-
 	csvFile := `"date","amount","dummy1","dummy2","name"
 "07/17/2023","-14.01","*","","AMERICAN STRATEG 8662748765 1A55EB86FCFE ROBERT CALLAHAN"
 "07/17/2023","-25.00","*","110","CHECK # 110"
 "07/13/2023","5086.51","*","","MSPBNA ACH TRNSFR 230712 84178376906 45469742"
-"07/07/2023","-2.55","*","","BILL PAY MasterCard ON-LINE xxxxxxxxxxxx5189 ON 07-07"
-"07/05/2023","-105.00","*","","SHENANDOAH VALLE UTILITY 230704 3957509 ROBERT T *CALLAHAN"
-"07/03/2023","-75.53","*","","GLO FIBER BILLPAY 230702 GLO FIBER ROBERT CALLAHAN"
-"07/03/2023","-1350.00","*","","Olive Tree Prope WEB PMTS 070323 YP2MT7 Robert Callahan"
 `
 	fake := FakeReadFiler{Str: csvFile}
 	myReadFile = fake.ReadFile
+	defer func() { myReadFile = os.ReadFile }()
 
-	type fields struct {
-		FinanceDir string
-		Banks      map[string]cfg.Bank
+	banks := map[string]cfg.Bank{"wellsfargo": {ID: "wellsfargo", CSVFileName: "wellsfargo.csv"}}
+	want := []*models.Transaction{
+		{Key: "wellsfargo:07/17/23:14.01", Source: "WellsFargo", Date: "07/17/23", Amount: -14.01,
+			BankName: "AMERICAN STRATEG 8662748765 1A55EB86FCFE ROBERT CALLAHAN", Deposit: 14.01, Budget: 14.01},
+		{Key: "wellsfargo:07/17/23:25.00", Source: "110", Date: "07/17/23", Amount: -25, Name: "CHECK",
+			BankName: "CHECK", Deposit: 25, Budget: 25, IsCheck: true},
+		{Key: "wellsfargo:07/13/23:-5086.51", Source: "WellsFargo", Date: "07/13/23", Amount: 5086.51,
+			BankName: "MSPBNA ACH TRNSFR 230712 84178376906 45469742", Withdrawal: 5086.51, Budget: 5086.51},
 	}
-	tests := []struct {
-		name    string
-		fields  fields
-		want    []*models.Transaction
-		wantErr bool
-	}{
-		{
-			name:    "Test read Wells Fargo CSV file",
-			fields:  fields{FinanceDir: FinanceDir, Banks: config.Banks},
-			want:    trans,
-			wantErr: false,
-		},
+
+	c := New(ConfigOptions{FinanceDir: FinanceDir, Banks: banks})
+	got, err := c.readWellsFargoCSV()
+	if err != nil {
+		t.Fatalf("readWellsFargoCSV() error = %v", err)
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			c := &Client{
-				FinanceDir: tt.fields.FinanceDir,
-				Banks:      tt.fields.Banks,
-			}
-			for _, tt := range tests {
-				t.Run(tt.name, func(t *testing.T) {
-					if got, _ := c.readWellsFargoCSV(); !reflect.DeepEqual(got, tt.want) {
-						t.Errorf("readWellsFargoCSV() = %v, want %v", got, tt.want)
-					}
-				})
-			}
-		})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readWellsFargoCSV() = %+v, want %+v", got, want)
 	}
 }
 
@@ -244,7 +215,7 @@ func Test_readWellsFargoCSVRows(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := readWellsFargoCSVRows(tt.args.csvFile, tt.args.bankId)
+			got, err := readWellsFargoCSVRows(tt.args.csvFile, tt.args.bankId, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("readWellsFargoCSVRows() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -270,7 +241,7 @@ func Test_processWellsFargoData(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := processWellsFargoData(tt.args.wellsFargo, tt.args.bankId); !reflect.DeepEqual(got, tt.want) {
+			if got := processWellsFargoData(tt.args.wellsFargo, tt.args.bankId, nil); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("processWellsFargoData() = %v, want %v", got, tt.want)
 			}
 		})
@@ -298,44 +269,108 @@ func Test_processCheck(t *testing.T) {
 	}
 }
 
-func Test_doWellsFargoHeadsExist(t *testing.T) {
-	type args struct {
-		fileBytes []byte
-	}
+func Test_parseWellsFargoCSV(t *testing.T) {
 	tests := []struct {
-		name string
-		args args
-		want bool
+		name    string
+		csv     string
+		want    []*WellsFargo
+		wantErr string
 	}{
-		// TODO: Add test cases.
+		{
+			name: "Test headerless by column position",
+			csv: `"07/17/2023","-14.01","*","","AMERICAN STRATEG 8662748765"
+"07/17/2023","-25.00","*","110","CHECK # 110"
+
+"07/13/2023","5086.51","*","","MSPBNA ACH TRNSFR"
+`,
+			want: []*WellsFargo{
+				{Date: "07/17/2023", Amount: "-14.01", Description: "AMERICAN STRATEG 8662748765", Line: 1},
+				{Date: "07/17/2023", Amount: "-25.00", CheckNum: "110", Description: "CHECK # 110", Line: 2},
+				{Date: "07/13/2023", Amount: "5086.51", Description: "MSPBNA ACH TRNSFR", Line: 4},
+			},
+		},
+		{
+			name: "Test placeholder header written by earlier versions",
+			csv: `"date","amount","dummy1","dummy2","name"
+"07/17/2023","-14.01","*","","AMERICAN STRATEG 8662748765"
+`,
+			want: []*WellsFargo{{Date: "07/17/2023", Amount: "-14.01", Description: "AMERICAN STRATEG 8662748765", Line: 2}},
+		},
+		{
+			name: "Test named header",
+			csv: `"DATE","DESCRIPTION","AMOUNT","CHECK #","STATUS"
+"07/17/2023","CHECK # 110","-25.00","110","Posted"
+`,
+			want: []*WellsFargo{{Date: "07/17/2023", Amount: "-25.00", CheckNum: "110", Description: "CHECK # 110", Status: "Posted", Line: 2}},
+		},
+		{
+			name: "Test malformed rows are reported with line numbers",
+			csv: `"07/17/2023","-14.01","*","","AMERICAN STRATEG 8662748765"
+"07/17/2023","-14.01"
+"yesterday","-14.01","*","","GLO FIBER"
+"07/17/2023","lots","*","","GLO FIBER"
+`,
+			wantErr: `malformed rows: line 2: expected at least 5 columns, got 2; line 3: invalid date "yesterday"; line 4: invalid amount "lots"`,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := doWellsFargoHeadsExist(tt.args.fileBytes); got != tt.want {
-				t.Errorf("doWellsFargoHeadsExist() = %v, want %v", got, tt.want)
+			got, err := parseWellsFargoCSV([]byte(tt.csv), nil)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("parseWellsFargoCSV() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseWellsFargoCSV() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseWellsFargoCSV() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
-func Test_addWellsFargoHeads(t *testing.T) {
-	type args struct {
-		fileName  string
-		fileBytes []byte
+func Test_parseWellsFargoCSV_report(t *testing.T) {
+	data := `"07/17/2023","-14.01","*","","AMERICAN STRATEG 8662748765"
+"07/17/2023","-14.01"
+"07/18/2023","lots","*","","GLO FIBER"
+"07/19/2023","-75.53","*","","GLO FIBER"
+`
+	report := &FileReport{}
+	rows, err := parseWellsFargoCSV([]byte(data), report)
+	checkTestingError(t, err)
+	trans := processWellsFargoData(rows, "wellsfargo", report)
+
+	// no zero-dollar rows: the bad rows are reported and left out
+	if len(trans) != 2 || trans[0].Amount != -14.01 || trans[1].Amount != -75.53 {
+		t.Errorf("processWellsFargoData() = %v", trans)
 	}
-	tests := []struct {
-		name    string
-		args    args
-		wantErr bool
-	}{
-		// TODO: Add test cases.
+	wantErrors := []RowIssue{
+		{Line: 2, Reason: "expected at least 5 columns, got 2"},
+		{Line: 3, Reason: `invalid amount "lots"`},
 	}
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			if err := addWellsFargoHeads(tt.args.fileName, tt.args.fileBytes); (err != nil) != tt.wantErr {
-				t.Errorf("addWellsFargoHeads() error = %v, wantErr %v", err, tt.wantErr)
-			}
-		})
+	if report.RowsRead != 4 || !reflect.DeepEqual(report.Errors, wantErrors) {
+		t.Errorf("report = %+v, want errors %v", report, wantErrors)
+	}
+}
+
+func Test_readWellsFargoCSVRows_doesNotModifyFile(t *testing.T) {
+	myReadFile = os.ReadFile
+	csvFile := t.TempDir() + "/wf.csv"
+	contents := `"07/17/2023","-25.00","*","110","CHECK # 110"` + "\n"
+	checkTestingError(t, os.WriteFile(csvFile, []byte(contents), 0600))
+
+	got, err := readWellsFargoCSVRows(csvFile, "wellsfargo", &FileReport{})
+	checkTestingError(t, err)
+	if len(got) != 1 || !got[0].IsCheck || got[0].Source != "110" {
+		t.Errorf("readWellsFargoCSVRows() = %+v", got)
+	}
+	after, err := os.ReadFile(csvFile)
+	checkTestingError(t, err)
+	if string(after) != contents {
+		t.Errorf("readWellsFargoCSVRows() modified the file: %q", after)
 	}
 }
 
@@ -354,7 +389,7 @@ func Test_readFidelityCSVRows(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := readFidelityCSVRows(tt.args.csvFile, tt.args.bankId)
+			got, err := readFidelityCSVRows(tt.args.csvFile, tt.args.bankId, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("readFidelityCSVRows() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -380,7 +415,7 @@ func Test_processFidelityData(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := processFidelityData(tt.args.fidelity, tt.args.bankId); !reflect.DeepEqual(got, tt.want) {
+			if got := processFidelityData(tt.args.fidelity, tt.args.bankId, nil); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("processFidelityData() = %v, want %v", got, tt.want)
 			}
 		})
@@ -402,7 +437,7 @@ func Test_readChaseCSVRows(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := readChaseCSVRows(tt.args.csvFile, tt.args.bankId)
+			got, err := readChaseCSVRows(tt.args.csvFile, tt.args.bankId, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("readChaseCSVRows() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -428,7 +463,7 @@ func Test_processChaseData(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := processChaseData(tt.args.chase, tt.args.bankId); !reflect.DeepEqual(got, tt.want) {
+			if got := processChaseData(tt.args.chase, tt.args.bankId, nil); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("processChaseData() = %v, want %v", got, tt.want)
 			}
 		})
@@ -450,7 +485,7 @@ func Test_readBankOfAmericaCSVRows(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := readBankOfAmericaCSVRows(tt.args.csvFile, tt.args.bankId)
+			got, err := readBankOfAmericaCSVRows(tt.args.csvFile, tt.args.bankId, nil)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("readBankOfAmericaCSVRows() error = %v, wantErr %v", err, tt.wantErr)
 				return
@@ -476,13 +511,83 @@ func Test_processBankOfAmericaData(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := processBankOfAmericaData(tt.args.boa, tt.args.bankId); !reflect.DeepEqual(got, tt.want) {
+			if got := processBankOfAmericaData(tt.args.boa, tt.args.bankId, nil); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("processBankOfAmericaData() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
 
+func Test_processCostcoCitiData(t *testing.T) {
+	type args struct {
+		citi   []*CostcoCitiVisa
+		bankId string
+	}
+	tests := []struct {
+		name        string
+		args        args
+		want        []*models.Transaction
+		wantSkipped []RowIssue
+	}{
+		{
+			name: "Test Costco Citi debit, credit, pending and payment rows",
+			args: args{
+				citi: []*CostcoCitiVisa{
+					{Status: "Cleared", Date: "01/05/2026", Description: "COSTCO WHSE #0123", Debit: 150.25, MemberName: "ROB CALLAHAN"},
+					{Status: "Cleared", Date: "01/06/2026", Description: "AMAZON RETURN", Credit: -20, MemberName: "JANE CALLAHAN"},
+					{Status: "Cleared", Date: "01/07/2026", Description: "TARGET REFUND", Credit: 10, MemberName: "JANE CALLAHAN"},
+					{Status: "Pending", Date: "01/08/2026", Description: "SHELL OIL", Debit: 40},
+					{Status: "Cleared", Date: "01/09/2026", Description: "AUTOPAY 999990000012345RAUTOPAY AUTO-PMT", Credit: -500},
+					{Status: "Cleared", Date: "01/10/2026", Description: "ONLINE PAYMENT, THANK YOU", Credit: -100},
+				},
+				bankId: "citi",
+			},
+			want: []*models.Transaction{
+				{Key: "citi:01/05/26:150.25", Source: "Citi", Date: "01/05/26", BankName: "COSTCO WHSE #0123", MemberName: "ROB CALLAHAN", Amount: 150.25, CreditPurchase: 150.25, CreditCard: 150.25, Budget: -150.25},
+				{Key: "citi:01/06/26:-20.00", Source: "Citi", Date: "01/06/26", BankName: "AMAZON RETURN", MemberName: "JANE CALLAHAN", Amount: -20, CreditPurchase: -20, CreditCard: -20, Budget: 20},
+				{Key: "citi:01/07/26:-10.00", Source: "Citi", Date: "01/07/26", BankName: "TARGET REFUND", MemberName: "JANE CALLAHAN", Amount: -10, CreditPurchase: -10, CreditCard: -10, Budget: 10},
+			},
+			wantSkipped: []RowIssue{{Line: 5, Reason: "pending"}, {Line: 6, Reason: "card payment"}, {Line: 7, Reason: "card payment"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := &FileReport{}
+			if got := processCostcoCitiData(tt.args.citi, tt.args.bankId, report); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("processCostcoCitiData() = %v, want %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(report.Skipped, tt.wantSkipped) {
+				t.Errorf("processCostcoCitiData() skipped = %v, want %v", report.Skipped, tt.wantSkipped)
+			}
+		})
+	}
+}
+
+func Test_readCostcoCitiCSVRows(t *testing.T) {
+	csvFile := t.TempDir() + "/citi.csv"
+	err := os.WriteFile(csvFile, []byte(`Status,Date,Description,Debit,Credit,Member Name
+Cleared,01/05/2026,COSTCO WHSE #0123,150.25,,ROB CALLAHAN
+Pending,01/08/2026,SHELL OIL,40.00,,ROB CALLAHAN
+Cleared,01/09/2026,AUTOPAY 999990000012345RAUTOPAY AUTO-PMT,,-500.00,ROB CALLAHAN
+Cleared,01/10/2026,SHELL OIL,forty,,ROB CALLAHAN
+Cleared,1/10,SHELL OIL,40.00,,ROB CALLAHAN
+`), 0600)
+	checkTestingError(t, err)
+
+	report := &FileReport{}
+	got, err := readCostcoCitiCSVRows(csvFile, "citi", report)
+	checkTestingError(t, err)
+	if report.RowsRead != 5 || len(report.Skipped) != 2 || len(report.Errors) != 2 || report.Errors[0].Line != 5 || report.Errors[1].Reason != `invalid date "1/10"` {
+		t.Errorf("readCostcoCitiCSVRows() report = %+v", report)
+	}
+	want := []*models.Transaction{
+		{Key: "citi:01/05/26:150.25", Source: "Citi", Date: "01/05/26", BankName: "COSTCO WHSE #0123", MemberName: "ROB CALLAHAN", Amount: 150.25, CreditPurchase: 150.25, CreditCard: 150.25, Budget: -150.25},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("readCostcoCitiCSVRows() = %v, want %v", got, want)
+	}
+}
+
 func Test_readDateValue(t *testing.T) {
 	type args struct {
 		date string
@@ -502,6 +607,11 @@ func Test_readDateValue(t *testing.T) {
 			args: args{date: "2023-01-02"},
 			want: "01/02/23",
 		},
+		{
+			name: "Test invalid date values",
+			args: args{date: "Jan 2"},
+			want: "",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,308 @@
+package csv
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"register/pkg/models"
+)
+
+const (
+	// ArchiveDirName is the folder under FinanceDir that ingested files are moved to
+	ArchiveDirName = "archive"
+	// ManifestFileName records the ingested files in the archive folder
+	ManifestFileName = "manifest.json"
+)
+
+// IngestedFile is a manifest entry for an ingested statement download
+type IngestedFile struct {
+	File         string    `json:"file"`
+	ArchivedAs   string    `json:"archivedAs"`
+	BankID       string    `json:"bankID"`
+	SHA256       string    `json:"sha256"`
+	Transactions int       `json:"transactions"`
+	ImportedAt   time.Time `json:"importedAt"`
+	FailedRows   int       `json:"-"` // rows that could not be read, which keep the file out of the archive
+}
+
+// Ingestion is the result of reading a directory of statement downloads
+type Ingestion struct {
+	Transactions []*models.Transaction
+	Files        []*IngestedFile
+	Skipped      []string // files already in the manifest
+	Unknown      []string // files whose bank could not be detected
+	Kept         []string // files left in place by Archive because rows failed
+}
+
+// headerSignatures are the columns that identify each bank's CSV download
+var headerSignatures = []struct {
+	bankID  string
+	columns []string
+}{
+	{"chase", []string{"transaction date", "post date", "description", "category", "type", "amount"}},
+	{"boa", []string{"posted date", "reference number", "payee", "address", "amount"}},
+	{"citi", []string{"status", "date", "description", "debit", "credit"}},
+	{"fidelity", []string{"date", "transaction", "name", "memo", "amount"}},
+	{"wellsfargo", []string{"date", "description", "amount", "check #"}},
+}
+
+// wellsFargoRowRe matches the first row of a headerless Wells Fargo download: "date","amount","*","check #","description"
+var wellsFargoRowRe = regexp.MustCompile(`^"\d\d/\d\d/\d{4}","-?[\d.]+","\*","\d*","`)
+
+// Ingest reads every CSV file matched by pattern, a directory or glob relative to FinanceDir, detects each
+// file's bank and merges the transactions. Files already in the archive manifest are skipped. A transaction
+// in overlapping downloads is kept once; repeats within one file are kept.
+func (c *Client) Ingest(pattern string) (*Ingestion, error) {
+	files, err := c.matchFiles(pattern)
+	if err != nil {
+		return nil, err
+	}
+	manifest, err := c.readManifest()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for _, f := range manifest {
+		seen[f.SHA256] = true
+	}
+
+	ing := &Ingestion{}
+	emitted := make(map[string]int)
+	for _, file := range files {
+		data, err := myReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("could not read file %s: %s", file, err.Error())
+		}
+		sum := sha256.Sum256(data)
+		hash := hex.EncodeToString(sum[:])
+		if seen[hash] {
+			ing.Skipped = append(ing.Skipped, file)
+			continue
+		}
+		seen[hash] = true
+
+		bankID, ok := c.DetectBank(data)
+		if !ok {
+			ing.Unknown = append(ing.Unknown, file)
+			continue
+		}
+		report := c.Report.NewFileReport(file, bankID)
+		trans, err := c.parseBankCSV(bankID, data, report)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s file %s: %s", bankID, file, err.Error())
+		}
+
+		ing.Transactions = append(ing.Transactions, mergeTransactions(emitted, trans)...)
+		ing.Files = append(ing.Files, &IngestedFile{
+			File:         file,
+			BankID:       bankID,
+			SHA256:       hash,
+			Transactions: len(trans),
+			FailedRows:   len(report.Errors),
+		})
+	}
+	return ing, nil
+}
+
+// Archive moves the ingested files to the archive folder and adds them to its manifest. A file with rows
+// that could not be read is left in place and out of the manifest so that it is read again once fixed.
+func (c *Client) Archive(ing *Ingestion) error {
+	if len(ing.Files) == 0 {
+		return nil
+	}
+	dir := c.archiveDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("could not create archive folder %s: %s", dir, err.Error())
+	}
+	manifest, err := c.readManifest()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, f := range ing.Files {
+		if f.FailedRows > 0 {
+			ing.Kept = append(ing.Kept, f.File)
+			continue
+		}
+		archived := filepath.Join(dir, now.Format("20060102-150405")+"-"+filepath.Base(f.File))
+		if err := os.Rename(f.File, archived); err != nil {
+			return fmt.Errorf("could not archive %s: %s", f.File, err.Error())
+		}
+		f.ArchivedAs = archived
+		f.ImportedAt = now
+		manifest = append(manifest, f)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestFileName), data, 0644); err != nil {
+		return fmt.Errorf("could not write manifest: %s", err.Error())
+	}
+	return nil
+}
+
+// DetectBank identifies a CSV download by its header row, or by its first row for headerless Wells Fargo
+// files. Mappings with a header are matched after the built-in banks.
+func (c *Client) DetectBank(data []byte) (string, bool) {
+	firstLine := string(data)
+	if i := strings.IndexAny(firstLine, "\r\n"); i >= 0 {
+		firstLine = firstLine[:i]
+	}
+	firstLine = strings.TrimPrefix(firstLine, "\ufeff")
+	if wellsFargoRowRe.MatchString(firstLine) {
+		return "wellsfargo", true
+	}
+
+	header, err := csv.NewReader(strings.NewReader(firstLine)).Read()
+	if err != nil {
+		return "", false
+	}
+	columns := make(map[string]bool, len(header))
+	for _, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(h))] = true
+	}
+
+	for _, sig := range headerSignatures {
+		if hasColumns(columns, sig.columns) {
+			return sig.bankID, true
+		}
+	}
+
+	bankIDs := make([]string, 0, len(c.Mappings))
+	for id := range c.Mappings {
+		bankIDs = append(bankIDs, id)
+	}
+	sort.Strings(bankIDs)
+	for _, id := range bankIDs {
+		m := c.Mappings[id]
+		if m.HasHeader && hasColumns(columns, m.namedColumns()) {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+func (c *Client) parseBankCSV(bankID string, data []byte, report *FileReport) ([]*models.Transaction, error) {
+	if m, ok := c.Mappings[bankID]; ok {
+		return readMappedCSV(bytes.NewReader(data), m, bankID, c.Banks[bankID].Source, report)
+	}
+
+	switch bankID {
+	case "wellsfargo":
+		rows, err := parseWellsFargoCSV(data, report)
+		if err != nil {
+			return nil, err
+		}
+		return processWellsFargoData(rows, bankID, report), nil
+	case "fidelity":
+		var rows []*FidelityVisa
+		if err := unmarshalRows(bytes.NewReader(data), &rows, report); err != nil {
+			return nil, err
+		}
+		return processFidelityData(rows, bankID, report), nil
+	case "chase":
+		var rows []*ChaseVisa
+		if err := unmarshalRows(bytes.NewReader(data), &rows, report); err != nil {
+			return nil, err
+		}
+		return processChaseData(rows, bankID, report), nil
+	case "citi":
+		var rows []*CostcoCitiVisa
+		if err := unmarshalRows(bytes.NewReader(data), &rows, report); err != nil {
+			return nil, err
+		}
+		return processCostcoCitiData(rows, bankID, report), nil
+	case "boa":
+		var rows []*BankOfAmerica
+		if err := unmarshalRows(bytes.NewReader(data), &rows, report); err != nil {
+			return nil, err
+		}
+		return processBankOfAmericaData(rows, bankID, report), nil
+	}
+	return nil, fmt.Errorf("unknown bankID: %s", bankID)
+}
+
+func (c *Client) matchFiles(pattern string) ([]string, error) {
+	if !filepath.IsAbs(pattern) {
+		pattern = filepath.Join(c.FinanceDir, pattern)
+	}
+	if info, err := os.Stat(pattern); err == nil && info.IsDir() {
+		pattern = filepath.Join(pattern, "*.csv")
+	}
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("bad file pattern %s: %s", pattern, err.Error())
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func (c *Client) archiveDir() string {
+	return filepath.Join(c.FinanceDir, ArchiveDirName)
+}
+
+func (c *Client) readManifest() ([]*IngestedFile, error) {
+	fileName := filepath.Join(c.archiveDir(), ManifestFileName)
+	data, err := os.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read manifest %s: %s", fileName, err.Error())
+	}
+	var manifest []*IngestedFile
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("could not parse manifest %s: %s", fileName, err.Error())
+	}
+	return manifest, nil
+}
+
+// mergeTransactions returns the transactions of one file not already emitted from an earlier, overlapping file.
+// emitted counts each key's occurrences so far so that same-day, same-amount repeats within a file are kept.
+func mergeTransactions(emitted map[string]int, trans []*models.Transaction) []*models.Transaction {
+	var merged []*models.Transaction
+	inFile := make(map[string]int)
+	for _, t := range trans {
+		inFile[t.Key]++
+		if inFile[t.Key] > emitted[t.Key] {
+			emitted[t.Key] = inFile[t.Key]
+			merged = append(merged, t)
+		}
+	}
+	return merged
+}
+
+// namedColumns are the mapping's columns given by header name rather than index
+func (m *Mapping) namedColumns() []string {
+	var names []string
+	for _, name := range []string{m.Date, m.Description, m.Amount, m.Debit, m.Credit, m.CheckNum} {
+		if name == "" || regexp.MustCompile(`^\d+$`).MatchString(name) {
+			continue
+		}
+		names = append(names, strings.ToLower(name))
+	}
+	return names
+}
+
+func hasColumns(columns map[string]bool, want []string) bool {
+	for _, w := range want {
+		if !columns[w] {
+			return false
+		}
+	}
+	return len(want) > 0
+}
@@ -0,0 +1,120 @@
+package csv
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"register/pkg/models"
+)
+
+const (
+	chaseJanuary = `Transaction Date,Post Date,Description,Category,Type,Amount
+01/03/2026,01/04/2026,STARBUCKS,Food & Drink,Sale,-5.25
+01/03/2026,01/04/2026,STARBUCKS,Food & Drink,Sale,-5.25
+01/05/2026,01/06/2026,KROGER,Groceries,Sale,-45.10
+`
+	// overlaps chaseJanuary on 01/03 and 01/05
+	chaseOverlap = `Transaction Date,Post Date,Description,Category,Type,Amount
+01/03/2026,01/04/2026,STARBUCKS,Food & Drink,Sale,-5.25
+01/03/2026,01/04/2026,STARBUCKS,Food & Drink,Sale,-5.25
+01/05/2026,01/06/2026,KROGER,Groceries,Sale,-45.10
+01/07/2026,01/08/2026,NETFLIX,Entertainment,Sale,-12.00
+`
+	citiJanuary = "\ufeffStatus,Date,Description,Debit,Credit,Member Name\nCleared,01/05/2026,COSTCO WHSE,150.25,,ROB\n"
+)
+
+func TestClient_DetectBank(t *testing.T) {
+	c := New(ConfigOptions{Mappings: map[string]*Mapping{
+		"credit_union": {HasHeader: true, Date: "Posting Date", Description: "Payee", Amount: "Amount"},
+		"headerless":   {Date: "0", Description: "1", Amount: "2"},
+	}})
+	tests := []struct {
+		name   string
+		data   string
+		want   string
+		wantOK bool
+	}{
+		{name: "Test detect Chase", data: chaseJanuary, want: "chase", wantOK: true},
+		{name: "Test detect Citi with BOM", data: citiJanuary, want: "citi", wantOK: true},
+		{name: "Test detect Fidelity", data: "Date,Transaction,Name,Memo,Amount\n", want: "fidelity", wantOK: true},
+		{name: "Test detect Bank of America", data: "Posted Date,Reference Number,Payee,Address,Amount\r\n", want: "boa", wantOK: true},
+		{name: "Test detect headerless Wells Fargo", data: `"07/17/2023","-25.00","*","110","CHECK # 110"` + "\n", want: "wellsfargo", wantOK: true},
+		{name: "Test detect mapping", data: "Posting Date,Payee,Amount,Balance\n", want: "credit_union", wantOK: true},
+		{name: "Test unknown header", data: "When,What,How Much\n", wantOK: false},
+		{name: "Test empty file", data: "", wantOK: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := c.DetectBank([]byte(tt.data))
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("DetectBank() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func Test_mergeTransactions(t *testing.T) {
+	a := &models.Transaction{Key: "chase:01/03/26:5.25"}
+	b := &models.Transaction{Key: "chase:01/05/26:45.10"}
+	c := &models.Transaction{Key: "chase:01/07/26:12.00"}
+
+	emitted := make(map[string]int)
+	first := mergeTransactions(emitted, []*models.Transaction{a, a, b})
+	second := mergeTransactions(emitted, []*models.Transaction{a, a, a, b, c})
+	if len(first) != 3 {
+		t.Errorf("mergeTransactions() first file = %d, want 3", len(first))
+	}
+	// one more STARBUCKS than the first file had, and the new NETFLIX
+	if len(second) != 2 || second[0] != a || second[1] != c {
+		t.Errorf("mergeTransactions() second file = %v", second)
+	}
+}
+
+func TestClient_IngestAndArchive(t *testing.T) {
+	myReadFile = os.ReadFile
+	dir := t.TempDir()
+	downloads := filepath.Join(dir, "downloads")
+	checkTestingError(t, os.Mkdir(downloads, 0755))
+	for name, data := range map[string]string{
+		"chase-1.csv": chaseJanuary,
+		"chase-2.csv": chaseOverlap,
+		"citi.csv":    citiJanuary,
+		"notes.csv":   "When,What\n",
+		"boa.csv":     "Posted Date,Reference Number,Payee,Address,Amount\n01/09/2026,123,SAFEWAY,,-20.00\nsoon,124,SAFEWAY,,-30.00\n",
+	} {
+		checkTestingError(t, os.WriteFile(filepath.Join(downloads, name), []byte(data), 0644))
+	}
+
+	c := New(ConfigOptions{FinanceDir: dir})
+	ing, err := c.Ingest("downloads")
+	checkTestingError(t, err)
+	if len(ing.Transactions) != 6 || len(ing.Files) != 4 || len(ing.Unknown) != 1 {
+		t.Fatalf("Ingest() = %d transactions, %d files, %d unknown", len(ing.Transactions), len(ing.Files), len(ing.Unknown))
+	}
+
+	checkTestingError(t, c.Archive(ing))
+	if _, err := os.Stat(filepath.Join(downloads, "chase-1.csv")); !os.IsNotExist(err) {
+		t.Errorf("Archive() left chase-1.csv in place")
+	}
+	// the file with a failed row stays to be read again
+	if _, err := os.Stat(filepath.Join(downloads, "boa.csv")); err != nil || len(ing.Kept) != 1 {
+		t.Errorf("Archive() kept %v, want boa.csv left in place", ing.Kept)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, ArchiveDirName, ManifestFileName))
+	checkTestingError(t, err)
+	var manifest []*IngestedFile
+	checkTestingError(t, json.Unmarshal(data, &manifest))
+	if len(manifest) != 3 || manifest[0].BankID != "chase" || manifest[0].ArchivedAs == "" {
+		t.Errorf("Archive() manifest = %s", data)
+	}
+
+	// the same download again is skipped by its hash
+	checkTestingError(t, os.WriteFile(filepath.Join(downloads, "chase-copy.csv"), []byte(chaseJanuary), 0644))
+	ing, err = c.Ingest(filepath.Join(downloads, "*.csv"))
+	checkTestingError(t, err)
+	if len(ing.Transactions) != 1 || len(ing.Files) != 1 || len(ing.Skipped) != 1 {
+		t.Errorf("Ingest() again = %d transactions, skipped %v", len(ing.Transactions), ing.Skipped)
+	}
+}
@@ -0,0 +1,291 @@
+package csv
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"register/pkg/models"
+	"register/pkg/statement"
+)
+
+// Mapping describes a bank's CSV export so it can be read without a Go struct per bank. Columns are header
+// names, or 0-based indexes when the file has no header row.
+type Mapping struct {
+	Source        string   `json:"source"`        // transaction Source; defaults to the bank's Source
+	HasHeader     bool     `json:"hasHeader"`     // the first row is a header
+	IsCreditCard  bool     `json:"isCreditCard"`  // fill the credit card columns instead of Withdrawal/Deposit
+	Date          string   `json:"date"`          // date column
+	DateLayout    string   `json:"dateLayout"`    // Go time layout, eg. 01/02/2006; default MM/DD/YY(YY) or YYYY-MM-DD
+	Description   string   `json:"description"`   // description column
+	Amount        string   `json:"amount"`        // signed amount column, negative for money out
+	FlipSign      bool     `json:"flipSign"`      // the amount column is positive for money out
+	Debit         string   `json:"debit"`         // money out column, used when there is no amount column
+	Credit        string   `json:"credit"`        // money in column, used when there is no amount column
+	CheckNum      string   `json:"checkNum"`      // optional check number column; CheckNumRegex applies to it
+	CheckNumRegex string   `json:"checkNumRegex"` // first group is the check number, matched against CheckNum or Description
+	SkipRegexes   []string `json:"skipRegexes"`   // rows whose description matches any of these are skipped
+}
+
+// mappedColumns are the resolved 0-based indexes of a mapping's columns; -1 if unused
+type mappedColumns struct {
+	date, description, amount, debit, credit, checkNum int
+}
+
+// LoadMappings reads the CSV mappings by bank ID from a JSON file
+func LoadMappings(fileName string) (map[string]*Mapping, error) {
+	data, err := myReadFile(fileName)
+	if err != nil {
+		return nil, fmt.Errorf("could not read CSV mappings file %s: %s", fileName, err.Error())
+	}
+	var mappings map[string]*Mapping
+	if err := json.Unmarshal(data, &mappings); err != nil {
+		return nil, fmt.Errorf("could not parse CSV mappings file %s: %s", fileName, err.Error())
+	}
+	for bankID, m := range mappings {
+		if err := m.validate(); err != nil {
+			return nil, fmt.Errorf("invalid CSV mapping for %s: %s", bankID, err.Error())
+		}
+	}
+	return mappings, nil
+}
+
+func (m *Mapping) validate() error {
+	if m.Date == "" || m.Description == "" {
+		return fmt.Errorf("date and description columns are required")
+	}
+	if m.Amount == "" && m.Debit == "" && m.Credit == "" {
+		return fmt.Errorf("an amount column or debit/credit columns are required")
+	}
+	if m.CheckNumRegex != "" {
+		if _, err := regexp.Compile(m.CheckNumRegex); err != nil {
+			return fmt.Errorf("checkNumRegex: %s", err.Error())
+		}
+	}
+	for _, re := range m.SkipRegexes {
+		if _, err := regexp.Compile(re); err != nil {
+			return fmt.Errorf("skipRegexes: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// readMappedCSV builds transactions from a CSV file described by a mapping. A mapping that does not fit the
+// file is an error; a row that does not fit fails in the report, or fails the file without a report.
+func readMappedCSV(r io.Reader, m *Mapping, bankId string, source string, report *FileReport) ([]*models.Transaction, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	rows, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var header []string
+	firstLine := 1
+	if m.HasHeader && len(rows) > 0 {
+		header, rows = rows[0], rows[1:]
+		firstLine = 2
+	}
+	cols, err := m.resolveColumns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	var skips []*regexp.Regexp
+	for _, re := range m.SkipRegexes {
+		skips = append(skips, regexp.MustCompile(re))
+	}
+	var checkRe *regexp.Regexp
+	if m.CheckNumRegex != "" {
+		checkRe = regexp.MustCompile(m.CheckNumRegex)
+	}
+	if m.Source != "" {
+		source = m.Source
+	}
+
+	var trans []*models.Transaction
+	for i, row := range rows {
+		line := firstLine + i // assumes no quoted values span lines
+		if len(row) == 1 && strings.TrimSpace(row[0]) == "" {
+			continue
+		}
+		if report != nil {
+			report.RowsRead++
+		}
+		description := strings.TrimSpace(field(row, cols.description))
+		if matchesAny(skips, description) {
+			report.skip(line, "matches a skip regex")
+			continue
+		}
+
+		date, err := m.parseDate(field(row, cols.date))
+		var out float64
+		if err == nil {
+			out, err = m.moneyOut(row, cols)
+		}
+		if err != nil {
+			if report == nil {
+				return nil, fmt.Errorf("line %d: %s", line, err.Error())
+			}
+			report.fail(line, "%s", err.Error())
+			continue
+		}
+
+		checkNum := checkNumber(row, cols, checkRe, description)
+		trans = append(trans, buildMappedTransaction(m, bankId, source, date, description, out, checkNum))
+	}
+	return trans, nil
+}
+
+// checkNumber reads the check number column, or the first group of checkRe matched against it or the description
+func checkNumber(row []string, cols mappedColumns, checkRe *regexp.Regexp, description string) string {
+	if checkRe != nil {
+		value := description
+		if cols.checkNum >= 0 {
+			value = field(row, cols.checkNum)
+		}
+		if sm := checkRe.FindStringSubmatch(value); len(sm) > 1 {
+			return sm[1]
+		}
+		return ""
+	}
+	return strings.TrimSpace(field(row, cols.checkNum))
+}
+
+func buildMappedTransaction(m *Mapping, bankId, source, date, description string, out float64, checkNum string) *models.Transaction {
+	return statement.Entry{
+		Source:       source,
+		KeyPrefix:    bankId,
+		Date:         date,
+		Description:  description,
+		CheckNum:     checkNum,
+		Out:          out,
+		IsCreditCard: m.IsCreditCard,
+	}.Transaction()
+}
+
+func (m *Mapping) resolveColumns(header []string) (mappedColumns, error) {
+	var cols mappedColumns
+	var err error
+	for _, c := range []struct {
+		name  string
+		index *int
+	}{
+		{m.Date, &cols.date},
+		{m.Description, &cols.description},
+		{m.Amount, &cols.amount},
+		{m.Debit, &cols.debit},
+		{m.Credit, &cols.credit},
+		{m.CheckNum, &cols.checkNum},
+	} {
+		if *c.index, err = columnIndex(header, c.name); err != nil {
+			return cols, err
+		}
+	}
+	return cols, nil
+}
+
+// columnIndex finds a column by header name (case-insensitive) or reads it as a 0-based index
+func columnIndex(header []string, name string) (int, error) {
+	if name == "" {
+		return -1, nil
+	}
+	if i, err := strconv.Atoi(name); err == nil {
+		return i, nil
+	}
+	for i, h := range header {
+		if strings.EqualFold(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")), name) {
+			return i, nil
+		}
+	}
+	if header == nil {
+		return -1, fmt.Errorf("column %q needs a header row; use a column index", name)
+	}
+	return -1, fmt.Errorf("no column named %q in header %v", name, header)
+}
+
+func (m *Mapping) parseDate(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if m.DateLayout == "" {
+		if !regexp.MustCompile(`\d\d/\d\d/(20)?\d\d|(20)?\d\d-\d\d-\d\d`).MatchString(value) {
+			return "", fmt.Errorf("invalid date: %q", value)
+		}
+		return readDateValue(value), nil
+	}
+	d, err := time.Parse(m.DateLayout, value)
+	if err != nil {
+		return "", fmt.Errorf("invalid date: %q", value)
+	}
+	return d.Format(models.RegisterDateFormat), nil
+}
+
+// moneyOut returns the row amount as positive for money out and negative for money in. A row without an
+// amount, or with neither a debit nor a credit, is an error rather than a zero transaction.
+func (m *Mapping) moneyOut(row []string, cols mappedColumns) (float64, error) {
+	if cols.amount >= 0 {
+		value := field(row, cols.amount)
+		if strings.TrimSpace(value) == "" {
+			return 0, fmt.Errorf("missing amount")
+		}
+		amount, err := parseMoney(value)
+		if err != nil {
+			return 0, err
+		}
+		if m.FlipSign {
+			return amount, nil
+		}
+		return -amount, nil
+	}
+	if strings.TrimSpace(field(row, cols.debit)) == "" && strings.TrimSpace(field(row, cols.credit)) == "" {
+		return 0, fmt.Errorf("missing debit and credit")
+	}
+	debit, err := parseMoney(field(row, cols.debit))
+	if err != nil {
+		return 0, err
+	}
+	credit, err := parseMoney(field(row, cols.credit))
+	if err != nil {
+		return 0, err
+	}
+	return math.Abs(debit) - math.Abs(credit), nil
+}
+
+// parseMoney reads amounts such as -1,234.56, $12.00 or (12.00); an empty value is zero
+func parseMoney(value string) (float64, error) {
+	value = strings.NewReplacer("$", "", ",", "", " ", "").Replace(strings.TrimSpace(value))
+	if value == "" {
+		return 0, nil
+	}
+	negative := strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")")
+	amount, err := strconv.ParseFloat(strings.Trim(value, "()"), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount: %q", value)
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+func field(row []string, i int) string {
+	if i < 0 || i >= len(row) {
+		return ""
+	}
+	return row[i]
+}
+
+func matchesAny(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
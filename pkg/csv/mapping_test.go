@@ -0,0 +1,191 @@
+package csv
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	cfg "register/pkg/config"
+	"register/pkg/models"
+)
+
+func Test_readMappedCSV(t *testing.T) {
+	type args struct {
+		doc     string
+		mapping *Mapping
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    []*models.Transaction
+		wantErr bool
+	}{
+		{
+			name: "Test signed amount with header, check number and skip regex",
+			args: args{
+				doc: `Posting Date,Amount,Description,Check or Slip #
+01/05/2026,-75.53,GLO FIBER BILLPAY,
+01/06/2026,-25.00,CHECK 110,110
+01/07/2026,"2,000.00",PAYROLL,
+01/08/2026,-500.00,ONLINE TRANSFER TO CARD,
+`,
+				mapping: &Mapping{
+					HasHeader:     true,
+					Date:          "Posting Date",
+					Description:   "description",
+					Amount:        "Amount",
+					CheckNum:      "Check or Slip #",
+					CheckNumRegex: `^0*(\d+)$`,
+					SkipRegexes:   []string{`(?i)transfer to card`},
+				},
+			},
+			want: []*models.Transaction{
+				{Key: "credit_union:01/05/26:75.53", Source: "CreditUnion", Date: "01/05/26", Name: "GLO FIBER BILLPAY", BankName: "GLO FIBER BILLPAY", Amount: 75.53, Withdrawal: 75.53, Budget: -75.53},
+				{Key: "credit_union:01/06/26:25.00", Source: "110", Date: "01/06/26", Name: "CHECK", BankName: "CHECK", Amount: 25, Withdrawal: 25, Budget: -25, IsCheck: true},
+				{Key: "credit_union:01/07/26:2000.00", Source: "CreditUnion", Date: "01/07/26", Name: "PAYROLL", BankName: "PAYROLL", Amount: -2000, Deposit: 2000, Budget: 2000},
+			},
+		},
+		{
+			name: "Test headerless debit/credit columns by index with date layout",
+			args: args{
+				doc: `2026-01-03,KROGER #123,45.10,,
+2026-01-04,PAYMENT THANK YOU,,500.00
+2026-01-05,REFUND,,-12.00
+`,
+				mapping: &Mapping{
+					Source:       "Discover",
+					IsCreditCard: true,
+					Date:         "0",
+					DateLayout:   "2006-01-02",
+					Description:  "1",
+					Debit:        "2",
+					Credit:       "3",
+					SkipRegexes:  []string{`(?i)payment\s+thank you`},
+				},
+			},
+			want: []*models.Transaction{
+				{Key: "credit_union:01/03/26:45.10", Source: "Discover", Date: "01/03/26", Name: "KROGER #123", BankName: "KROGER #123", Amount: 45.10, CreditPurchase: 45.10, CreditCard: 45.10, Budget: -45.10},
+				{Key: "credit_union:01/05/26:-12.00", Source: "Discover", Date: "01/05/26", Name: "REFUND", BankName: "REFUND", Amount: -12, CreditPurchase: -12, CreditCard: -12, Budget: 12},
+			},
+		},
+		{
+			name: "Test flipped sign card amounts",
+			args: args{
+				doc: "Date,Name,Amount\n01/03/26,NETFLIX,($12.00)\n01/04/26,AMAZON,$30.00\n",
+				mapping: &Mapping{
+					HasHeader: true, IsCreditCard: true, FlipSign: true,
+					Date: "Date", Description: "Name", Amount: "Amount",
+				},
+			},
+			want: []*models.Transaction{
+				{Key: "credit_union:01/03/26:-12.00", Source: "CreditUnion", Date: "01/03/26", Name: "NETFLIX", BankName: "NETFLIX", Amount: -12, CreditPurchase: -12, CreditCard: -12, Budget: 12},
+				{Key: "credit_union:01/04/26:30.00", Source: "CreditUnion", Date: "01/04/26", Name: "AMAZON", BankName: "AMAZON", Amount: 30, CreditPurchase: 30, CreditCard: 30, Budget: -30},
+			},
+		},
+		{
+			name: "Test missing header column",
+			args: args{
+				doc:     "Date,Name,Amount\n01/03/26,NETFLIX,-12.00\n",
+				mapping: &Mapping{HasHeader: true, Date: "Date", Description: "Payee", Amount: "Amount"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Test blank debit and credit",
+			args: args{
+				doc:     "2026-01-03,KROGER #123,,\n",
+				mapping: &Mapping{Date: "0", DateLayout: "2006-01-02", Description: "1", Debit: "2", Credit: "3"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Test bad amount",
+			args: args{
+				doc:     "01/03/26,NETFLIX,twelve\n",
+				mapping: &Mapping{Date: "0", Description: "1", Amount: "2"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readMappedCSV(strings.NewReader(tt.args.doc), tt.args.mapping, "credit_union", "CreditUnion", nil)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("readMappedCSV() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				for i := range got {
+					t.Logf("got[%d] = %+v", i, got[i])
+				}
+				t.Errorf("readMappedCSV() did not match")
+			}
+		})
+	}
+}
+
+func Test_readMappedCSV_report(t *testing.T) {
+	doc := "Date,Name,Amount\n01/03/26,NETFLIX,-12.00\n01/04/26,AMAZON,twelve\n01/05/26,TRANSFER,-100.00\n01/06/26,SAFEWAY,\n"
+	m := &Mapping{HasHeader: true, Date: "Date", Description: "Name", Amount: "Amount", SkipRegexes: []string{"TRANSFER"}}
+	report := &FileReport{}
+	got, err := readMappedCSV(strings.NewReader(doc), m, "credit_union", "CreditUnion", report)
+	if err != nil {
+		t.Fatalf("readMappedCSV() error = %v", err)
+	}
+	want := &FileReport{
+		RowsRead: 4,
+		Skipped:  []RowIssue{{Line: 4, Reason: "matches a skip regex"}},
+		Errors:   []RowIssue{{Line: 3, Reason: `invalid amount: "twelve"`}, {Line: 5, Reason: "missing amount"}},
+	}
+	report.failed = nil
+	if len(got) != 1 || !reflect.DeepEqual(report, want) {
+		t.Errorf("readMappedCSV() = %d transactions, report %+v", len(got), report)
+	}
+}
+
+func TestLoadMappings(t *testing.T) {
+	tests := []struct {
+		name    string
+		json    string
+		wantErr bool
+	}{
+		{
+			name: "Test valid mapping",
+			json: `{"credit_union": {"hasHeader": true, "date": "Date", "description": "Description", "amount": "Amount"}}`,
+		},
+		{
+			name:    "Test mapping without an amount column",
+			json:    `{"credit_union": {"date": "0", "description": "1"}}`,
+			wantErr: true,
+		},
+		{
+			name:    "Test mapping with a bad skip regex",
+			json:    `{"credit_union": {"date": "0", "description": "1", "amount": "2", "skipRegexes": ["("]}}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			myReadFile = FakeReadFiler{Str: tt.json}.ReadFile
+			_, err := LoadMappings("mappings.json")
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadMappings() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestClient_GetTransactions_mapping(t *testing.T) {
+	myReadFile = FakeReadFiler{Str: "Date,Description,Amount\n01/05/2026,GLO FIBER BILLPAY,-75.53\n"}.ReadFile
+	c := New(ConfigOptions{
+		FinanceDir: "/finance",
+		Banks:      map[string]cfg.Bank{"credit_union": {ID: "credit_union", Name: "Credit Union", Source: "CreditUnion", CSVFileName: "cu.csv"}},
+		Mappings:   map[string]*Mapping{"credit_union": {HasHeader: true, Date: "Date", Description: "Description", Amount: "Amount"}},
+	})
+	got, err := c.GetTransactions([]string{"credit_union"})
+	if err != nil {
+		t.Fatalf("GetTransactions() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Key != "credit_union:01/05/26:75.53" {
+		t.Errorf("GetTransactions() = %v", got)
+	}
+}
@@ -0,0 +1,112 @@
+package csv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/gocarina/gocsv"
+)
+
+// RowIssue is a CSV row left out of the import, by line number
+type RowIssue struct {
+	Line   int
+	Reason string
+}
+
+// FileReport is the ingestion report of one CSV file
+type FileReport struct {
+	File     string
+	BankID   string
+	RowsRead int        // data rows in the file
+	Skipped  []RowIssue // rows left out on purpose, eg. pending rows and card payments
+	Errors   []RowIssue // rows that could not be read
+	failed   map[int]bool
+}
+
+// Report is the ingestion report of every CSV file read by a client
+type Report struct {
+	Files []*FileReport
+}
+
+// NewFileReport adds a report for a file
+func (r *Report) NewFileReport(file, bankID string) *FileReport {
+	f := &FileReport{File: file, BankID: bankID}
+	r.Files = append(r.Files, f)
+	return f
+}
+
+// HasErrors returns true if any row of any file could not be read
+func (r *Report) HasErrors() bool {
+	for _, f := range r.Files {
+		if len(f.Errors) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Print writes a summary per file followed by its skipped and failed rows
+func (r *Report) Print(w io.Writer) {
+	for _, f := range r.Files {
+		fmt.Fprintf(w, "    %-12s %s: %d rows read, %d imported, %d skipped, %d errors\n",
+			f.BankID, f.File, f.RowsRead, f.Imported(), len(f.Skipped), len(f.Errors))
+		for _, e := range f.Errors {
+			fmt.Fprintf(w, "        error   line %d: %s\n", e.Line, e.Reason)
+		}
+		for _, s := range f.Skipped {
+			fmt.Fprintf(w, "        skipped line %d: %s\n", s.Line, s.Reason)
+		}
+	}
+}
+
+// Imported is the number of rows that became transactions
+func (f *FileReport) Imported() int {
+	return f.RowsRead - len(f.Skipped) - len(f.Errors)
+}
+
+// skip records a row left out on purpose; a nil report records nothing
+func (f *FileReport) skip(line int, reason string) {
+	if f == nil {
+		return
+	}
+	f.Skipped = append(f.Skipped, RowIssue{Line: line, Reason: reason})
+}
+
+// fail records a row that could not be read; only the first error of a row is kept
+func (f *FileReport) fail(line int, format string, a ...interface{}) {
+	if f == nil {
+		return
+	}
+	if f.failed == nil {
+		f.failed = make(map[int]bool)
+	}
+	if f.failed[line] {
+		return
+	}
+	f.failed[line] = true
+	f.Errors = append(f.Errors, RowIssue{Line: line, Reason: fmt.Sprintf(format, a...)})
+}
+
+// hasError returns true if the row at line already failed
+func (f *FileReport) hasError(line int) bool {
+	return f != nil && f.failed[line]
+}
+
+// unmarshalRows reads a CSV with a header row into out, a pointer to a slice of struct pointers. A field that
+// cannot be parsed fails its row in the report, by line number, rather than the whole file; without a report
+// it fails the file.
+func unmarshalRows(r io.Reader, out interface{}, report *FileReport) error {
+	err := gocsv.UnmarshalWithErrorHandler(r, func(e *csv.ParseError) bool {
+		report.fail(e.Line, "column %d: %s", e.Column, e.Err.Error())
+		return report != nil
+	}, out)
+	if err != nil {
+		return err
+	}
+	if report != nil {
+		report.RowsRead += reflect.ValueOf(out).Elem().Len()
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package csv
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReport_Print(t *testing.T) {
+	csvFile := filepath.Join(t.TempDir(), "chase.csv")
+	err := os.WriteFile(csvFile, []byte(`Transaction Date,Post Date,Description,Category,Type,Amount
+01/03/2026,01/04/2026,STARBUCKS,Food & Drink,Sale,-5.25
+01/04/2026,01/05/2026,KROGER,Groceries,Sale,lots
+01/05/2026,01/06/2026,PAYMENT THANK YOU,,Payment,500.00
+Jan 6,01/07/2026,NETFLIX,Entertainment,Sale,-12.00
+`), 0600)
+	checkTestingError(t, err)
+
+	report := &Report{}
+	trans, err := readChaseCSVRows(csvFile, "chase", report.NewFileReport(csvFile, "chase"))
+	checkTestingError(t, err)
+	if len(trans) != 1 || !report.HasErrors() {
+		t.Fatalf("readChaseCSVRows() = %d transactions, report %+v", len(trans), report.Files[0])
+	}
+
+	var out bytes.Buffer
+	report.Print(&out)
+	for _, want := range []string{
+		"chase.csv: 4 rows read, 1 imported, 1 skipped, 2 errors",
+		"error   line 3: column 6:",
+		`error   line 5: invalid date "Jan 6"`,
+		"skipped line 4: card payment",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("Print() = %q, missing %q", out.String(), want)
+		}
+	}
+}
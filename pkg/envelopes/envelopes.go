@@ -0,0 +1,164 @@
+package envelopes
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"register/pkg/income"
+	"register/pkg/models"
+)
+
+// Balance is a category's envelope on a date
+type Balance struct {
+	Category    string  `json:"category"`
+	Rollover    string  `json:"rollover"`
+	Funded      float64 `json:"funded"`      // the income allocations
+	Spent       float64 `json:"spent"`       // the spending less refunds
+	Transferred float64 `json:"transferred"` // the net of transfers in and out
+	RolledOff   float64 `json:"rolledOff"`   // the amount the rollover rule dropped at month starts
+	Balance     float64 `json:"balance"`
+}
+
+// Events returns the envelope events of the transactions: a funding event for each category an income
+// source's deposit is allocated to, and a spending event for each transaction in a category column.
+// funding holds the allocations by income source name, then category. Events are keyed by the bank
+// transaction ID, so same-key purchases, eg. a double charge, are each recorded; transactions without
+// one, eg. from CSV files, are keyed by their occurrence of the transaction key.
+func Events(trans []*models.Transaction, columns []models.Column, sources *income.Sources, funding map[string]map[string]float64) ([]models.EnvelopeEvent, error) {
+	categories := make(map[int]models.Column)
+	for _, c := range columns {
+		if c.IsCategory {
+			categories[c.ColumnIndex] = c
+		}
+	}
+
+	occurrences := make(map[string]int)
+	var events []models.EnvelopeEvent
+	for _, t := range trans {
+		key := t.TransactionID
+		if key == "" {
+			occurrences[t.Key]++
+			key = models.OccurrenceKey(t.Key, occurrences[t.Key])
+		}
+		date, err := time.Parse(models.RegisterDateFormat, t.Date)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s: could not read the date: %s", t.Key, err.Error())
+		}
+		if src := sources.Named(t.Name); src != nil && t.Deposit > 0 {
+			names := make([]string, 0, len(funding[src.Name]))
+			for name := range funding[src.Name] {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				events = append(events, models.EnvelopeEvent{Date: date, Category: name, Kind: models.EnvelopeFunding,
+					Amount: funding[src.Name][name], Key: key, Note: t.Name})
+			}
+			if len(names) > 0 {
+				continue
+			}
+		}
+		if c, ok := categories[t.ColumnIndex]; ok && t.Budget != 0 {
+			events = append(events, models.EnvelopeEvent{Date: date, Category: c.Name, Kind: models.EnvelopeSpending,
+				Amount: t.Budget, Key: key, Note: t.Name})
+		}
+	}
+	return events, nil
+}
+
+// Transfer returns the two events that move the amount from one category to another
+func Transfer(from, to string, amount float64, date time.Time, note string) []models.EnvelopeEvent {
+	key := fmt.Sprintf("transfer:%d", time.Now().UnixNano())
+	return []models.EnvelopeEvent{
+		{Date: date, Category: from, Kind: models.EnvelopeTransfer, Amount: -amount, Key: key, Note: note},
+		{Date: date, Category: to, Kind: models.EnvelopeTransfer, Amount: amount, Key: key, Note: note},
+	}
+}
+
+// ValidRollover returns true if the rollover is one of the Rollover constants
+func ValidRollover(rollover string) bool {
+	switch rollover {
+	case models.RolloverCarry, models.RolloverReset, models.RolloverCap:
+		return true
+	}
+	return false
+}
+
+// Balances returns each category's balance at the end of the date from the events, oldest first, and the
+// rollover rules. The rules apply at the start of each month: the balance carries over, has money left
+// over dropped (reset) or is capped. Categories without a rule carry over.
+func Balances(rules []models.EnvelopeRule, events []models.EnvelopeEvent, asOf time.Time) []Balance {
+	byCategory := make(map[string]models.EnvelopeRule)
+	balances := make(map[string]*Balance)
+	for _, r := range rules {
+		byCategory[r.Category] = r
+		balances[r.Category] = &Balance{Category: r.Category}
+	}
+	months := make(map[string]time.Time)
+	for _, e := range events {
+		if e.Date.After(asOf) {
+			continue
+		}
+		b, ok := balances[e.Category]
+		if !ok {
+			b = &Balance{Category: e.Category}
+			balances[e.Category] = b
+		}
+		month := monthOf(e.Date)
+		if last, ok := months[e.Category]; ok && month.After(last) {
+			rollover(b, byCategory[e.Category])
+		}
+		months[e.Category] = month
+
+		switch e.Kind {
+		case models.EnvelopeFunding:
+			b.Funded += e.Amount
+		case models.EnvelopeSpending:
+			b.Spent -= e.Amount
+		case models.EnvelopeTransfer:
+			b.Transferred += e.Amount
+		}
+		b.Balance += e.Amount
+	}
+
+	all := make([]Balance, 0, len(balances))
+	for category, b := range balances {
+		if last, ok := months[category]; ok && monthOf(asOf).After(last) {
+			rollover(b, byCategory[category])
+		}
+		b.Rollover = byCategory[category].Rollover
+		if b.Rollover == "" {
+			b.Rollover = models.RolloverCarry
+		}
+		b.Funded, b.Spent, b.Transferred = round(b.Funded), round(b.Spent), round(b.Transferred)
+		b.RolledOff, b.Balance = round(b.RolledOff), round(b.Balance)
+		all = append(all, *b)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Category < all[j].Category
+	})
+	return all
+}
+
+// rollover applies the rule to the balance carried into a new month
+func rollover(b *Balance, rule models.EnvelopeRule) {
+	carried := b.Balance
+	switch rule.Rollover {
+	case models.RolloverReset:
+		carried = math.Min(carried, 0)
+	case models.RolloverCap:
+		carried = math.Min(carried, rule.Cap)
+	}
+	b.RolledOff += b.Balance - carried
+	b.Balance = carried
+}
+
+func monthOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func round(f float64) float64 {
+	return math.Round(f*100) / 100
+}
@@ -0,0 +1,122 @@
+package envelopes
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"register/pkg/income"
+	"register/pkg/models"
+)
+
+func day(s string) time.Time {
+	d, _ := time.Parse("2006-01-02", s)
+	return d
+}
+
+func TestEvents(t *testing.T) {
+	columns := []models.Column{
+		{Name: "Groceries", ColumnIndex: 12, IsCategory: true},
+		{Name: "Dining", ColumnIndex: 13, IsCategory: true},
+		{Name: "Credit Card", ColumnIndex: 6},
+	}
+	paycheck := income.Default[0].Name
+	funding := map[string]map[string]float64{paycheck: {"Groceries": 200, "Dining": 75}}
+	trans := []*models.Transaction{
+		{Key: "wf-1", Date: "09/18/26", Name: paycheck, Deposit: 1500},
+		{Key: "citi-1", Date: "09/20/26", Name: "Safeway", Budget: -42.5, ColumnIndex: 12},
+		{Key: "citi-2", Date: "09/21/26", Name: "Chase Payment", CreditCard: 300, ColumnIndex: 6},
+		{Key: "citi-3", Date: "09/22/26", Name: "Safeway", Budget: 10, ColumnIndex: 12},
+	}
+	got, err := Events(trans, columns, nil, funding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.EnvelopeEvent{
+		{Date: day("2026-09-18"), Category: "Dining", Kind: models.EnvelopeFunding, Amount: 75, Key: "wf-1", Note: paycheck},
+		{Date: day("2026-09-18"), Category: "Groceries", Kind: models.EnvelopeFunding, Amount: 200, Key: "wf-1", Note: paycheck},
+		{Date: day("2026-09-20"), Category: "Groceries", Kind: models.EnvelopeSpending, Amount: -42.5, Key: "citi-1", Note: "Safeway"},
+		{Date: day("2026-09-22"), Category: "Groceries", Kind: models.EnvelopeSpending, Amount: 10, Key: "citi-3", Note: "Safeway"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Events() = %+v, want %+v", got, want)
+	}
+
+	// a double charge has the same key as the first
+	trans = []*models.Transaction{
+		{Key: "citi-4", TransactionID: "p-1", Date: "09/23/26", Name: "Cafe", Budget: -6, ColumnIndex: 13},
+		{Key: "citi-4", TransactionID: "p-2", Date: "09/23/26", Name: "Cafe", Budget: -6, ColumnIndex: 13},
+		{Key: "citi-5", Date: "09/24/26", Name: "Cafe", Budget: -4, ColumnIndex: 13},
+		{Key: "citi-5", Date: "09/24/26", Name: "Cafe", Budget: -4, ColumnIndex: 13},
+	}
+	got, err = Events(trans, columns, nil, funding)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []models.EnvelopeEvent{
+		{Date: day("2026-09-23"), Category: "Dining", Kind: models.EnvelopeSpending, Amount: -6, Key: "p-1", Note: "Cafe"},
+		{Date: day("2026-09-23"), Category: "Dining", Kind: models.EnvelopeSpending, Amount: -6, Key: "p-2", Note: "Cafe"},
+		{Date: day("2026-09-24"), Category: "Dining", Kind: models.EnvelopeSpending, Amount: -4, Key: "citi-5", Note: "Cafe"},
+		{Date: day("2026-09-24"), Category: "Dining", Kind: models.EnvelopeSpending, Amount: -4, Key: "citi-5:2", Note: "Cafe"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Events() = %+v, want %+v", got, want)
+	}
+
+	if _, err := Events([]*models.Transaction{{Key: "bad", Date: "2026-09-18"}}, columns, nil, funding); err == nil {
+		t.Errorf("Events() error = nil, want a date error")
+	}
+}
+
+func TestBalances(t *testing.T) {
+	rules := []models.EnvelopeRule{
+		{Category: "Dining", Rollover: models.RolloverReset},
+		{Category: "Groceries", Rollover: models.RolloverCap, Cap: 50},
+		{Category: "Gifts", Rollover: models.RolloverCarry},
+	}
+	events := []models.EnvelopeEvent{
+		{Date: day("2026-08-07"), Category: "Groceries", Kind: models.EnvelopeFunding, Amount: 200},
+		{Date: day("2026-08-07"), Category: "Dining", Kind: models.EnvelopeFunding, Amount: 75},
+		{Date: day("2026-08-07"), Category: "Travel", Kind: models.EnvelopeFunding, Amount: 100},
+		{Date: day("2026-08-10"), Category: "Groceries", Kind: models.EnvelopeSpending, Amount: -80},
+		{Date: day("2026-08-12"), Category: "Dining", Kind: models.EnvelopeSpending, Amount: -25},
+		{Date: day("2026-08-20"), Category: "Travel", Kind: models.EnvelopeTransfer, Amount: -30},
+		{Date: day("2026-08-20"), Category: "Dining", Kind: models.EnvelopeTransfer, Amount: 30},
+		{Date: day("2026-09-04"), Category: "Groceries", Kind: models.EnvelopeFunding, Amount: 200},
+		{Date: day("2026-09-05"), Category: "Dining", Kind: models.EnvelopeSpending, Amount: -90},
+	}
+
+	tests := []struct {
+		name string
+		asOf time.Time
+		want []Balance
+	}{
+		{
+			name: "Test before the month's end",
+			asOf: day("2026-08-31"),
+			want: []Balance{
+				{Category: "Dining", Rollover: models.RolloverReset, Funded: 75, Spent: 25, Transferred: 30, Balance: 80},
+				{Category: "Gifts", Rollover: models.RolloverCarry},
+				{Category: "Groceries", Rollover: models.RolloverCap, Funded: 200, Spent: 80, Balance: 120},
+				{Category: "Travel", Rollover: models.RolloverCarry, Funded: 100, Transferred: -30, Balance: 70},
+			},
+		},
+		{
+			name: "Test rolled over",
+			asOf: day("2026-10-01"),
+			want: []Balance{
+				{Category: "Dining", Rollover: models.RolloverReset, Funded: 75, Spent: 115, Transferred: 30, RolledOff: 80, Balance: -90},
+				{Category: "Gifts", Rollover: models.RolloverCarry},
+				{Category: "Groceries", Rollover: models.RolloverCap, Funded: 400, Spent: 80, RolledOff: 270, Balance: 50},
+				{Category: "Travel", Rollover: models.RolloverCarry, Funded: 100, Transferred: -30, Balance: 70},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Balances(rules, events, tt.asOf); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Balances() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
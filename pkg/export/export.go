@@ -0,0 +1,295 @@
+package export
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"register/pkg/income"
+	"register/pkg/models"
+)
+
+// Format ...
+type Format string
+
+const (
+	// Beancount ...
+	Beancount Format = "beancount"
+	// Ledger ...
+	Ledger Format = "ledger"
+	// HLedger ...
+	HLedger Format = "hledger"
+
+	CheckingAccount      = "Assets:Checking:WellsFargo"
+	CreditCardAccount    = "Liabilities:CreditCard"
+	SalaryAccount        = "Income:Salary"
+	OtherIncomeAccount   = "Income:Other"
+	UncategorizedAccount = "Expenses:Uncategorized"
+	CreditCardsColumn    = "Credit Cards"
+	ExportDateFormat     = "2006-01-02"
+)
+
+// Options ...
+type Options struct {
+	Format   Format
+	Columns  []models.Column
+	Balances map[string]float64 // balance assertions by account name
+	Date     time.Time          // date of the balance assertions
+	Income   *income.Sources    // nil for the default income source
+}
+
+// Posting is one leg of a double-entry transaction
+type Posting struct {
+	Account string
+	Amount  float64
+}
+
+// Entry is a balanced double-entry transaction
+type Entry struct {
+	Date          time.Time
+	Payee         string
+	Code          string
+	Note          string
+	TaxDeductible bool
+	Postings      []Posting
+}
+
+// ParseFormat ...
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(s)); f {
+	case Beancount, Ledger, HLedger:
+		return f, nil
+	}
+	return "", fmt.Errorf("unknown export format: %s; use beancount, ledger or hledger", s)
+}
+
+// checkNumberRe matches the source of a check, its number
+var checkNumberRe = regexp.MustCompile(`^\d+$`)
+
+// SourceAccount maps a transaction source (WellsFargo, Chase, Fidelity or a check number) to an asset or liability account
+func SourceAccount(source string) string {
+	if source == "" || strings.EqualFold(source, "WellsFargo") || checkNumberRe.MatchString(source) {
+		return CheckingAccount
+	}
+	return CreditCardAccount + ":" + accountName(source)
+}
+
+// CategoryAccount maps a budget category column to an expense account
+func CategoryAccount(column string) string {
+	return "Expenses:" + accountName(column)
+}
+
+// Write converts the transactions to double-entry postings and writes them in the requested format
+func Write(w io.Writer, trans []models.Transaction, o Options) error {
+	columns := make(map[int]models.Column)
+	for _, c := range o.Columns {
+		columns[c.ColumnIndex] = c
+	}
+
+	var entries []*Entry
+	for _, t := range trans {
+		e, err := buildEntry(t, columns, o.Income)
+		if err != nil {
+			return err
+		}
+		entries = append(entries, e)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Date.Before(entries[j].Date)
+	})
+
+	if o.Format == Beancount {
+		if err := writeBeancountOpens(w, entries, o); err != nil {
+			return err
+		}
+	}
+
+	for _, e := range entries {
+		var err error
+		if o.Format == Beancount {
+			err = writeBeancountEntry(w, e)
+		} else {
+			err = writeLedgerEntry(w, e)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return writeBalances(w, o)
+}
+
+func buildEntry(t models.Transaction, columns map[int]models.Column, sources *income.Sources) (*Entry, error) {
+	date, err := time.Parse(models.RegisterDateFormat, t.Date)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse date %s for %s: %s", t.Date, t.Key, err.Error())
+	}
+	e := &Entry{
+		Date:          date,
+		Payee:         t.Name,
+		Note:          t.Note,
+		TaxDeductible: t.TaxDeductible,
+	}
+	if e.Payee == "" {
+		e.Payee = t.BankName
+	}
+	if t.IsCheck {
+		e.Code = t.Source
+	}
+
+	source := SourceAccount(t.Source)
+	if t.IsCheck {
+		source = CheckingAccount
+	}
+
+	// amount is what was spent: positive for a purchase or withdrawal, negative for a deposit or refund
+	amount := t.CreditCard
+	if source == CheckingAccount {
+		amount = t.Withdrawal - t.Deposit
+	}
+
+	e.Postings = []Posting{
+		{Account: counterAccount(t, amount, columns, sources), Amount: amount},
+		{Account: source, Amount: -amount},
+	}
+	return e, nil
+}
+
+func counterAccount(t models.Transaction, amount float64, columns map[int]models.Column, sources *income.Sources) string {
+	if src := sources.Named(t.Name); src != nil {
+		if src.Account != "" {
+			return src.Account
+		}
+		return SalaryAccount
+	}
+	if col, ok := columns[t.ColumnIndex]; ok && col.IsCategory {
+		if col.Name == CreditCardsColumn {
+			return CreditCardAccount
+		}
+		return CategoryAccount(col.Name)
+	}
+	if amount < 0 {
+		return OtherIncomeAccount
+	}
+	return UncategorizedAccount
+}
+
+// writeBeancountOpens opens every account used, as of the first transaction, since beancount requires it
+func writeBeancountOpens(w io.Writer, entries []*Entry, o Options) error {
+	opened := make(map[string]bool)
+	for a := range o.Balances {
+		opened[a] = true
+	}
+	for _, e := range entries {
+		for _, p := range e.Postings {
+			opened[p.Account] = true
+		}
+	}
+	if len(opened) == 0 {
+		return nil
+	}
+
+	date := o.Date
+	if len(entries) > 0 {
+		date = entries[0].Date
+	}
+	accounts := make([]string, 0, len(opened))
+	for a := range opened {
+		accounts = append(accounts, a)
+	}
+	sort.Strings(accounts)
+	for _, a := range accounts {
+		if _, err := fmt.Fprintf(w, "%s open %s USD\n", date.Format(ExportDateFormat), a); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
+func writeBeancountEntry(w io.Writer, e *Entry) error {
+	narration := e.Note
+	if e.Code != "" {
+		narration = strings.TrimSpace("Check #" + e.Code + " " + narration)
+	}
+	s := fmt.Sprintf("%s * %q %q\n", e.Date.Format(ExportDateFormat), e.Payee, narration)
+	if e.Note != "" {
+		s += fmt.Sprintf("  note: %q\n", e.Note)
+	}
+	if e.TaxDeductible {
+		s += "  tax-deductible: TRUE\n"
+	}
+	for _, p := range e.Postings {
+		s += fmt.Sprintf("  %-50s %10.2f USD\n", p.Account, p.Amount)
+	}
+	_, err := fmt.Fprintln(w, s)
+	return err
+}
+
+// writeLedgerEntry writes the entry in the journal syntax shared by ledger and hledger
+func writeLedgerEntry(w io.Writer, e *Entry) error {
+	code := ""
+	if e.Code != "" {
+		code = "(" + e.Code + ") "
+	}
+	s := fmt.Sprintf("%s * %s%s\n", e.Date.Format(ExportDateFormat), code, e.Payee)
+	if e.Note != "" {
+		s += fmt.Sprintf("    ; note: %s\n", e.Note)
+	}
+	if e.TaxDeductible {
+		s += "    ; tax-deductible: true\n"
+	}
+	for _, p := range e.Postings {
+		s += fmt.Sprintf("    %-50s %s\n", p.Account, ledgerAmount(p.Amount))
+	}
+	_, err := fmt.Fprintln(w, s)
+	return err
+}
+
+func writeBalances(w io.Writer, o Options) error {
+	accounts := make([]string, 0, len(o.Balances))
+	for a := range o.Balances {
+		accounts = append(accounts, a)
+	}
+	sort.Strings(accounts)
+
+	date := o.Date.Format(ExportDateFormat)
+	for _, a := range accounts {
+		var err error
+		if o.Format == Beancount {
+			// beancount checks the balance at the start of the day
+			_, err = fmt.Fprintf(w, "%s balance %-50s %10.2f USD\n", o.Date.AddDate(0, 0, 1).Format(ExportDateFormat), a, o.Balances[a])
+		} else {
+			_, err = fmt.Fprintf(w, "%s * Balance assertion\n    %-50s %s = %s\n\n", date, a, ledgerAmount(0), ledgerAmount(o.Balances[a]))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ledgerAmount(amount float64) string {
+	if amount < 0 {
+		return fmt.Sprintf("-$%.2f", -amount)
+	}
+	return fmt.Sprintf("$%.2f", amount)
+}
+
+// accountName turns a source or column name into a valid account name component, eg. "Clothing & Household" to Clothing-Household
+func accountName(name string) string {
+	words := regexp.MustCompile(`[^A-Za-z0-9]+`).Split(name, -1)
+	var parts []string
+	for _, word := range words {
+		if word == "" {
+			continue
+		}
+		parts = append(parts, strings.ToUpper(word[:1])+word[1:])
+	}
+	if len(parts) == 0 {
+		return "Unknown"
+	}
+	return strings.Join(parts, "-")
+}
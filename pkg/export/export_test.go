@@ -0,0 +1,153 @@
+package export
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"register/pkg/income"
+	"register/pkg/models"
+)
+
+var testColumns = []models.Column{
+	{Name: "Credit Cards", ColumnIndex: 10, IsCategory: true},
+	{Name: "Groceries", ColumnIndex: 11, IsCategory: true},
+	{Name: "Clothing & Household", ColumnIndex: 12, IsCategory: true},
+}
+
+func TestSourceAccount(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{name: "Test SourceAccount Wells Fargo", source: "WellsFargo", want: CheckingAccount},
+		{name: "Test SourceAccount check number", source: "1042", want: CheckingAccount},
+		{name: "Test SourceAccount Chase", source: "Chase", want: "Liabilities:CreditCard:Chase"},
+		{name: "Test SourceAccount boa", source: "boa", want: "Liabilities:CreditCard:Boa"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SourceAccount(tt.source); got != tt.want {
+				t.Errorf("SourceAccount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_accountName(t *testing.T) {
+	if got := accountName("Clothing & Household"); got != "Clothing-Household" {
+		t.Errorf("accountName() = %v", got)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	trans := []models.Transaction{
+		{Source: "Chase", Date: "01/05/26", Name: "Kroger", CreditCard: 45.10, ColumnIndex: 11, Note: "party food", TaxDeductible: true},
+		{Source: "1042", Date: "01/03/26", Name: "CHECK", Withdrawal: 25, IsCheck: true, ColumnIndex: 12},
+		{Source: "WellsFargo", Date: "01/02/26", Name: income.Default[0].Name, Deposit: 2000},
+	}
+	o := Options{
+		Columns:  testColumns,
+		Balances: map[string]float64{CheckingAccount: 1975},
+		Date:     time.Date(2026, time.January, 6, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		name   string
+		format Format
+		want   string
+	}{
+		{
+			name:   "Test Write beancount",
+			format: Beancount,
+			want: `2026-01-02 open Assets:Checking:WellsFargo USD
+2026-01-02 open Expenses:Clothing-Household USD
+2026-01-02 open Expenses:Groceries USD
+2026-01-02 open Income:Salary USD
+2026-01-02 open Liabilities:CreditCard:Chase USD
+
+2026-01-02 * "50/50 Taphouse Paycheck" ""
+  Income:Salary                                        -2000.00 USD
+  Assets:Checking:WellsFargo                            2000.00 USD
+
+2026-01-03 * "CHECK" "Check #1042"
+  Expenses:Clothing-Household                             25.00 USD
+  Assets:Checking:WellsFargo                             -25.00 USD
+
+2026-01-05 * "Kroger" "party food"
+  note: "party food"
+  tax-deductible: TRUE
+  Expenses:Groceries                                      45.10 USD
+  Liabilities:CreditCard:Chase                           -45.10 USD
+
+2026-01-07 balance Assets:Checking:WellsFargo                            1975.00 USD
+`,
+		},
+		{
+			name:   "Test Write ledger",
+			format: Ledger,
+			want: `2026-01-02 * 50/50 Taphouse Paycheck
+    Income:Salary                                      -$2000.00
+    Assets:Checking:WellsFargo                         $2000.00
+
+2026-01-03 * (1042) CHECK
+    Expenses:Clothing-Household                        $25.00
+    Assets:Checking:WellsFargo                         -$25.00
+
+2026-01-05 * Kroger
+    ; note: party food
+    ; tax-deductible: true
+    Expenses:Groceries                                 $45.10
+    Liabilities:CreditCard:Chase                       -$45.10
+
+2026-01-06 * Balance assertion
+    Assets:Checking:WellsFargo                         $0.00 = $1975.00
+
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			o.Format = tt.format
+			if err := Write(&buf, trans, o); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Write() = \n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_counterAccount(t *testing.T) {
+	sources, err := income.New([]models.IncomeSource{
+		{Name: "Acme Payroll", Match: "ACME"},
+		{Name: "Tutoring", Match: "VENMO", Account: "Income:Tutoring"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns := map[int]models.Column{11: {Name: "Groceries", ColumnIndex: 11, IsCategory: true}}
+	tests := []struct {
+		name    string
+		t       models.Transaction
+		amount  float64
+		sources *income.Sources
+		want    string
+	}{
+		{name: "Test configured paycheck", t: models.Transaction{Name: "Acme Payroll"}, amount: -2000, sources: sources, want: SalaryAccount},
+		{name: "Test income source account", t: models.Transaction{Name: "Tutoring"}, amount: -80, sources: sources, want: "Income:Tutoring"},
+		{name: "Test default paycheck", t: models.Transaction{Name: income.Default[0].Name}, amount: -2000, want: SalaryAccount},
+		{name: "Test default is not used when sources are configured", t: models.Transaction{Name: income.Default[0].Name}, amount: -2000, sources: sources, want: OtherIncomeAccount},
+		{name: "Test category", t: models.Transaction{Name: "Kroger", ColumnIndex: 11}, amount: 45, sources: sources, want: "Expenses:Groceries"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := counterAccount(tt.t, tt.amount, columns, tt.sources); got != tt.want {
+				t.Errorf("counterAccount() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
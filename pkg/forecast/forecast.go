@@ -0,0 +1,187 @@
+package forecast
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"register/pkg/models"
+	"register/pkg/recurring"
+)
+
+// Event is one expected deposit (positive) or withdrawal (negative) from checking
+type Event struct {
+	Date   time.Time
+	Name   string
+	Amount float64
+}
+
+// Day is the projected checking balance at the end of a day
+type Day struct {
+	Date       time.Time
+	Events     []Event
+	Balance    float64
+	BelowFloor bool
+}
+
+// Card is a credit card balance paid in full from checking on a day of the month
+type Card struct {
+	Name       string
+	Balance    float64 // the amount owed
+	PaymentDay int     // day of the month; a day past the month's end is its last day, 0 the first
+}
+
+// Income is a regular deposit to checking, eg. a paycheck
+type Income struct {
+	Name    string
+	Amount  float64
+	Last    time.Time // the last deposit; the next are projected from it
+	Cadence string    // models.AllocationWeekly, AllocationEvery2Weeks, AllocationTwiceMonthly or AllocationMonthly; none is not projected
+}
+
+// Options ...
+type Options struct {
+	Start   time.Time // the first day projected; Balance is its opening balance
+	Days    int
+	Balance float64
+	Floor   float64
+	Incomes []Income
+	Bills   []recurring.Subscription
+	Cards   []Card
+}
+
+// Forecast ...
+type Forecast struct {
+	Days   []Day
+	Lowest Day
+}
+
+// BelowFloor returns the days the balance would be below the floor
+func (f *Forecast) BelowFloor() []Day {
+	var days []Day
+	for _, d := range f.Days {
+		if d.BelowFloor {
+			days = append(days, d)
+		}
+	}
+	return days
+}
+
+// Project returns the daily checking balance for the options' days. Bills that are late but not stopped are
+// expected on the first day; each card's current balance is paid once, on its next payment day.
+func Project(o Options) *Forecast {
+	start := truncate(o.Start)
+	end := start.AddDate(0, 0, o.Days)
+	var events []Event
+
+	for _, in := range o.Incomes {
+		if in.Amount == 0 || in.Last.IsZero() || in.Cadence == models.AllocationNone {
+			continue
+		}
+		for n, d := 1, nextPayday(in, 1); d.Before(end); n, d = n+1, nextPayday(in, n+1) {
+			if !d.Before(start) {
+				events = append(events, Event{Date: d, Name: in.Name, Amount: in.Amount})
+			}
+		}
+	}
+
+	for _, b := range o.Bills {
+		if b.Stopped {
+			continue
+		}
+		for i, d := 0, truncate(b.NextExpected); d.Before(end); i, d = i+1, nextCharge(b, i+1) {
+			if d.Before(start) {
+				d = start
+			}
+			events = append(events, Event{Date: d, Name: b.Name, Amount: -b.LastAmount})
+		}
+	}
+
+	for _, c := range o.Cards {
+		if c.Balance <= 0 {
+			continue
+		}
+		if d := paymentDate(start, c.PaymentDay); d.Before(end) {
+			events = append(events, Event{Date: d, Name: c.Name, Amount: -c.Balance})
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Date.Before(events[j].Date) })
+
+	f := &Forecast{}
+	balance := o.Balance
+	for d, i := start, 0; d.Before(end); d = d.AddDate(0, 0, 1) {
+		day := Day{Date: d}
+		for ; i < len(events) && !events[i].Date.After(d); i++ {
+			day.Events = append(day.Events, events[i])
+			balance += events[i].Amount
+		}
+		day.Balance = round(balance)
+		day.BelowFloor = day.Balance < o.Floor
+		if len(f.Days) == 0 || day.Balance < f.Lowest.Balance {
+			f.Lowest = day
+		}
+		f.Days = append(f.Days, day)
+	}
+	return f
+}
+
+// nextPayday returns the nth payday after the income's last one. Twice-monthly income is paid on the 15th
+// and the last day of the month.
+func nextPayday(in Income, n int) time.Time {
+	last := truncate(in.Last)
+	switch in.Cadence {
+	case models.AllocationWeekly:
+		return last.AddDate(0, 0, 7*n)
+	case models.AllocationTwiceMonthly:
+		d := last
+		for i := 0; i < n; i++ {
+			monthEnd := time.Date(d.Year(), d.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+			switch {
+			case d.Day() < 15:
+				d = time.Date(d.Year(), d.Month(), 15, 0, 0, 0, 0, time.UTC)
+			case d.Before(monthEnd):
+				d = monthEnd
+			default:
+				d = time.Date(d.Year(), d.Month()+1, 15, 0, 0, 0, 0, time.UTC)
+			}
+		}
+		return d
+	case models.AllocationMonthly:
+		return last.AddDate(0, n, 0)
+	}
+	return last.AddDate(0, 0, 14*n)
+}
+
+// nextCharge returns a bill's nth charge after its next expected one, counted from it so monthly bills
+// keep their day of the month
+func nextCharge(b recurring.Subscription, n int) time.Time {
+	d := truncate(b.NextExpected)
+	switch b.Cadence {
+	case recurring.Weekly:
+		return d.AddDate(0, 0, 7*n)
+	case recurring.Monthly:
+		return d.AddDate(0, n, 0)
+	}
+	return d.AddDate(n, 0, 0)
+}
+
+// paymentDate returns the first day on or after start that is the payment day of its month
+func paymentDate(start time.Time, day int) time.Time {
+	for m := 0; ; m++ {
+		first := time.Date(start.Year(), start.Month()+time.Month(m), 1, 0, 0, 0, 0, time.UTC)
+		last := first.AddDate(0, 1, -1).Day()
+		d := first.AddDate(0, 0, min(max(day, 1), last)-1)
+		if !d.Before(start) {
+			return d
+		}
+	}
+}
+
+// truncate returns t's date at midnight UTC, as register dates are parsed
+func truncate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func round(f float64) float64 {
+	return math.Round(f*100) / 100
+}
@@ -0,0 +1,112 @@
+package forecast
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"register/pkg/models"
+	"register/pkg/recurring"
+)
+
+func day(s string) time.Time {
+	d, _ := time.Parse("2006-01-02", s)
+	return d
+}
+
+func TestProject(t *testing.T) {
+	o := Options{
+		Start:   time.Date(2026, time.September, 28, 15, 30, 0, 0, time.Local),
+		Days:    10,
+		Balance: 1000,
+		Floor:   200,
+		Incomes: []Income{
+			{Name: "Paycheck", Amount: 1500, Last: day("2026-09-18"), Cadence: models.AllocationEvery2Weeks},
+			{Name: "Bonus", Amount: 5000},
+		},
+		Bills: []recurring.Subscription{
+			{Name: "Rent", Cadence: recurring.Monthly, NextExpected: day("2026-10-01"), LastAmount: 1800},
+			{Name: "Gym", Cadence: recurring.Weekly, NextExpected: day("2026-09-26"), LastAmount: 10},
+			{Name: "Old Magazine", Cadence: recurring.Monthly, NextExpected: day("2026-10-02"), LastAmount: 5, Stopped: true},
+		},
+		Cards: []Card{
+			{Name: "Chase", Balance: 300, PaymentDay: 31},
+			{Name: "Citi", Balance: 0, PaymentDay: 5},
+		},
+	}
+	f := Project(o)
+
+	if len(f.Days) != 10 || !f.Days[0].Date.Equal(day("2026-09-28")) || !f.Days[9].Date.Equal(day("2026-10-07")) {
+		t.Fatalf("Project() days = %d from %v", len(f.Days), f.Days[0].Date)
+	}
+	balances := make(map[string]float64)
+	for _, d := range f.Days {
+		balances[d.Date.Format("2006-01-02")] = d.Balance
+	}
+	want := map[string]float64{
+		"2026-09-28": 990, // late gym charge expected today
+		"2026-09-29": 990,
+		"2026-09-30": 690, // Chase paid on the last day of September
+		"2026-10-01": -1110,
+		"2026-10-02": 390, // paycheck
+		"2026-10-03": 380, // gym
+		"2026-10-04": 380,
+		"2026-10-05": 380,
+		"2026-10-06": 380,
+		"2026-10-07": 380,
+	}
+	if !reflect.DeepEqual(balances, want) {
+		t.Errorf("Project() balances = %v, want %v", balances, want)
+	}
+
+	below := f.BelowFloor()
+	if len(below) != 1 || !below[0].Date.Equal(day("2026-10-01")) || len(below[0].Events) != 1 || below[0].Events[0].Name != "Rent" {
+		t.Errorf("BelowFloor() = %+v", below)
+	}
+	if !f.Lowest.Date.Equal(day("2026-10-01")) || f.Lowest.Balance != -1110 {
+		t.Errorf("Project() lowest = %+v", f.Lowest)
+	}
+}
+
+func Test_paymentDate(t *testing.T) {
+	tests := []struct {
+		name  string
+		start time.Time
+		day   int
+		want  time.Time
+	}{
+		{name: "Test later this month", start: day("2026-09-10"), day: 25, want: day("2026-09-25")},
+		{name: "Test today", start: day("2026-09-25"), day: 25, want: day("2026-09-25")},
+		{name: "Test next month", start: day("2026-09-26"), day: 25, want: day("2026-10-25")},
+		{name: "Test past the month's end", start: day("2026-02-10"), day: 31, want: day("2026-02-28")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := paymentDate(tt.start, tt.day); !got.Equal(tt.want) {
+				t.Errorf("paymentDate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_nextPayday(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Income
+		n    int
+		want time.Time
+	}{
+		{name: "Test every 2 weeks", in: Income{Last: day("2026-09-18"), Cadence: models.AllocationEvery2Weeks}, n: 2, want: day("2026-10-16")},
+		{name: "Test weekly", in: Income{Last: day("2026-09-18"), Cadence: models.AllocationWeekly}, n: 1, want: day("2026-09-25")},
+		{name: "Test monthly", in: Income{Last: day("2026-09-01"), Cadence: models.AllocationMonthly}, n: 2, want: day("2026-11-01")},
+		{name: "Test twice monthly from the 15th", in: Income{Last: day("2026-09-15"), Cadence: models.AllocationTwiceMonthly}, n: 1, want: day("2026-09-30")},
+		{name: "Test twice monthly from the month end", in: Income{Last: day("2026-09-30"), Cadence: models.AllocationTwiceMonthly}, n: 3, want: day("2026-11-15")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextPayday(tt.in, tt.n); !got.Equal(tt.want) {
+				t.Errorf("nextPayday() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"time"
+
 	"register/pkg/driver"
 	"register/pkg/models"
 	"register/pkg/repository"
@@ -55,6 +57,46 @@ func (q *Query) CreateMerchant(m *models.Merchant) {
 	q.repo.CreateMerchant(m)
 }
 
+// MigrateCatalog creates the columns and merchants tables
+func (q *Query) MigrateCatalog() error {
+	return q.repo.MigrateCatalog()
+}
+
+// GetMerchant ...
+func (q *Query) GetMerchant(id int) (*models.Merchant, error) {
+	return q.repo.GetMerchant(id)
+}
+
+// SaveMerchant creates or updates a merchant
+func (q *Query) SaveMerchant(m *models.Merchant) error {
+	return q.repo.SaveMerchant(m)
+}
+
+// DeleteMerchant ...
+func (q *Query) DeleteMerchant(id int) error {
+	return q.repo.DeleteMerchant(id)
+}
+
+// MergeMerchants maps the bank names of the fromIDs' merchants to the intoID merchant
+func (q *Query) MergeMerchants(intoID int, fromIDs []int) error {
+	return q.repo.MergeMerchants(intoID, fromIDs)
+}
+
+// GetColumn ...
+func (q *Query) GetColumn(id int) (*models.Column, error) {
+	return q.repo.GetColumn(id)
+}
+
+// SaveColumn creates or updates a column
+func (q *Query) SaveColumn(col *models.Column) error {
+	return q.repo.SaveColumn(col)
+}
+
+// DeleteColumn ...
+func (q *Query) DeleteColumn(id int) error {
+	return q.repo.DeleteColumn(id)
+}
+
 // GetLookupData ...
 func (q *Query) GetLookupData() []*models.DataRow {
 	return q.repo.GetLookupData()
@@ -65,6 +107,71 @@ func (q *Query) GetNameMapToColumn() map[string]string {
 	return q.repo.GetNameMapToColumn()
 }
 
+// MigrateUsers creates the users tables
+func (q *Query) MigrateUsers() error {
+	return q.repo.MigrateUsers()
+}
+
+// CreateUser ...
+func (q *Query) CreateUser(user *models.User) error {
+	return q.repo.CreateUser(user)
+}
+
+// GetUser ...
+func (q *Query) GetUser(username string) (*models.User, error) {
+	return q.repo.GetUser(username)
+}
+
+// GetUserByTokenHash ...
+func (q *Query) GetUserByTokenHash(hash string) (*models.User, error) {
+	return q.repo.GetUserByTokenHash(hash)
+}
+
+// SetUserAdmin ...
+func (q *Query) SetUserAdmin(username string, admin bool) error {
+	return q.repo.SetUserAdmin(username, admin)
+}
+
+// CreateAPIToken ...
+func (q *Query) CreateAPIToken(token *models.APIToken) error {
+	return q.repo.CreateAPIToken(token)
+}
+
+// GetUserItems ...
+func (q *Query) GetUserItems(userID uint) ([]models.UserItem, error) {
+	return q.repo.GetUserItems(userID)
+}
+
+// AddUserItem ...
+func (q *Query) AddUserItem(item *models.UserItem) error {
+	return q.repo.AddUserItem(item)
+}
+
+// MigrateEnvelopes creates the envelope events and rules tables
+func (q *Query) MigrateEnvelopes() error {
+	return q.repo.MigrateEnvelopes()
+}
+
+// AddEnvelopeEvents adds the events not yet recorded and returns the number added
+func (q *Query) AddEnvelopeEvents(events []models.EnvelopeEvent) (int64, error) {
+	return q.repo.AddEnvelopeEvents(events)
+}
+
+// GetEnvelopeEvents returns the events on or before the date
+func (q *Query) GetEnvelopeEvents(through time.Time) ([]models.EnvelopeEvent, error) {
+	return q.repo.GetEnvelopeEvents(through)
+}
+
+// GetEnvelopeRules ...
+func (q *Query) GetEnvelopeRules() ([]models.EnvelopeRule, error) {
+	return q.repo.GetEnvelopeRules()
+}
+
+// SaveEnvelopeRule creates or replaces a category's rollover rule
+func (q *Query) SaveEnvelopeRule(rule *models.EnvelopeRule) error {
+	return q.repo.SaveEnvelopeRule(rule)
+}
+
 // PrintData ...
 func (q *Query) PrintData() {
 	q.repo.PrintData()
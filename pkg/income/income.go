@@ -0,0 +1,112 @@
+package income
+
+import (
+	"fmt"
+	"regexp"
+
+	"register/pkg/models"
+)
+
+// Default is the income source used when none are configured: the paycheck the register was written for
+var Default = []models.IncomeSource{{
+	Name:        "50/50 Taphouse Paycheck",
+	Match:       "NOVA BEER LLC",
+	ColumnIndex: 42,
+	Color:       "green",
+	Allocation:  models.AllocationEvery2Weeks,
+	Cadence:     models.AllocationEvery2Weeks,
+}}
+
+var defaultSources = mustNew(Default)
+
+type source struct {
+	models.IncomeSource
+	re *regexp.Regexp
+}
+
+// Sources are the income sources deposits are matched against; nil Sources are the Default ones
+type Sources struct {
+	list []source
+}
+
+// New compiles the sources' match rules; no sources are the Default ones
+func New(sources []models.IncomeSource) (*Sources, error) {
+	if len(sources) == 0 {
+		sources = Default
+	}
+	s := &Sources{}
+	names := make(map[string]bool)
+	for _, src := range sources {
+		if src.Name == "" || src.Match == "" {
+			return nil, fmt.Errorf("income source %q needs a name and a match rule", src.Name)
+		}
+		if names[src.Name] {
+			return nil, fmt.Errorf("income source %q is listed twice", src.Name)
+		}
+		names[src.Name] = true
+		if !validAllocation(src.Allocation) || !validAllocation(src.Cadence) {
+			return nil, fmt.Errorf("income source %q: allocation and cadence must be weekly, every2weeks, twicemonthly, monthly or empty", src.Name)
+		}
+		re, err := regexp.Compile("(?i)" + src.Match)
+		if err != nil {
+			return nil, fmt.Errorf("income source %q: could not compile match: %s", src.Name, err.Error())
+		}
+		if src.Color == "" {
+			src.Color = "green"
+		}
+		s.list = append(s.list, source{IncomeSource: src, re: re})
+	}
+	return s, nil
+}
+
+func mustNew(sources []models.IncomeSource) *Sources {
+	s, err := New(sources)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+func validAllocation(a string) bool {
+	switch a {
+	case models.AllocationNone, models.AllocationWeekly, models.AllocationEvery2Weeks, models.AllocationTwiceMonthly, models.AllocationMonthly:
+		return true
+	}
+	return false
+}
+
+func (s *Sources) sources() []source {
+	if s == nil {
+		return defaultSources.list
+	}
+	return s.list
+}
+
+// Match returns the first source whose match rule matches the bank name, or nil
+func (s *Sources) Match(bankName string) *models.IncomeSource {
+	for _, src := range s.sources() {
+		if src.re.MatchString(bankName) {
+			return &src.IncomeSource
+		}
+	}
+	return nil
+}
+
+// Named returns the source with the register name, or nil
+func (s *Sources) Named(name string) *models.IncomeSource {
+	for _, src := range s.sources() {
+		if src.Name == name {
+			return &src.IncomeSource
+		}
+	}
+	return nil
+}
+
+// All returns the sources in configured order
+func (s *Sources) All() []models.IncomeSource {
+	all := make([]models.IncomeSource, 0, len(s.sources()))
+	for _, src := range s.sources() {
+		all = append(all, src.IncomeSource)
+	}
+	return all
+}
@@ -0,0 +1,71 @@
+package income
+
+import (
+	"testing"
+
+	"register/pkg/models"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		sources []models.IncomeSource
+		wantErr bool
+	}{
+		{name: "Test default", sources: nil},
+		{name: "Test valid", sources: []models.IncomeSource{
+			{Name: "Paycheck", Match: "ACME PAYROLL", Allocation: models.AllocationTwiceMonthly, Cadence: models.AllocationTwiceMonthly},
+			{Name: "Reimbursement", Match: "EXPENSIFY", ColumnIndex: 12},
+		}},
+		{name: "Test missing match", sources: []models.IncomeSource{{Name: "Paycheck"}}, wantErr: true},
+		{name: "Test duplicate name", sources: []models.IncomeSource{{Name: "Paycheck", Match: "A"}, {Name: "Paycheck", Match: "B"}}, wantErr: true},
+		{name: "Test bad cadence", sources: []models.IncomeSource{{Name: "Paycheck", Match: "A", Cadence: "daily"}}, wantErr: true},
+		{name: "Test bad match", sources: []models.IncomeSource{{Name: "Paycheck", Match: "("}}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New(tt.sources); (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSources_Match(t *testing.T) {
+	s, err := New([]models.IncomeSource{
+		{Name: "Paycheck", Match: "ACME PAYROLL"},
+		{Name: "Side Income", Match: "^venmo .*cashout", Color: "blue"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name     string
+		sources  *Sources
+		bankName string
+		want     string
+	}{
+		{name: "Test paycheck", sources: s, bankName: "ACME PAYROLL PPD ID: 123", want: "Paycheck"},
+		{name: "Test ignoring case", sources: s, bankName: "VENMO weekly CASHOUT", want: "Side Income"},
+		{name: "Test no match", sources: s, bankName: "SAFEWAY #1234", want: ""},
+		{name: "Test nil sources are the default", sources: nil, bankName: "NOVA BEER LLC PAYROLL", want: Default[0].Name},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ""
+			if src := tt.sources.Match(tt.bankName); src != nil {
+				got = src.Name
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if src := s.Named("Paycheck"); src == nil || src.Color != "green" {
+		t.Errorf("Named() = %+v, want the paycheck with the default color", src)
+	}
+	if src := s.Named("Bonus"); src != nil {
+		t.Errorf("Named() = %+v, want nil", src)
+	}
+}
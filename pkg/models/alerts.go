@@ -0,0 +1,31 @@
+package models
+
+// Alert sink types
+const (
+	AlertSinkSMTP    = "smtp"
+	AlertSinkWebhook = "webhook"
+	AlertSinkCommand = "command"
+	AlertSinkFile    = "file"
+)
+
+// AlertRules are the alerts evaluated after each update and where they are sent; a zero rule is off
+type AlertRules struct {
+	CategoryPercent  float64     `json:"categoryPercent"`  // a category's month spending reaches this percent of its budget
+	LargeTransaction float64     `json:"largeTransaction"` // a single purchase of at least this amount
+	NewMerchant      bool        `json:"newMerchant"`      // a purchase from a merchant not seen before
+	DuplicateDays    int         `json:"duplicateDays"`    // the same amount from the same merchant within this many days
+	Sinks            []AlertSink `json:"sinks"`
+}
+
+// AlertSink is one place alerts are delivered to; the fields used depend on Type
+type AlertSink struct {
+	Type     string   `json:"type"`     // AlertSinkSMTP, AlertSinkWebhook, AlertSinkCommand or AlertSinkFile
+	Addr     string   `json:"addr"`     // smtp: the server's host:port
+	Username string   `json:"username"` // smtp: optional, authenticates with PLAIN
+	Password string   `json:"password"`
+	From     string   `json:"from"`    // smtp
+	To       []string `json:"to"`      // smtp
+	URL      string   `json:"url"`     // webhook: receives a JSON POST of the alerts
+	Command  []string `json:"command"` // command: run once per alert with its title and message appended, eg. ["notify-send"]
+	File     string   `json:"file"`    // file: alerts are appended as JSON lines
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Envelope event kinds
+const (
+	EnvelopeFunding  = "funding"  // an income source's allocation to the category
+	EnvelopeSpending = "spending" // a transaction in the category column; refunds are positive
+	EnvelopeTransfer = "transfer" // money moved between two categories
+)
+
+// Envelope rollover rules, applied to a category's balance at the start of each month
+const (
+	RolloverCarry = "carry" // the balance carries over; the default
+	RolloverReset = "reset" // money left over is dropped, overspending carries over
+	RolloverCap   = "cap"   // the balance carried over is at most the rule's Cap
+)
+
+// EnvelopeEvent adds to or draws on a budget category's envelope
+type EnvelopeEvent struct {
+	gorm.Model
+	Date     time.Time `gorm:"index"`
+	Category string    `gorm:"uniqueIndex:idx_envelope_event;size:64"` // the category column name
+	Kind     string    `gorm:"size:16"`
+	Amount   float64   // positive adds to the envelope, negative draws on it
+	Key      string    `gorm:"uniqueIndex:idx_envelope_event;size:128"` // the bank transaction ID or key, so it is recorded once
+	Note     string
+}
+
+// EnvelopeRule is a category's rollover rule
+type EnvelopeRule struct {
+	gorm.Model
+	Category string `gorm:"uniqueIndex;size:64"`
+	Rollover string `gorm:"size:16"` // one of the Rollover constants
+	Cap      float64
+}
@@ -0,0 +1,23 @@
+package models
+
+// Income source allocations: the Budget sheet amounts entered across the category columns when the
+// income is recorded
+const (
+	AllocationNone         = ""
+	AllocationWeekly       = "weekly"
+	AllocationEvery2Weeks  = "every2weeks"
+	AllocationTwiceMonthly = "twicemonthly"
+	AllocationMonthly      = "monthly"
+)
+
+// IncomeSource is a kind of checking deposit, eg. a paycheck, side income, a bonus or a reimbursement
+type IncomeSource struct {
+	Name        string             `json:"name"`        // the register name, eg. "50/50 Taphouse Paycheck"
+	Match       string             `json:"match"`       // regular expression matched against the bank name, ignoring case
+	ColumnIndex int                `json:"columnIndex"` // the register column the deposit goes to
+	Color       string             `json:"color"`       // the row's color; default green
+	Allocation  string             `json:"allocation"`  // one of the Allocation constants
+	Allocations map[string]float64 `json:"allocations"` // amounts by category column that replace the Budget sheet's
+	Cadence     string             `json:"cadence"`     // how often it is paid, an Allocation constant; none for irregular income
+	Account     string             `json:"account"`     // the export account; default Income:Salary
+}
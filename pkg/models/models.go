@@ -1,6 +1,8 @@
 package models
 
 import (
+	"fmt"
+
 	"gorm.io/gorm"
 )
 
@@ -8,6 +10,7 @@ import (
 type Transaction struct {
 	gorm.Model
 	Key            string
+	TransactionID  string // the bank's stable id for the transaction, eg. OFX FITID or Plaid transaction_id
 	Source         string
 	Date           string
 	Name           string
@@ -24,8 +27,22 @@ type Transaction struct {
 	IsCategory     bool
 	TaxDeductible  bool
 	IsCheck        bool
+	MemberName     string // the cardholder on cards with more than one, eg. Costco Citi Visa
+	AccountID      string // the bank account the transaction was read from, eg. Plaid account_id
+}
+
+// OccurrenceKey returns the Key of the nth transaction with the same key, eg. the second of two splits with
+// the same amount: the key for the first, then the key with ":<n>" appended
+func OccurrenceKey(key string, n int) string {
+	if n <= 1 {
+		return key
+	}
+	return fmt.Sprintf("%s:%d", key, n)
 }
 
+// RegisterDateFormat is the format of a transaction's Date, the Register's date format, eg. 09/28/26
+const RegisterDateFormat = "01/02/06"
+
 // Merchant ...
 type Merchant struct {
 	gorm.Model
@@ -59,3 +76,25 @@ type DataRow struct {
 	IsCategory    bool
 	TaxDeductible bool
 }
+
+// Register columns an account's transactions are written to
+const (
+	AccountColumnsChecking = "checking" // Withdrawal and Deposit
+	AccountColumnsCredit   = "credit"   // Credit Purchases and Credit Card
+)
+
+// Item is one login at an institution, a Plaid item, and the accounts it holds
+type Item struct {
+	ID       string    `json:"id"` // names the access token file, <ID>AccessToken.txt in PlaidTokensDir
+	Accounts []Account `json:"accounts"`
+}
+
+// Account is one account of an item, eg. checking, savings or a card
+type Account struct {
+	ID          string `json:"id"` // Plaid account_id
+	Name        string `json:"name"`
+	Source      string `json:"source"`      // the register Source column and transaction key prefix
+	Columns     string `json:"columns"`     // AccountColumnsChecking or AccountColumnsCredit
+	Register    bool   `json:"register"`    // the account's transactions feed the register
+	BalanceCell string `json:"balanceCell"` // the register cell its balance is written to, eg. G1; optional
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// User is a local account of the API server
+type User struct {
+	gorm.Model
+	Username     string `gorm:"uniqueIndex;size:64"`
+	PasswordHash string `json:"-"` // bcrypt
+	Admin        bool   // may change the merchants and columns
+}
+
+// APIToken authenticates a user's API requests. Only the token's SHA-256 is stored.
+type APIToken struct {
+	gorm.Model
+	UserID    uint
+	Name      string
+	TokenHash string `gorm:"uniqueIndex;size:64" json:"-"`
+}
+
+// UserItem gives a user one of the configured bank items, a Plaid login
+type UserItem struct {
+	gorm.Model
+	UserID uint   `gorm:"uniqueIndex:idx_user_item"`
+	BankID string `gorm:"uniqueIndex:idx_user_item;size:64"`
+	ItemID string `gorm:"uniqueIndex:idx_user_item;size:64"`
+}
@@ -0,0 +1,164 @@
+package ofx
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"register/pkg/banking"
+	"register/pkg/config"
+	"register/pkg/models"
+	"register/pkg/statement"
+)
+
+// ConfigOptions ...
+type ConfigOptions struct {
+	FinanceDir string
+	Banks      map[string]config.Bank
+	Recorded   map[string]bool // the transaction IDs already recorded, eg. the transactions table's TransactionIDs
+}
+
+// Client reads OFX/QFX statement downloads named by each bank's OFXFileName from FinanceDir
+type Client struct {
+	FinanceDir string
+	Banks      map[string]config.Bank
+	Recorded   map[string]bool
+}
+
+// myReadFile is a function variable that can be reassigned to handle mocking for testing
+var myReadFile = os.ReadFile
+
+// New ...
+func New(o ConfigOptions) *Client {
+	return &Client{
+		FinanceDir: o.FinanceDir,
+		Banks:      o.Banks,
+		Recorded:   o.Recorded,
+	}
+}
+
+// HasFile returns true if the bank is configured with an OFX/QFX file
+func (c *Client) HasFile(bankID string) bool {
+	return c.Banks[bankID].OFXFileName != ""
+}
+
+// GetTransactions reads the statement for each bank, dropping transactions with an ID already seen in this
+// run or recorded, so a statement downloaded again is not imported twice. The ID is the FITID scoped to the
+// bank and account, as FITIDs are only unique within an account.
+func (c *Client) GetTransactions(bankIDs []string) ([]*models.Transaction, error) {
+	var trans []*models.Transaction
+	seen := make(map[string]bool)
+
+	for _, bankID := range bankIDs {
+		bank, statements, err := c.readStatements(bankID)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("    %s\n", bank.Name)
+		for _, s := range statements {
+			for _, t := range s.Transactions {
+				id := transactionID(bankID, s, t)
+				if id != "" && (seen[id] || c.Recorded[id]) {
+					continue
+				}
+				seen[id] = true
+				if s.IsCreditCard && isCardPayment(t) {
+					continue
+				}
+				tran := buildTransaction(bank, s, t)
+				tran.TransactionID = id
+				trans = append(trans, tran)
+			}
+		}
+	}
+	return trans, nil
+}
+
+// GetBalances returns the statement ledger balance (LEDGERBAL) of each bank's account, the statement whose
+// ACCTID is the bank's AccountID. A file of one statement needs no AccountID.
+func (c *Client) GetBalances(bankIDs []string) map[string]banking.Balance {
+	balances := make(map[string]banking.Balance, len(bankIDs))
+	for _, bankID := range bankIDs {
+		bank, statements, err := c.readStatements(bankID)
+		if err != nil {
+			balances[bankID] = banking.Balance{BankName: bank.Name, Error: err}
+			continue
+		}
+		s, err := accountStatement(bank, statements)
+		if err != nil {
+			balances[bankID] = banking.Balance{BankName: bank.Name, Error: err}
+			continue
+		}
+		amount := s.LedgerBalance
+		if s.IsCreditCard {
+			// credit card ledger balances are negative when money is owed; the register tracks the amount owed
+			amount = -amount
+		}
+		balances[bankID] = banking.Balance{BankName: bank.Name, Amount: amount}
+	}
+	return balances
+}
+
+func (c *Client) readStatements(bankID string) (config.Bank, []*Statement, error) {
+	bank, ok := c.Banks[bankID]
+	if !ok {
+		return bank, nil, fmt.Errorf("unknown bankID: %s", bankID)
+	}
+	if bank.OFXFileName == "" {
+		return bank, nil, fmt.Errorf("no OFX file configured for %s", bankID)
+	}
+
+	fileName := c.FinanceDir + "/" + bank.OFXFileName
+	data, err := myReadFile(fileName)
+	if err != nil {
+		return bank, nil, fmt.Errorf("could not read OFX file %s: %s", fileName, err.Error())
+	}
+	statements, err := Parse(data)
+	if err != nil {
+		return bank, nil, fmt.Errorf("could not parse OFX file %s: %s", fileName, err.Error())
+	}
+	return bank, statements, nil
+}
+
+// accountStatement returns the statement of the bank's AccountID, or the only statement if none is configured
+func accountStatement(bank config.Bank, statements []*Statement) (*Statement, error) {
+	if bank.AccountID == "" {
+		if len(statements) != 1 {
+			return nil, fmt.Errorf("%d accounts in the OFX file for %s; configure its accountID", len(statements), bank.ID)
+		}
+		return statements[0], nil
+	}
+	for _, s := range statements {
+		if s.AccountID == bank.AccountID {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("no account %s in the OFX file for %s", bank.AccountID, bank.ID)
+}
+
+// transactionID returns the FITID scoped to the bank and account, or "" if the transaction has no FITID
+func transactionID(bankID string, s *Statement, t *StatementTransaction) string {
+	if t.FITID == "" {
+		return ""
+	}
+	return bankID + ":" + s.AccountID + ":" + t.FITID
+}
+
+func isCardPayment(t *StatementTransaction) bool {
+	return t.Type == "PAYMENT" || statement.CardPaymentRe.MatchString(t.Name)
+}
+
+func buildTransaction(bank config.Bank, s *Statement, st *StatementTransaction) *models.Transaction {
+	name := strings.TrimSpace(st.Name)
+	if name == "" {
+		name = st.Memo
+	}
+	return statement.Entry{
+		Source:       bank.Source,
+		Date:         st.DatePosted.Format(models.RegisterDateFormat),
+		Description:  name,
+		CheckNum:     st.CheckNum,
+		Out:          -st.Amount,
+		IsCreditCard: s.IsCreditCard,
+	}.Transaction()
+}
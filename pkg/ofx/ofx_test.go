@@ -0,0 +1,276 @@
+package ofx
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"register/pkg/banking"
+	cfg "register/pkg/config"
+	"register/pkg/models"
+)
+
+const sgmlBankStatement = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+SECURITY:NONE
+ENCODING:USASCII
+
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0<SEVERITY>INFO</STATUS><DTSERVER>20260110120000<LANGUAGE>ENG</SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1>
+<STMTTRNRS>
+<TRNUID>1
+<STMTRS>
+<CURDEF>USD
+<BANKACCTFROM>
+<BANKID>121000248
+<ACCTID>1234567890
+<ACCTTYPE>CHECKING
+</BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>20260101
+<DTEND>20260110
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20260105120000.000[-5:EST]
+<TRNAMT>-75.53
+<FITID>202601050001
+<NAME>GLO FIBER BILLPAY
+<MEMO>AT&amp;T style memo
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CHECK
+<DTPOSTED>20260106
+<TRNAMT>-25.00
+<FITID>202601060002
+<CHECKNUM>0110
+<NAME>CHECK 110
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20260107
+<TRNAMT>2000.00
+<FITID>202601070003
+<NAME>NOVA BEER LLC PAYROLL
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20260107
+<TRNAMT>2000.00
+<FITID>202601070003
+<NAME>NOVA BEER LLC PAYROLL
+</STMTTRN>
+</BANKTRANLIST>
+<LEDGERBAL>
+<BALAMT>1899.47
+<DTASOF>20260110
+</LEDGERBAL>
+</STMTRS>
+</STMTTRNRS>
+</BANKMSGSRSV1>
+</OFX>
+`
+
+const xmlCardStatement = `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+  <CREDITCARDMSGSRSV1>
+    <CCSTMTTRNRS>
+      <TRNUID>1</TRNUID>
+      <CCSTMTRS>
+        <CURDEF>USD</CURDEF>
+        <CCACCTFROM><ACCTID>4111111111111111</ACCTID></CCACCTFROM>
+        <BANKTRANLIST>
+          <DTSTART>20260101</DTSTART>
+          <DTEND>20260110</DTEND>
+          <STMTTRN>
+            <TRNTYPE>DEBIT</TRNTYPE>
+            <DTPOSTED>20260103</DTPOSTED>
+            <TRNAMT>-45.10</TRNAMT>
+            <FITID>CC0001</FITID>
+            <NAME>KROGER #123</NAME>
+          </STMTTRN>
+          <STMTTRN>
+            <TRNTYPE>PAYMENT</TRNTYPE>
+            <DTPOSTED>20260104</DTPOSTED>
+            <TRNAMT>500.00</TRNAMT>
+            <FITID>CC0002</FITID>
+            <NAME>PAYMENT THANK YOU</NAME>
+          </STMTTRN>
+        </BANKTRANLIST>
+        <LEDGERBAL><BALAMT>-345.10</BALAMT><DTASOF>20260110</DTASOF></LEDGERBAL>
+      </CCSTMTRS>
+    </CCSTMTTRNRS>
+  </CREDITCARDMSGSRSV1>
+</OFX>
+`
+
+type fakeReadFiler struct {
+	files map[string]string
+}
+
+func (f fakeReadFiler) ReadFile(filename string) ([]byte, error) {
+	return io.ReadAll(bytes.NewBufferString(f.files[filename]))
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name            string
+		doc             string
+		wantAccount     string
+		wantCreditCard  bool
+		wantBalance     float64
+		wantTrans       int
+		wantFirstName   string
+		wantFirstAmount float64
+	}{
+		{
+			name:            "Test Parse OFX 1.x SGML",
+			doc:             sgmlBankStatement,
+			wantAccount:     "1234567890",
+			wantBalance:     1899.47,
+			wantTrans:       4,
+			wantFirstName:   "GLO FIBER BILLPAY",
+			wantFirstAmount: -75.53,
+		},
+		{
+			name:            "Test Parse OFX 2.x XML",
+			doc:             xmlCardStatement,
+			wantAccount:     "4111111111111111",
+			wantCreditCard:  true,
+			wantBalance:     -345.10,
+			wantTrans:       2,
+			wantFirstName:   "KROGER #123",
+			wantFirstAmount: -45.10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse([]byte(tt.doc))
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			s := got[0]
+			if s.AccountID != tt.wantAccount || s.IsCreditCard != tt.wantCreditCard || s.LedgerBalance != tt.wantBalance {
+				t.Errorf("Parse() statement = %+v", s)
+			}
+			if len(s.Transactions) != tt.wantTrans {
+				t.Fatalf("Parse() transactions = %d, want %d", len(s.Transactions), tt.wantTrans)
+			}
+			if s.Transactions[0].Name != tt.wantFirstName || s.Transactions[0].Amount != tt.wantFirstAmount {
+				t.Errorf("Parse() first transaction = %+v", s.Transactions[0])
+			}
+		})
+	}
+}
+
+func TestParse_memoAndCheckNum(t *testing.T) {
+	got, err := Parse([]byte(sgmlBankStatement))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	trans := got[0].Transactions
+	if trans[0].Memo != "AT&T style memo" {
+		t.Errorf("Parse() memo = %q", trans[0].Memo)
+	}
+	if trans[1].CheckNum != "110" {
+		t.Errorf("Parse() check number = %q", trans[1].CheckNum)
+	}
+	if want := time.Date(2026, time.January, 5, 0, 0, 0, 0, time.UTC); !trans[0].DatePosted.Equal(want) {
+		t.Errorf("Parse() date = %v, want %v", trans[0].DatePosted, want)
+	}
+}
+
+func TestParse_notOFX(t *testing.T) {
+	if _, err := Parse([]byte("Date,Amount,Description\n")); err == nil {
+		t.Errorf("Parse() expected an error")
+	}
+}
+
+func TestClient_GetTransactions(t *testing.T) {
+	myReadFile = fakeReadFiler{files: map[string]string{
+		"/finance/wf.ofx":       sgmlBankStatement,
+		"/finance/fidelity.qfx": xmlCardStatement,
+	}}.ReadFile
+
+	c := New(ConfigOptions{
+		FinanceDir: "/finance",
+		Banks: map[string]cfg.Bank{
+			"wellsfargo": {ID: "wellsfargo", Name: "Wells Fargo", Source: "WellsFargo", OFXFileName: "wf.ofx"},
+			"fidelity":   {ID: "fidelity", Name: "Fidelity", Source: "Fidelity", OFXFileName: "fidelity.qfx"},
+		},
+	})
+	got, err := c.GetTransactions([]string{"wellsfargo", "fidelity"})
+	if err != nil {
+		t.Fatalf("GetTransactions() error = %v", err)
+	}
+
+	want := []*models.Transaction{
+		{TransactionID: "wellsfargo:1234567890:202601050001", Key: "wellsfargo:01/05/26:75.53", Source: "WellsFargo", Date: "01/05/26", Name: "GLO FIBER BILLPAY", BankName: "GLO FIBER BILLPAY", Amount: 75.53, Withdrawal: 75.53, Budget: -75.53},
+		{TransactionID: "wellsfargo:1234567890:202601060002", Key: "110:01/06/26:25.00", Source: "110", Date: "01/06/26", Name: "CHECK", BankName: "CHECK", Amount: 25, Withdrawal: 25, Budget: -25, IsCheck: true},
+		{TransactionID: "wellsfargo:1234567890:202601070003", Key: "wellsfargo:01/07/26:2000.00", Source: "WellsFargo", Date: "01/07/26", Name: "NOVA BEER LLC PAYROLL", BankName: "NOVA BEER LLC PAYROLL", Amount: -2000, Deposit: 2000, Budget: 2000},
+		{TransactionID: "fidelity:4111111111111111:CC0001", Key: "fidelity:01/03/26:45.10", Source: "Fidelity", Date: "01/03/26", Name: "KROGER #123", BankName: "KROGER #123", Amount: 45.10, CreditPurchase: 45.10, CreditCard: 45.10, Budget: -45.10},
+	}
+	if !reflect.DeepEqual(got, want) {
+		for i := range got {
+			t.Logf("got[%d] = %+v", i, got[i])
+		}
+		t.Errorf("GetTransactions() did not match")
+	}
+
+	balances := c.GetBalances([]string{"wellsfargo", "fidelity"})
+	wantBalances := map[string]banking.Balance{
+		"wellsfargo": {BankName: "Wells Fargo", Amount: 1899.47},
+		"fidelity":   {BankName: "Fidelity", Amount: 345.10},
+	}
+	if !reflect.DeepEqual(balances, wantBalances) {
+		t.Errorf("GetBalances() = %v, want %v", balances, wantBalances)
+	}
+
+	// a statement downloaded again only adds the transactions not yet recorded; a FITID recorded for
+	// another account does not match
+	c.Recorded = map[string]bool{
+		"wellsfargo:1234567890:202601050001": true,
+		"wellsfargo:1234567890:202601060002": true,
+		"fidelity:4111111111111111:CC0001":   true,
+		"wellsfargo:9999999999:202601070003": true,
+	}
+	got, err = c.GetTransactions([]string{"wellsfargo", "fidelity"})
+	if err != nil {
+		t.Fatalf("GetTransactions() error = %v", err)
+	}
+	if len(got) != 1 || got[0].TransactionID != "wellsfargo:1234567890:202601070003" {
+		t.Errorf("GetTransactions() with recorded IDs = %v, want only 202601070003", got)
+	}
+}
+
+func Test_accountStatement(t *testing.T) {
+	checking := &Statement{AccountID: "1234567890"}
+	savings := &Statement{AccountID: "5555"}
+	tests := []struct {
+		name       string
+		accountID  string
+		statements []*Statement
+		want       *Statement
+		wantErr    bool
+	}{
+		{name: "Test one statement without an account", statements: []*Statement{checking}, want: checking},
+		{name: "Test the configured account", accountID: "5555", statements: []*Statement{checking, savings}, want: savings},
+		{name: "Test two statements without an account", statements: []*Statement{checking, savings}, wantErr: true},
+		{name: "Test a missing account", accountID: "7777", statements: []*Statement{checking}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := accountStatement(cfg.Bank{ID: "wellsfargo", AccountID: tt.accountID}, tt.statements)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("accountStatement() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("accountStatement() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
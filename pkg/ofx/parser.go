@@ -0,0 +1,218 @@
+package ofx
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// node is an OFX element. OFX 1.x is SGML where leaf elements have no closing tag; OFX 2.x is XML.
+// Both parse to the same tree.
+type node struct {
+	Name     string
+	Value    string
+	Children []*node
+}
+
+// Statement ...
+type Statement struct {
+	BankID            string
+	AccountID         string
+	AccountType       string
+	IsCreditCard      bool
+	Currency          string
+	Transactions      []*StatementTransaction
+	LedgerBalance     float64
+	LedgerBalanceDate time.Time
+}
+
+// StatementTransaction ...
+type StatementTransaction struct {
+	Type       string
+	DatePosted time.Time
+	Amount     float64
+	FITID      string
+	CheckNum   string
+	Name       string
+	Memo       string
+}
+
+var tokenRe = regexp.MustCompile(`<(/?)([A-Za-z0-9.]+)[^>]*>|([^<]+)`)
+
+// Parse reads the statements from an OFX 1.x (SGML) or 2.x (XML) document
+func Parse(data []byte) ([]*Statement, error) {
+	root, err := parseTree(string(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var statements []*Statement
+	for _, n := range root.findAll("STMTRS") {
+		s, err := buildStatement(n, false)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, s)
+	}
+	for _, n := range root.findAll("CCSTMTRS") {
+		s, err := buildStatement(n, true)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, s)
+	}
+	if len(statements) == 0 {
+		return nil, fmt.Errorf("no bank or credit card statements found")
+	}
+	return statements, nil
+}
+
+func parseTree(doc string) (*node, error) {
+	start := strings.Index(strings.ToUpper(doc), "<OFX>")
+	if start < 0 {
+		return nil, fmt.Errorf("not an OFX document: no <OFX> element")
+	}
+
+	root := &node{}
+	stack := []*node{root}
+	for _, m := range tokenRe.FindAllStringSubmatch(doc[start:], -1) {
+		top := stack[len(stack)-1]
+		switch {
+		case m[3] != "":
+			// text belongs to the element just opened; in SGML it also closes that element
+			text := strings.TrimSpace(m[3])
+			if text == "" || len(stack) == 1 {
+				continue
+			}
+			top.Value = decodeEntities(text)
+			stack = stack[:len(stack)-1]
+		case m[1] == "/":
+			name := strings.ToUpper(m[2])
+			// a closing tag for a leaf already closed by its value
+			if len(top.Children) > 0 && top.Children[len(top.Children)-1].Name == name && top.Name != name {
+				continue
+			}
+			// pop to the matching aggregate, closing any SGML leaves without values
+			for i := len(stack) - 1; i > 0; i-- {
+				if stack[i].Name == name {
+					stack = stack[:i]
+					break
+				}
+			}
+		default:
+			n := &node{Name: strings.ToUpper(m[2])}
+			top.Children = append(top.Children, n)
+			stack = append(stack, n)
+		}
+	}
+	return root, nil
+}
+
+func (n *node) find(name string) *node {
+	for _, c := range n.Children {
+		if c.Name == name {
+			return c
+		}
+	}
+	for _, c := range n.Children {
+		if f := c.find(name); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+func (n *node) findAll(name string) []*node {
+	var found []*node
+	for _, c := range n.Children {
+		if c.Name == name {
+			found = append(found, c)
+		} else {
+			found = append(found, c.findAll(name)...)
+		}
+	}
+	return found
+}
+
+func (n *node) value(path ...string) string {
+	cur := n
+	for _, p := range path {
+		if cur = cur.find(p); cur == nil {
+			return ""
+		}
+	}
+	return cur.Value
+}
+
+func buildStatement(n *node, isCreditCard bool) (*Statement, error) {
+	s := &Statement{
+		BankID:       n.value("BANKID"),
+		AccountID:    n.value("ACCTID"),
+		AccountType:  n.value("ACCTTYPE"),
+		IsCreditCard: isCreditCard,
+		Currency:     n.value("CURDEF"),
+	}
+	if isCreditCard {
+		s.AccountType = "CREDITCARD"
+	}
+
+	if bal := n.find("LEDGERBAL"); bal != nil {
+		amount, err := parseAmount(bal.value("BALAMT"))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse LEDGERBAL: %s", err.Error())
+		}
+		s.LedgerBalance = amount
+		s.LedgerBalanceDate, _ = parseDate(bal.value("DTASOF"))
+	}
+
+	if list := n.find("BANKTRANLIST"); list != nil {
+		for _, t := range list.findAll("STMTTRN") {
+			trans, err := buildStatementTransaction(t)
+			if err != nil {
+				return nil, err
+			}
+			s.Transactions = append(s.Transactions, trans)
+		}
+	}
+	return s, nil
+}
+
+func buildStatementTransaction(n *node) (*StatementTransaction, error) {
+	amount, err := parseAmount(n.value("TRNAMT"))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse TRNAMT for FITID %s: %s", n.value("FITID"), err.Error())
+	}
+	date, err := parseDate(n.value("DTPOSTED"))
+	if err != nil {
+		return nil, fmt.Errorf("could not parse DTPOSTED for FITID %s: %s", n.value("FITID"), err.Error())
+	}
+	return &StatementTransaction{
+		Type:       n.value("TRNTYPE"),
+		DatePosted: date,
+		Amount:     amount,
+		FITID:      n.value("FITID"),
+		CheckNum:   strings.TrimLeft(n.value("CHECKNUM"), "0"),
+		Name:       n.value("NAME"),
+		Memo:       n.value("MEMO"),
+	}, nil
+}
+
+// parseDate reads an OFX datetime such as 20260105, 20260105120000 or 20260105120000.000[-5:EST]
+func parseDate(s string) (time.Time, error) {
+	if len(s) < 8 {
+		return time.Time{}, fmt.Errorf("invalid date: %q", s)
+	}
+	return time.Parse("20060102", s[:8])
+}
+
+// parseAmount reads an OFX amount, which may use a comma as the decimal separator
+func parseAmount(s string) (float64, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", ".")
+	return strconv.ParseFloat(s, 64)
+}
+
+func decodeEntities(s string) string {
+	return strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'", "&nbsp;", " ").Replace(s)
+}
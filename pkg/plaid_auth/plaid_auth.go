@@ -9,7 +9,20 @@ import (
 )
 
 func GetLinkToken(c *banking.Client) (string, error) {
-	ctx := context.Background()
+	request := newLinkTokenRequest(c)
+	request.SetProducts([]plaid.Products{plaid.PRODUCTS_TRANSACTIONS})
+	return createLinkToken(c, request)
+}
+
+// GetUpdateLinkToken returns a Link token in update mode for an existing item, so the user can re-authenticate
+// it, eg. after ITEM_LOGIN_REQUIRED. The item keeps its access token.
+func GetUpdateLinkToken(c *banking.Client, accessToken string) (string, error) {
+	request := newLinkTokenRequest(c)
+	request.SetAccessToken(accessToken)
+	return createLinkToken(c, request)
+}
+
+func newLinkTokenRequest(c *banking.Client) *plaid.LinkTokenCreateRequest {
 	user := plaid.LinkTokenCreateRequestUser{
 		ClientUserId: c.UserID,
 	}
@@ -22,10 +35,16 @@ func GetLinkToken(c *banking.Client) (string, error) {
 	)
 	request.SetClientId(c.ClientID)
 	request.SetSecret(c.Secret)
-	request.SetProducts([]plaid.Products{plaid.PRODUCTS_TRANSACTIONS})
 	request.SetLinkCustomizationName("default")
 	request.SetRedirectUri("https://localhost:9000/public/oauth.html")
+	if c.WebhookURL != "" {
+		request.SetWebhook(c.WebhookURL)
+	}
+	return request
+}
 
+func createLinkToken(c *banking.Client, request *plaid.LinkTokenCreateRequest) (string, error) {
+	ctx := context.Background()
 	resp, _, err := c.PlaidClient.PlaidApi.LinkTokenCreate(ctx).LinkTokenCreateRequest(*request).Execute()
 	if err != nil {
 		return "", err
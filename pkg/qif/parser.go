@@ -0,0 +1,143 @@
+package qif
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// BankType ...
+	BankType = "Bank"
+	// CreditCardType ...
+	CreditCardType = "CCard"
+)
+
+// Record is one QIF transaction, terminated by a ^ line
+type Record struct {
+	Type     string
+	Date     time.Time
+	Amount   float64 // negative for money out, as QIF writes it
+	CheckNum string
+	Payee    string
+	Memo     string
+	Category string
+	Cleared  string
+	Splits   []*Split
+}
+
+// Split is one S/E/$ group of a split transaction
+type Split struct {
+	Category string
+	Memo     string
+	Amount   float64
+}
+
+// Read parses the Bank and CCard transactions of a QIF file; records of other types (Invst, Cat, Class...) are skipped
+func Read(r io.Reader) ([]*Record, error) {
+	var records []*Record
+	var rec *Record
+	var split *Split
+	recType := ""
+	lineNum := 0
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if lineNum == 1 {
+			line = strings.TrimPrefix(line, "\ufeff")
+		}
+
+		if strings.HasPrefix(line, "!") {
+			recType = ""
+			if strings.HasPrefix(strings.ToLower(line), "!type:") {
+				recType = normalizeType(line[len("!type:"):])
+			}
+			continue
+		}
+		if recType == "" {
+			// options, account lists and other headers we do not read
+			continue
+		}
+
+		if rec == nil {
+			rec = &Record{Type: recType}
+			split = nil
+		}
+		code, value := line[0], strings.TrimSpace(line[1:])
+		var err error
+		switch code {
+		case '^':
+			records = append(records, rec)
+			rec = nil
+		case 'D':
+			rec.Date, err = parseDate(value)
+		case 'T', 'U':
+			rec.Amount, err = parseAmount(value)
+		case 'N':
+			rec.CheckNum = value
+		case 'P':
+			rec.Payee = value
+		case 'M':
+			rec.Memo = value
+		case 'L':
+			rec.Category = value
+		case 'C':
+			rec.Cleared = value
+		case 'S':
+			split = &Split{Category: value}
+			rec.Splits = append(rec.Splits, split)
+		case 'E':
+			if split != nil {
+				split.Memo = value
+			}
+		case '$':
+			if split != nil {
+				split.Amount, err = parseAmount(value)
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s", lineNum, err.Error())
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if rec != nil {
+		// tolerate a missing ^ after the last record
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+func normalizeType(t string) string {
+	switch strings.ToLower(strings.TrimSpace(t)) {
+	case "bank", "cash":
+		return BankType
+	case "ccard":
+		return CreditCardType
+	}
+	return ""
+}
+
+// parseDate reads the QIF date forms 01/05/2026, 1/ 5/26, 1/5'26 (Quicken's form for 2000 onwards) and 2026-01-05
+func parseDate(s string) (time.Time, error) {
+	s = strings.ReplaceAll(strings.ReplaceAll(s, " ", ""), "'", "/")
+	for _, layout := range []string{"1/2/2006", "1/2/06", "2006-01-02", "1-2-06"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid date: %q", s)
+}
+
+func parseAmount(s string) (float64, error) {
+	return strconv.ParseFloat(strings.ReplaceAll(strings.ReplaceAll(s, ",", ""), "$", ""), 64)
+}
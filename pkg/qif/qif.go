@@ -0,0 +1,113 @@
+package qif
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"register/pkg/config"
+	"register/pkg/models"
+	"register/pkg/statement"
+)
+
+// ConfigOptions ...
+type ConfigOptions struct {
+	FinanceDir string
+	Banks      map[string]config.Bank
+}
+
+// Client reads QIF exports named by each bank's QIFFileName from FinanceDir
+type Client struct {
+	FinanceDir string
+	Banks      map[string]config.Bank
+}
+
+// myReadFile is a function variable that can be reassigned to handle mocking for testing
+var myReadFile = os.ReadFile
+
+var checkNumRe = regexp.MustCompile(`^\d+$`)
+
+// New ...
+func New(o ConfigOptions) *Client {
+	return &Client{
+		FinanceDir: o.FinanceDir,
+		Banks:      o.Banks,
+	}
+}
+
+// HasFile returns true if the bank is configured with a QIF file
+func (c *Client) HasFile(bankID string) bool {
+	return c.Banks[bankID].QIFFileName != ""
+}
+
+// GetTransactions reads the QIF file for each bank. A split transaction becomes one transaction per split.
+func (c *Client) GetTransactions(bankIDs []string) ([]*models.Transaction, error) {
+	var trans []*models.Transaction
+	for _, bankID := range bankIDs {
+		bank, ok := c.Banks[bankID]
+		if !ok {
+			return nil, fmt.Errorf("unknown bankID: %s", bankID)
+		}
+		if bank.QIFFileName == "" {
+			return nil, fmt.Errorf("no QIF file configured for %s", bankID)
+		}
+
+		fileName := c.FinanceDir + "/" + bank.QIFFileName
+		data, err := myReadFile(fileName)
+		if err != nil {
+			return nil, fmt.Errorf("could not read QIF file %s: %s", fileName, err.Error())
+		}
+		records, err := Read(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("could not parse QIF file %s: %s", fileName, err.Error())
+		}
+
+		fmt.Printf("    %s\n", bank.Name)
+		for _, rec := range records {
+			if rec.Type == CreditCardType && statement.CardPaymentRe.MatchString(rec.Payee) {
+				continue
+			}
+			trans = append(trans, buildTransactions(bank, rec)...)
+		}
+	}
+	return trans, nil
+}
+
+func buildTransactions(bank config.Bank, rec *Record) []*models.Transaction {
+	if len(rec.Splits) == 0 {
+		return []*models.Transaction{buildTransaction(bank, rec, rec.Amount, rec.Memo)}
+	}
+	// splits of the same amount get distinct keys so they are not taken for one another
+	occurrences := make(map[string]int)
+	trans := make([]*models.Transaction, 0, len(rec.Splits))
+	for _, s := range rec.Splits {
+		note := s.Memo
+		if note == "" {
+			note = s.Category
+		}
+		t := buildTransaction(bank, rec, s.Amount, note)
+		occurrences[t.Key]++
+		t.Key = models.OccurrenceKey(t.Key, occurrences[t.Key])
+		trans = append(trans, t)
+	}
+	return trans
+}
+
+func buildTransaction(bank config.Bank, rec *Record, qifAmount float64, note string) *models.Transaction {
+	e := statement.Entry{
+		Source:       bank.Source,
+		Date:         rec.Date.Format(models.RegisterDateFormat),
+		Description:  rec.Payee,
+		Out:          -qifAmount,
+		IsCreditCard: rec.Type == CreditCardType,
+	}
+	// N also holds non-numeric values such as ATM, DEP or EFT
+	if checkNumRe.MatchString(rec.CheckNum) {
+		e.CheckNum = strings.TrimLeft(rec.CheckNum, "0")
+	}
+	tran := e.Transaction()
+	tran.Note = note
+	return tran
+}
@@ -0,0 +1,216 @@
+package qif
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	cfg "register/pkg/config"
+	"register/pkg/models"
+)
+
+const bankQIF = `!Type:Bank
+D01/05/2026
+T-75.53
+PGLO FIBER BILLPAY
+MInternet
+^
+D1/ 6'26
+T-25.00
+N0110
+PCHECK 110
+^
+D01/07/26
+T2,000.00
+NDEP
+PNOVA BEER LLC PAYROLL
+^
+D01/08/2026
+T-120.00
+PCOSTCO
+SGroceries
+EFood
+$-80.00
+SClothing & Household
+$-40.00
+^
+`
+
+const cardQIF = `!Option:AutoSwitch
+!Account
+NChase
+TCCard
+^
+!Clear:AutoSwitch
+!Type:CCard
+D01/03/2026
+T-45.10
+PKROGER #123
+^
+D01/04/2026
+T500.00
+PPAYMENT THANK YOU
+^
+D01/05/2026
+T-12.00
+PNETFLIX`
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name        string
+		doc         string
+		wantRecords int
+		wantType    string
+		wantSplits  []int
+	}{
+		{name: "Test Read Bank", doc: bankQIF, wantRecords: 4, wantType: BankType, wantSplits: []int{0, 0, 0, 2}},
+		{name: "Test Read CCard without trailing ^", doc: cardQIF, wantRecords: 3, wantType: CreditCardType, wantSplits: []int{0, 0, 0}},
+		{name: "Test Read CRLF", doc: strings.ReplaceAll(bankQIF, "\n", "\r\n"), wantRecords: 4, wantType: BankType, wantSplits: []int{0, 0, 0, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Read(strings.NewReader(tt.doc))
+			if err != nil {
+				t.Fatalf("Read() error = %v", err)
+			}
+			if len(got) != tt.wantRecords {
+				t.Fatalf("Read() records = %d, want %d", len(got), tt.wantRecords)
+			}
+			for i, rec := range got {
+				if rec.Type != tt.wantType || len(rec.Splits) != tt.wantSplits[i] {
+					t.Errorf("Read() record %d = %+v", i, rec)
+				}
+			}
+		})
+	}
+}
+
+func TestRead_fields(t *testing.T) {
+	got, err := Read(strings.NewReader(bankQIF))
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if want := time.Date(2026, time.January, 6, 0, 0, 0, 0, time.UTC); !got[1].Date.Equal(want) {
+		t.Errorf("Read() date = %v, want %v", got[1].Date, want)
+	}
+	if got[0].Memo != "Internet" || got[1].CheckNum != "0110" || got[2].Amount != 2000 {
+		t.Errorf("Read() = %+v %+v %+v", got[0], got[1], got[2])
+	}
+	want := []*Split{{Category: "Groceries", Memo: "Food", Amount: -80}, {Category: "Clothing & Household", Amount: -40}}
+	if !reflect.DeepEqual(got[3].Splits, want) {
+		t.Errorf("Read() splits = %+v, want %+v", got[3].Splits, want)
+	}
+}
+
+func TestRead_badDate(t *testing.T) {
+	if _, err := Read(strings.NewReader("!Type:Bank\nDyesterday\n^\n")); err == nil {
+		t.Errorf("Read() expected an error")
+	}
+}
+
+func TestClient_GetTransactions(t *testing.T) {
+	myReadFile = func(filename string) ([]byte, error) {
+		return []byte(map[string]string{"/finance/wf.qif": bankQIF, "/finance/chase.qif": cardQIF}[filename]), nil
+	}
+
+	c := New(ConfigOptions{
+		FinanceDir: "/finance",
+		Banks: map[string]cfg.Bank{
+			"wellsfargo": {ID: "wellsfargo", Name: "Wells Fargo", Source: "WellsFargo", QIFFileName: "wf.qif"},
+			"chase":      {ID: "chase", Name: "Chase", Source: "Chase", QIFFileName: "chase.qif"},
+		},
+	})
+	got, err := c.GetTransactions([]string{"wellsfargo", "chase"})
+	if err != nil {
+		t.Fatalf("GetTransactions() error = %v", err)
+	}
+
+	want := []*models.Transaction{
+		{Key: "wellsfargo:01/05/26:75.53", Source: "WellsFargo", Date: "01/05/26", Name: "GLO FIBER BILLPAY", BankName: "GLO FIBER BILLPAY", Note: "Internet", Amount: 75.53, Withdrawal: 75.53, Budget: -75.53},
+		{Key: "110:01/06/26:25.00", Source: "110", Date: "01/06/26", Name: "CHECK", BankName: "CHECK", Amount: 25, Withdrawal: 25, Budget: -25, IsCheck: true},
+		{Key: "wellsfargo:01/07/26:2000.00", Source: "WellsFargo", Date: "01/07/26", Name: "NOVA BEER LLC PAYROLL", BankName: "NOVA BEER LLC PAYROLL", Amount: -2000, Deposit: 2000, Budget: 2000},
+		{Key: "wellsfargo:01/08/26:80.00", Source: "WellsFargo", Date: "01/08/26", Name: "COSTCO", BankName: "COSTCO", Note: "Food", Amount: 80, Withdrawal: 80, Budget: -80},
+		{Key: "wellsfargo:01/08/26:40.00", Source: "WellsFargo", Date: "01/08/26", Name: "COSTCO", BankName: "COSTCO", Note: "Clothing & Household", Amount: 40, Withdrawal: 40, Budget: -40},
+		{Key: "chase:01/03/26:45.10", Source: "Chase", Date: "01/03/26", Name: "KROGER #123", BankName: "KROGER #123", Amount: 45.10, CreditPurchase: 45.10, CreditCard: 45.10, Budget: -45.10},
+		{Key: "chase:01/05/26:12.00", Source: "Chase", Date: "01/05/26", Name: "NETFLIX", BankName: "NETFLIX", Amount: 12, CreditPurchase: 12, CreditCard: 12, Budget: -12},
+	}
+	if !reflect.DeepEqual(got, want) {
+		for i := range got {
+			t.Logf("got[%d] = %+v", i, got[i])
+		}
+		t.Errorf("GetTransactions() did not match")
+	}
+}
+
+func Test_buildTransactions_sameAmountSplits(t *testing.T) {
+	rec := &Record{
+		Type:   BankType,
+		Date:   time.Date(2026, time.January, 8, 0, 0, 0, 0, time.UTC),
+		Payee:  "COSTCO",
+		Amount: -120,
+		Splits: []*Split{{Category: "Groceries", Amount: -40}, {Category: "Groceries", Amount: -40}, {Category: "Clothing & Household", Amount: -40}},
+	}
+	var got []string
+	for _, t := range buildTransactions(cfg.Bank{Source: "WellsFargo"}, rec) {
+		got = append(got, t.Key)
+	}
+	want := []string{"wellsfargo:01/08/26:40.00", "wellsfargo:01/08/26:40.00:2", "wellsfargo:01/08/26:40.00:3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("buildTransactions() keys = %v, want %v", got, want)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	trans := []models.Transaction{
+		{Source: "Chase", Date: "01/05/26", Name: "Kroger", CreditCard: 45.10, ColumnIndex: 11, Note: "party food"},
+		{Source: "1042", Date: "01/03/26", Name: "CHECK", Withdrawal: 25, IsCheck: true, ColumnIndex: 12},
+		{Source: "WellsFargo", Date: "01/02/26", Name: "Paycheck", Deposit: 2000},
+	}
+	columns := []models.Column{
+		{Name: "Groceries", ColumnIndex: 11, IsCategory: true},
+		{Name: "Clothing & Household", ColumnIndex: 12, IsCategory: true},
+	}
+	want := `!Account
+NChase
+TCCard
+^
+!Type:CCard
+D01/05/2026
+T-45.10
+PKroger
+Mparty food
+LGroceries
+^
+!Account
+NChecking
+TBank
+^
+!Type:Bank
+D01/03/2026
+T-25.00
+N1042
+PCHECK
+LClothing & Household
+^
+D01/02/2026
+T2000.00
+PPaycheck
+^
+`
+
+	var buf bytes.Buffer
+	if err := Write(&buf, trans, columns); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got := buf.String(); got != want {
+		t.Errorf("Write() = \n%v\nwant\n%v", got, want)
+	}
+
+	// what we write reads back
+	records, err := Read(&buf)
+	if err != nil || len(records) != 3 {
+		t.Errorf("Read() of written QIF = %d records, err %v", len(records), err)
+	}
+}
@@ -0,0 +1,114 @@
+package qif
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"register/pkg/export"
+	"register/pkg/models"
+)
+
+const qifDateFormat = "01/02/2006"
+
+// CheckingAccountName is the !Account name used for Wells Fargo and checks
+const CheckingAccountName = "Checking"
+
+// Write writes the transactions as QIF, one !Account block per source: checking (Wells Fargo and checks) as
+// Bank and each credit card as CCard. The budget category column is written as the L category.
+func Write(w io.Writer, trans []models.Transaction, cols []models.Column) error {
+	columns := make(map[int]string)
+	for _, c := range cols {
+		if c.IsCategory {
+			columns[c.ColumnIndex] = c.Name
+		}
+	}
+
+	accounts := make(map[string][]models.Transaction)
+	for _, t := range trans {
+		account := t.Source
+		if t.IsCheck || export.SourceAccount(t.Source) == export.CheckingAccount {
+			account = CheckingAccountName
+		}
+		accounts[account] = append(accounts[account], t)
+	}
+	names := make([]string, 0, len(accounts))
+	for name := range accounts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		recType := CreditCardType
+		if name == CheckingAccountName {
+			recType = BankType
+		}
+		if _, err := fmt.Fprintf(w, "!Account\nN%s\nT%s\n^\n!Type:%s\n", name, recType, recType); err != nil {
+			return err
+		}
+		for _, t := range accounts[name] {
+			rec, err := buildRecord(t, recType, columns)
+			if err != nil {
+				return err
+			}
+			if err := writeRecord(w, rec); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func buildRecord(t models.Transaction, recType string, columns map[int]string) (*Record, error) {
+	date, err := time.Parse(models.RegisterDateFormat, t.Date)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse date %s for %s: %s", t.Date, t.Key, err.Error())
+	}
+	rec := &Record{
+		Type:     recType,
+		Date:     date,
+		Payee:    t.Name,
+		Memo:     t.Note,
+		Category: columns[t.ColumnIndex],
+	}
+	if rec.Payee == "" {
+		rec.Payee = t.BankName
+	}
+	if t.IsCheck {
+		rec.CheckNum = t.Source
+	}
+	if recType == CreditCardType {
+		rec.Amount = -t.CreditCard
+	} else {
+		rec.Amount = t.Deposit - t.Withdrawal
+	}
+	return rec, nil
+}
+
+func writeRecord(w io.Writer, rec *Record) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "D%s\n", rec.Date.Format(qifDateFormat))
+	fmt.Fprintf(&b, "T%.2f\n", rec.Amount)
+	if rec.CheckNum != "" {
+		fmt.Fprintf(&b, "N%s\n", rec.CheckNum)
+	}
+	fmt.Fprintf(&b, "P%s\n", rec.Payee)
+	if rec.Memo != "" {
+		fmt.Fprintf(&b, "M%s\n", rec.Memo)
+	}
+	if rec.Category != "" {
+		fmt.Fprintf(&b, "L%s\n", rec.Category)
+	}
+	for _, s := range rec.Splits {
+		fmt.Fprintf(&b, "S%s\n", s.Category)
+		if s.Memo != "" {
+			fmt.Fprintf(&b, "E%s\n", s.Memo)
+		}
+		fmt.Fprintf(&b, "$%.2f\n", s.Amount)
+	}
+	b.WriteString("^\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
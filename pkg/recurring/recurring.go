@@ -0,0 +1,233 @@
+package recurring
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"register/api/services/sheets_service"
+	"register/pkg/models"
+)
+
+// Cadence ...
+type Cadence string
+
+const (
+	// Weekly ...
+	Weekly Cadence = "weekly"
+	// Monthly ...
+	Monthly Cadence = "monthly"
+	// Yearly ...
+	Yearly Cadence = "yearly"
+
+	// MaxStepChange is the largest change in amount, as a fraction, from one charge to the next of a subscription
+	MaxStepChange = 0.3
+	// MaxOutlierShare is the share of a merchant's charges, at least one, that can fall outside its cadence, eg.
+	// an extra charge or one after a skipped month
+	MaxOutlierShare = 0.2
+)
+
+// cadences are the intervals charges recur at, in days, with the days late a charge can be before the
+// subscription is taken to have stopped
+var cadences = []struct {
+	cadence  Cadence
+	min, max float64
+	grace    int
+	minCount int
+}{
+	{Weekly, 5, 9, 4, 3},
+	{Monthly, 26, 35, 10, 3},
+	{Yearly, 350, 380, 30, 2},
+}
+
+// Charge is one purchase from a merchant
+type Charge struct {
+	Name   string
+	Date   time.Time
+	Amount float64
+}
+
+// PriceChange is a change in a subscription's amount
+type PriceChange struct {
+	Date time.Time
+	From float64
+	To   float64
+}
+
+// Subscription is a merchant charging at a regular interval
+type Subscription struct {
+	Name          string
+	Cadence       Cadence
+	Charges       int
+	First         time.Time
+	Last          time.Time
+	LastAmount    float64
+	AverageAmount float64
+	NextExpected  time.Time
+	PriceChanges  []PriceChange
+	Stopped       bool // no charge since NextExpected plus the cadence's grace days
+	PriceChanged  bool // the last charge's amount differs from the one before
+}
+
+// FromTransactions returns the purchases of stored transactions: those taking money from a budget category
+func FromTransactions(trans []models.Transaction) []Charge {
+	var charges []Charge
+	for _, t := range trans {
+		date, err := time.Parse(models.RegisterDateFormat, t.Date)
+		if err != nil || t.Budget >= 0 {
+			continue
+		}
+		charges = append(charges, Charge{Name: chargeName(t.Name, t.BankName), Date: date, Amount: -t.Budget})
+	}
+	return charges
+}
+
+// FromRegister returns the purchases of the register sheet: its withdrawals and credit card charges
+func FromRegister(entries []*sheets_service.RegisterEntry) []Charge {
+	var charges []Charge
+	for _, e := range entries {
+		date, err := time.Parse(models.RegisterDateFormat, e.Date)
+		amount := e.Withdrawal + e.CreditCard
+		if err != nil || amount <= 0 {
+			continue
+		}
+		charges = append(charges, Charge{Name: chargeName(e.Name, ""), Date: date, Amount: amount})
+	}
+	return charges
+}
+
+func chargeName(name, bankName string) string {
+	if name == "" {
+		return strings.TrimSpace(bankName)
+	}
+	return strings.TrimSpace(name)
+}
+
+// Detect finds the merchants whose charges recur weekly, monthly or yearly, by their median interval, with an
+// amount that is stable, changes by at most MaxStepChange at a time or changes for good, eg. a price rise the
+// next charge repeats. Up to MaxOutlierShare of the charges can be outliers. Subscriptions are sorted by
+// name; asOf decides which stopped.
+func Detect(charges []Charge, asOf time.Time) []Subscription {
+	byName := make(map[string][]Charge)
+	for _, c := range charges {
+		if c.Name != "" {
+			byName[c.Name] = append(byName[c.Name], c)
+		}
+	}
+
+	var subs []Subscription
+	for name, cs := range byName {
+		sort.SliceStable(cs, func(i, j int) bool { return cs[i].Date.Before(cs[j].Date) })
+		if s, ok := detect(name, cs, asOf); ok {
+			subs = append(subs, s)
+		}
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].Name < subs[j].Name })
+	return subs
+}
+
+func detect(name string, cs []Charge, asOf time.Time) (Subscription, bool) {
+	if len(cs) < 2 {
+		return Subscription{}, false
+	}
+	var intervals []float64
+	for i := 1; i < len(cs); i++ {
+		intervals = append(intervals, cs[i].Date.Sub(cs[i-1].Date).Hours()/24)
+	}
+	interval := median(intervals)
+
+	for _, c := range cadences {
+		if interval < c.min || interval > c.max {
+			continue
+		}
+		regular, outliers := regularCharges(cs, c.min, c.max)
+		if len(regular) < c.minCount || outliers > maxOutliers(len(cs)) {
+			continue
+		}
+		last := regular[len(regular)-1]
+		s := Subscription{
+			Name:         name,
+			Cadence:      c.cadence,
+			Charges:      len(regular),
+			First:        regular[0].Date,
+			Last:         last.Date,
+			LastAmount:   last.Amount,
+			NextExpected: next(last.Date, c.cadence),
+		}
+		total := 0.0
+		for i, ch := range regular {
+			total += ch.Amount
+			if i > 0 && math.Abs(ch.Amount-regular[i-1].Amount) >= 0.01 {
+				s.PriceChanges = append(s.PriceChanges, PriceChange{Date: ch.Date, From: regular[i-1].Amount, To: ch.Amount})
+			}
+		}
+		s.AverageAmount = math.Round(total/float64(len(regular))*100) / 100
+		s.Stopped = asOf.After(s.NextExpected.AddDate(0, 0, c.grace))
+		s.PriceChanged = len(s.PriceChanges) > 0 && s.PriceChanges[len(s.PriceChanges)-1].Date.Equal(s.Last)
+		return s, true
+	}
+	return Subscription{}, false
+}
+
+// regularCharges returns the charges that keep to an interval of lo to hi days and an amount that changes by
+// at most MaxStepChange, and the number of outliers: extra charges, which are left out, and charges after a
+// longer interval, eg. a skipped month, which are kept. A larger change in amount is a price change when the
+// next charge repeats the new amount.
+func regularCharges(cs []Charge, lo, hi float64) ([]Charge, int) {
+	regular := []Charge{cs[0]}
+	outliers := 0
+	for i := 1; i < len(cs); i++ {
+		ch := cs[i]
+		prev := regular[len(regular)-1]
+		days := ch.Date.Sub(prev.Date).Hours() / 24
+		if days < lo || (!similar(prev.Amount, ch.Amount) && !repeated(cs, i, lo)) {
+			outliers++
+			continue
+		}
+		if days > hi {
+			outliers++
+		}
+		regular = append(regular, ch)
+	}
+	return regular, outliers
+}
+
+// similar returns true if the amount changes by at most MaxStepChange
+func similar(from, to float64) bool {
+	return math.Abs(to-from) <= MaxStepChange*from
+}
+
+// repeated returns true if the charge after cs[i], at least lo days later, repeats its amount
+func repeated(cs []Charge, i int, lo float64) bool {
+	if i+1 >= len(cs) {
+		return false
+	}
+	next := cs[i+1]
+	return next.Date.Sub(cs[i].Date).Hours()/24 >= lo && similar(cs[i].Amount, next.Amount)
+}
+
+// maxOutliers returns the number of a merchant's charges that can be outliers
+func maxOutliers(charges int) int {
+	return max(1, int(MaxOutlierShare*float64(charges)))
+}
+
+func median(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+func next(last time.Time, cadence Cadence) time.Time {
+	switch cadence {
+	case Weekly:
+		return last.AddDate(0, 0, 7)
+	case Monthly:
+		return last.AddDate(0, 1, 0)
+	}
+	return last.AddDate(1, 0, 0)
+}
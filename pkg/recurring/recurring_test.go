@@ -0,0 +1,160 @@
+package recurring
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"register/pkg/models"
+)
+
+func date(s string) time.Time {
+	d, _ := time.Parse(models.RegisterDateFormat, s)
+	return d
+}
+
+func TestDetect(t *testing.T) {
+	asOf := date("09/20/26")
+	tests := []struct {
+		name    string
+		charges []Charge
+		want    []Subscription
+	}{
+		{
+			name: "Test monthly with a price change",
+			charges: []Charge{
+				{Name: "Netflix", Date: date("06/04/26"), Amount: 15.49},
+				{Name: "Netflix", Date: date("07/04/26"), Amount: 15.49},
+				{Name: "Netflix", Date: date("08/05/26"), Amount: 15.49},
+				{Name: "Netflix", Date: date("09/04/26"), Amount: 18.49},
+			},
+			want: []Subscription{{
+				Name: "Netflix", Cadence: Monthly, Charges: 4, First: date("06/04/26"), Last: date("09/04/26"),
+				LastAmount: 18.49, AverageAmount: 16.24, NextExpected: date("10/04/26"),
+				PriceChanges: []PriceChange{{Date: date("09/04/26"), From: 15.49, To: 18.49}}, PriceChanged: true,
+			}},
+		},
+		{
+			name: "Test weekly that stopped",
+			charges: []Charge{
+				{Name: "Car Wash", Date: date("08/01/26"), Amount: 12},
+				{Name: "Car Wash", Date: date("08/08/26"), Amount: 12},
+				{Name: "Car Wash", Date: date("08/15/26"), Amount: 12},
+			},
+			want: []Subscription{{
+				Name: "Car Wash", Cadence: Weekly, Charges: 3, First: date("08/01/26"), Last: date("08/15/26"),
+				LastAmount: 12, AverageAmount: 12, NextExpected: date("08/22/26"), Stopped: true,
+			}},
+		},
+		{
+			name: "Test yearly",
+			charges: []Charge{
+				{Name: "Costco Membership", Date: date("03/10/25"), Amount: 60},
+				{Name: "Costco Membership", Date: date("03/12/26"), Amount: 65},
+			},
+			want: []Subscription{{
+				Name: "Costco Membership", Cadence: Yearly, Charges: 2, First: date("03/10/25"), Last: date("03/12/26"),
+				LastAmount: 65, AverageAmount: 62.5, NextExpected: date("03/12/27"),
+				PriceChanges: []PriceChange{{Date: date("03/12/26"), From: 60, To: 65}}, PriceChanged: true,
+			}},
+		},
+		{
+			name: "Test monthly with a skipped month",
+			charges: []Charge{
+				{Name: "Hulu", Date: date("04/05/26"), Amount: 7.99},
+				{Name: "Hulu", Date: date("05/05/26"), Amount: 7.99},
+				{Name: "Hulu", Date: date("07/05/26"), Amount: 7.99},
+				{Name: "Hulu", Date: date("08/05/26"), Amount: 7.99},
+				{Name: "Hulu", Date: date("09/05/26"), Amount: 7.99},
+			},
+			want: []Subscription{{
+				Name: "Hulu", Cadence: Monthly, Charges: 5, First: date("04/05/26"), Last: date("09/05/26"),
+				LastAmount: 7.99, AverageAmount: 7.99, NextExpected: date("10/05/26"),
+			}},
+		},
+		{
+			name: "Test monthly with an extra charge",
+			charges: []Charge{
+				{Name: "Disney Plus", Date: date("05/12/26"), Amount: 13.99},
+				{Name: "Disney Plus", Date: date("06/12/26"), Amount: 13.99},
+				{Name: "Disney Plus", Date: date("06/20/26"), Amount: 4.99},
+				{Name: "Disney Plus", Date: date("07/12/26"), Amount: 13.99},
+				{Name: "Disney Plus", Date: date("08/12/26"), Amount: 13.99},
+				{Name: "Disney Plus", Date: date("09/12/26"), Amount: 13.99},
+			},
+			want: []Subscription{{
+				Name: "Disney Plus", Cadence: Monthly, Charges: 5, First: date("05/12/26"), Last: date("09/12/26"),
+				LastAmount: 13.99, AverageAmount: 13.99, NextExpected: date("10/12/26"),
+			}},
+		},
+		{
+			name: "Test monthly with a lasting price rise",
+			charges: []Charge{
+				{Name: "Max", Date: date("10/15/25"), Amount: 9.99},
+				{Name: "Max", Date: date("11/15/25"), Amount: 9.99},
+				{Name: "Max", Date: date("12/15/25"), Amount: 9.99},
+				{Name: "Max", Date: date("01/15/26"), Amount: 9.99},
+				{Name: "Max", Date: date("02/15/26"), Amount: 9.99},
+				{Name: "Max", Date: date("03/15/26"), Amount: 9.99},
+				{Name: "Max", Date: date("04/15/26"), Amount: 9.99},
+				{Name: "Max", Date: date("05/15/26"), Amount: 9.99},
+				{Name: "Max", Date: date("06/15/26"), Amount: 15.49},
+				{Name: "Max", Date: date("07/15/26"), Amount: 15.49},
+				{Name: "Max", Date: date("08/15/26"), Amount: 15.49},
+				{Name: "Max", Date: date("09/15/26"), Amount: 15.49},
+			},
+			want: []Subscription{{
+				Name: "Max", Cadence: Monthly, Charges: 12, First: date("10/15/25"), Last: date("09/15/26"),
+				LastAmount: 15.49, AverageAmount: 11.82, NextExpected: date("10/15/26"),
+				PriceChanges: []PriceChange{{Date: date("06/15/26"), From: 9.99, To: 15.49}},
+			}},
+		},
+		{
+			name: "Test too many outliers are not a subscription",
+			charges: []Charge{
+				{Name: "Uber", Date: date("01/01/26"), Amount: 20},
+				{Name: "Uber", Date: date("02/01/26"), Amount: 20},
+				{Name: "Uber", Date: date("04/01/26"), Amount: 20},
+				{Name: "Uber", Date: date("05/01/26"), Amount: 20},
+				{Name: "Uber", Date: date("07/01/26"), Amount: 20},
+				{Name: "Uber", Date: date("08/01/26"), Amount: 20},
+			},
+		},
+		{
+			name: "Test irregular amounts and intervals are not subscriptions",
+			charges: []Charge{
+				{Name: "Safeway", Date: date("09/01/26"), Amount: 80},
+				{Name: "Safeway", Date: date("09/08/26"), Amount: 140},
+				{Name: "Safeway", Date: date("09/15/26"), Amount: 60},
+				{Name: "Shell", Date: date("07/01/26"), Amount: 40},
+				{Name: "Shell", Date: date("07/12/26"), Amount: 40},
+				{Name: "Shell", Date: date("08/20/26"), Amount: 40},
+				{Name: "Spotify", Date: date("08/20/26"), Amount: 11.99},
+				{Name: "Spotify", Date: date("09/20/26"), Amount: 11.99},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Detect(tt.charges, asOf); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Detect() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFromTransactions(t *testing.T) {
+	trans := []models.Transaction{
+		{Date: "09/04/26", Name: "Netflix", Budget: -17.99},
+		{Date: "09/05/26", BankName: " HULU 877 ", Budget: -7.99},
+		{Date: "09/10/26", Name: "Salary", Budget: 5000},
+		{Date: "bad", Name: "Netflix", Budget: -17.99},
+	}
+	want := []Charge{
+		{Name: "Netflix", Date: date("09/04/26"), Amount: 17.99},
+		{Name: "HULU 877", Date: date("09/05/26"), Amount: 7.99},
+	}
+	if got := FromTransactions(trans); !reflect.DeepEqual(got, want) {
+		t.Errorf("FromTransactions() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"errors"
+
+	"register/pkg/models"
+
+	"gorm.io/gorm"
+)
+
+// Catalog errors
+var (
+	ErrColumnInUse   = errors.New("column has merchants mapped to it")
+	ErrUnknownColumn = errors.New("no such column")
+)
+
+// Catalog edits the merchants and the register columns they are mapped to. The SQL is the same for each
+// database, so the query repos embed it.
+type Catalog struct {
+	Conn *gorm.DB
+}
+
+// MigrateCatalog creates or updates the columns and merchants tables
+func (c *Catalog) MigrateCatalog() error {
+	return c.Conn.AutoMigrate(&models.Column{}, &models.Merchant{})
+}
+
+// GetMerchant ...
+func (c *Catalog) GetMerchant(id int) (*models.Merchant, error) {
+	var m models.Merchant
+	if err := c.Conn.Preload("Column").First(&m, id).Error; err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// SaveMerchant creates the merchant, or updates it if it has an ID
+func (c *Catalog) SaveMerchant(m *models.Merchant) error {
+	if err := c.columnExists(m.ColumnID); err != nil {
+		return err
+	}
+	if m.ID == 0 {
+		return c.Conn.Omit("Column").Create(m).Error
+	}
+	if err := c.Conn.First(&models.Merchant{}, m.ID).Error; err != nil {
+		return err
+	}
+	return c.Conn.Model(&models.Merchant{}).Where("id = ?", m.ID).Updates(map[string]interface{}{
+		"bank_name":      m.BankName,
+		"name":           m.Name,
+		"column_id":      m.ColumnID,
+		"tax_deductible": m.TaxDeductible,
+	}).Error
+}
+
+// DeleteMerchant ...
+func (c *Catalog) DeleteMerchant(id int) error {
+	result := c.Conn.Delete(&models.Merchant{}, id)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+// MergeMerchants merges duplicate merchants into one. Every bank name mapped to one of the fromIDs' merchant
+// names is mapped to the into merchant's name and column instead; bank names it already has are dropped.
+func (c *Catalog) MergeMerchants(intoID int, fromIDs []int) error {
+	return c.Conn.Transaction(func(tx *gorm.DB) error {
+		var into models.Merchant
+		if err := tx.First(&into, intoID).Error; err != nil {
+			return err
+		}
+		for _, id := range fromIDs {
+			var from models.Merchant
+			if err := tx.First(&from, id).Error; err != nil {
+				return err
+			}
+			if from.Name == into.Name {
+				continue
+			}
+			err := tx.Model(&models.Merchant{}).Where("name = ?", from.Name).Updates(map[string]interface{}{
+				"name":           into.Name,
+				"column_id":      into.ColumnID,
+				"tax_deductible": into.TaxDeductible,
+			}).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		var merged []models.Merchant
+		if err := tx.Where("name = ?", into.Name).Order("id").Find(&merged).Error; err != nil {
+			return err
+		}
+		seen := make(map[string]bool)
+		for _, m := range merged {
+			if seen[m.BankName] {
+				if err := tx.Delete(&models.Merchant{}, m.ID).Error; err != nil {
+					return err
+				}
+			}
+			seen[m.BankName] = true
+		}
+		return nil
+	})
+}
+
+// GetColumn ...
+func (c *Catalog) GetColumn(id int) (*models.Column, error) {
+	var col models.Column
+	if err := c.Conn.First(&col, id).Error; err != nil {
+		return nil, err
+	}
+	return &col, nil
+}
+
+// SaveColumn creates the column, or updates it if it has an ID
+func (c *Catalog) SaveColumn(col *models.Column) error {
+	if col.ID == 0 {
+		return c.Conn.Create(col).Error
+	}
+	if err := c.Conn.First(&models.Column{}, col.ID).Error; err != nil {
+		return err
+	}
+	return c.Conn.Model(&models.Column{}).Where("id = ?", col.ID).Updates(map[string]interface{}{
+		"name":         col.Name,
+		"color":        col.Color,
+		"column_index": col.ColumnIndex,
+		"letter":       col.Letter,
+		"is_category":  col.IsCategory,
+	}).Error
+}
+
+// DeleteColumn deletes a column no merchant is mapped to
+func (c *Catalog) DeleteColumn(id int) error {
+	var n int64
+	if err := c.Conn.Model(&models.Merchant{}).Where("column_id = ?", id).Count(&n).Error; err != nil {
+		return err
+	}
+	if n > 0 {
+		return ErrColumnInUse
+	}
+	result := c.Conn.Delete(&models.Column{}, id)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
+
+func (c *Catalog) columnExists(id int) error {
+	var n int64
+	if err := c.Conn.Model(&models.Column{}).Where("id = ?", id).Count(&n).Error; err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrUnknownColumn
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"time"
+
+	"register/pkg/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Envelopes are the budget categories' funding, spending and transfer events and their rollover rules.
+// The SQL is the same for each database, so the query repos embed it.
+type Envelopes struct {
+	Conn *gorm.DB
+}
+
+// MigrateEnvelopes creates or updates the envelope events and rules tables
+func (e *Envelopes) MigrateEnvelopes() error {
+	return e.Conn.AutoMigrate(&models.EnvelopeEvent{}, &models.EnvelopeRule{})
+}
+
+// AddEnvelopeEvents adds the events in one transaction and returns the number added. Events already
+// recorded, with the same key and category, are skipped.
+func (e *Envelopes) AddEnvelopeEvents(events []models.EnvelopeEvent) (int64, error) {
+	if len(events) == 0 {
+		return 0, nil
+	}
+	result := e.Conn.Clauses(clause.OnConflict{DoNothing: true}).Create(&events)
+	return result.RowsAffected, result.Error
+}
+
+// GetEnvelopeEvents returns the events on or before the date, oldest first
+func (e *Envelopes) GetEnvelopeEvents(through time.Time) ([]models.EnvelopeEvent, error) {
+	var events []models.EnvelopeEvent
+	err := e.Conn.Where("date <= ?", through).Order("date, id").Find(&events).Error
+	return events, err
+}
+
+// GetEnvelopeRules ...
+func (e *Envelopes) GetEnvelopeRules() ([]models.EnvelopeRule, error) {
+	var rules []models.EnvelopeRule
+	err := e.Conn.Order("category").Find(&rules).Error
+	return rules, err
+}
+
+// SaveEnvelopeRule creates the category's rule, or replaces the one it has
+func (e *Envelopes) SaveEnvelopeRule(rule *models.EnvelopeRule) error {
+	return e.Conn.Where(models.EnvelopeRule{Category: rule.Category}).
+		Assign(map[string]interface{}{"rollover": rule.Rollover, "cap": rule.Cap}).
+		FirstOrCreate(rule).Error
+}
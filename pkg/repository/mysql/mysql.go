@@ -11,12 +11,18 @@ import (
 
 type mysqlQueryRepo struct {
 	Conn *gorm.DB
+	repo.Users
+	repo.Catalog
+	repo.Envelopes
 }
 
 // NewMySQLQueryRepo ...
 func NewMySQLQueryRepo(conn *gorm.DB) repo.QueryRepo {
 	return &mysqlQueryRepo{
-		Conn: conn,
+		Conn:      conn,
+		Users:     repo.Users{Conn: conn},
+		Catalog:   repo.Catalog{Conn: conn},
+		Envelopes: repo.Envelopes{Conn: conn},
 	}
 }
 
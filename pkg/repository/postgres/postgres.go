@@ -12,12 +12,18 @@ import (
 
 type postgresQueryRepo struct {
 	Conn *gorm.DB
+	repo.Users
+	repo.Catalog
+	repo.Envelopes
 }
 
 // NewPostgreSQLQueryRepo returns the implementation of post repository interface
 func NewPostgreSQLQueryRepo(conn *gorm.DB) repo.QueryRepo {
 	return &postgresQueryRepo{
-		Conn: conn,
+		Conn:      conn,
+		Users:     repo.Users{Conn: conn},
+		Catalog:   repo.Catalog{Conn: conn},
+		Envelopes: repo.Envelopes{Conn: conn},
 	}
 }
 
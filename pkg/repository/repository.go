@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"register/pkg/models"
 
 	"gorm.io/gorm"
@@ -10,10 +12,18 @@ import (
 type QueryRepo interface {
 	CreateDB(dbName string) (*gorm.DB, error)
 
+	MigrateCatalog() error
 	GetColumns() []models.Column
+	GetColumn(id int) (*models.Column, error)
+	SaveColumn(col *models.Column) error
+	DeleteColumn(id int) error
 
 	GetMerchants() []models.Merchant
 	CreateMerchant(m *models.Merchant)
+	GetMerchant(id int) (*models.Merchant, error)
+	SaveMerchant(m *models.Merchant) error
+	DeleteMerchant(id int) error
+	MergeMerchants(intoID int, fromIDs []int) error
 
 	GetTransactions() []models.Transaction
 	SaveTransaction(trans *models.Transaction)
@@ -22,6 +32,21 @@ type QueryRepo interface {
 	GetLookupData() []*models.DataRow
 	GetNameMapToColumn() map[string]string
 
+	MigrateUsers() error
+	CreateUser(user *models.User) error
+	GetUser(username string) (*models.User, error)
+	GetUserByTokenHash(hash string) (*models.User, error)
+	SetUserAdmin(username string, admin bool) error
+	CreateAPIToken(token *models.APIToken) error
+	GetUserItems(userID uint) ([]models.UserItem, error)
+	AddUserItem(item *models.UserItem) error
+
+	MigrateEnvelopes() error
+	AddEnvelopeEvents(events []models.EnvelopeEvent) (int64, error)
+	GetEnvelopeEvents(through time.Time) ([]models.EnvelopeEvent, error)
+	GetEnvelopeRules() ([]models.EnvelopeRule, error)
+	SaveEnvelopeRule(rule *models.EnvelopeRule) error
+
 	PrintData()
 	PrintTable(table string)
 }
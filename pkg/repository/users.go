@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"register/pkg/models"
+
+	"gorm.io/gorm"
+)
+
+// Users are the API server's users, their API tokens and items. The SQL is the same for each database,
+// so the query repos embed it.
+type Users struct {
+	Conn *gorm.DB
+}
+
+// MigrateUsers creates or updates the users, API tokens and user items tables
+func (u *Users) MigrateUsers() error {
+	return u.Conn.AutoMigrate(&models.User{}, &models.APIToken{}, &models.UserItem{})
+}
+
+// CreateUser ...
+func (u *Users) CreateUser(user *models.User) error {
+	return u.Conn.Create(user).Error
+}
+
+// GetUser returns the user by username
+func (u *Users) GetUser(username string) (*models.User, error) {
+	var user models.User
+	if err := u.Conn.Where("username = ?", username).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUserByTokenHash returns the user holding the API token with this SHA-256
+func (u *Users) GetUserByTokenHash(hash string) (*models.User, error) {
+	var token models.APIToken
+	if err := u.Conn.Where("token_hash = ?", hash).First(&token).Error; err != nil {
+		return nil, err
+	}
+	var user models.User
+	if err := u.Conn.First(&user, token.UserID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// SetUserAdmin makes the user an admin, who may change the merchants and columns, or not
+func (u *Users) SetUserAdmin(username string, admin bool) error {
+	res := u.Conn.Model(&models.User{}).Where("username = ?", username).Update("admin", admin)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CreateAPIToken ...
+func (u *Users) CreateAPIToken(token *models.APIToken) error {
+	return u.Conn.Create(token).Error
+}
+
+// GetUserItems returns the user's bank items
+func (u *Users) GetUserItems(userID uint) ([]models.UserItem, error) {
+	var items []models.UserItem
+	err := u.Conn.Where("user_id = ?", userID).Order("bank_id, item_id").Find(&items).Error
+	return items, err
+}
+
+// AddUserItem gives the user a bank item; adding an item the user already has is not an error
+func (u *Users) AddUserItem(item *models.UserItem) error {
+	return u.Conn.Where(models.UserItem{UserID: item.UserID, BankID: item.BankID, ItemID: item.ItemID}).
+		FirstOrCreate(item).Error
+}
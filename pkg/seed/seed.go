@@ -0,0 +1,246 @@
+package seed
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"register/pkg/models"
+
+	"github.com/gocarina/gocsv"
+)
+
+// Seed files read from the seed directory
+const (
+	ColumnsFileName      = "columns.csv"       // Name, Color, Category: the register columns in sheet order
+	MerchToColsFileName  = "merch_to_cats.csv" // Merchant, Column Name: each merchant name's column
+	MerchantsFileName    = "merchants.csv"     // Bank Name, Name: each bank transaction name's merchant name
+	placeholderColumnFmt = "old-%d"            // names a column left blank in columns.csv, an unused sheet column
+)
+
+// Store is the database seeded; handler.Query is one
+type Store interface {
+	MigrateCatalog() error
+	GetColumns() []models.Column
+	SaveColumn(col *models.Column) error
+	GetMerchants() []models.Merchant
+	SaveMerchant(m *models.Merchant) error
+}
+
+type columnRow struct {
+	Name     string `csv:"Name"`
+	Color    string `csv:"Color"`
+	Category string `csv:"Category"`
+}
+
+type merchToColRow struct {
+	Merchant   string `csv:"Merchant"`
+	ColumnName string `csv:"Column Name"`
+}
+
+type merchantRow struct {
+	BankName string `csv:"Bank Name"`
+	Name     string `csv:"Name"`
+}
+
+// Merchant is a merchant to seed, by its column's name
+type Merchant struct {
+	BankName   string
+	Name       string
+	ColumnName string
+}
+
+// Data are the validated seed files
+type Data struct {
+	Columns   []models.Column
+	Merchants []Merchant
+	Skipped   []string // merchants whose name is mapped to no column
+}
+
+// Problem is one invalid row of a seed file
+type Problem struct {
+	File string
+	Line int
+	Msg  string
+}
+
+// Problems are every invalid row of the seed files; nothing is written when there are any
+type Problems []Problem
+
+func (p Problems) Error() string {
+	msgs := make([]string, 0, len(p))
+	for _, problem := range p {
+		msgs = append(msgs, fmt.Sprintf("%s line %d: %s", problem.File, problem.Line, problem.Msg))
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Load reads and validates the seed files in dir
+func Load(dir string) (*Data, error) {
+	var columnRows []*columnRow
+	var merchToColRows []*merchToColRow
+	var merchantRows []*merchantRow
+	files := []struct {
+		name string
+		rows interface{}
+	}{
+		{ColumnsFileName, &columnRows},
+		{MerchToColsFileName, &merchToColRows},
+		{MerchantsFileName, &merchantRows},
+	}
+	for _, f := range files {
+		if err := readCSV(filepath.Join(dir, f.name), f.rows); err != nil {
+			return nil, err
+		}
+	}
+
+	var problems Problems
+	data := &Data{}
+
+	// line numbers count the header as line 1
+	columns := make(map[string]bool)
+	for i, r := range columnRows {
+		name := strings.TrimSpace(r.Name)
+		if name == "" {
+			name = fmt.Sprintf(placeholderColumnFmt, i)
+		}
+		isCategory, err := strconv.ParseBool(r.Category)
+		if err != nil {
+			problems = append(problems, Problem{ColumnsFileName, i + 2, fmt.Sprintf("Category %q is not true or false", r.Category)})
+		}
+		if columns[name] {
+			problems = append(problems, Problem{ColumnsFileName, i + 2, fmt.Sprintf("column %q is listed twice", name)})
+		}
+		columns[name] = true
+		data.Columns = append(data.Columns, models.Column{Name: name, Color: r.Color, ColumnIndex: i, IsCategory: isCategory})
+	}
+
+	merchToCol := make(map[string]string)
+	for i, r := range merchToColRows {
+		colName, seen := merchToCol[r.Merchant]
+		switch {
+		case r.Merchant == "":
+			problems = append(problems, Problem{MerchToColsFileName, i + 2, "Merchant is empty"})
+		case seen && colName != r.ColumnName:
+			problems = append(problems, Problem{MerchToColsFileName, i + 2,
+				fmt.Sprintf("merchant %q is mapped to both %q and %q", r.Merchant, colName, r.ColumnName)})
+		case r.ColumnName != "" && !columns[r.ColumnName]:
+			problems = append(problems, Problem{MerchToColsFileName, i + 2, fmt.Sprintf("column %q is not in %s", r.ColumnName, ColumnsFileName)})
+		}
+		merchToCol[r.Merchant] = r.ColumnName
+	}
+
+	bankNames := make(map[string]bool)
+	for i, r := range merchantRows {
+		colName, ok := merchToCol[r.Name]
+		switch {
+		case r.BankName == "" || r.Name == "":
+			problems = append(problems, Problem{MerchantsFileName, i + 2, "Bank Name and Name are required"})
+		case bankNames[r.BankName]:
+			problems = append(problems, Problem{MerchantsFileName, i + 2, fmt.Sprintf("bank name %q is listed twice", r.BankName)})
+		case !ok:
+			problems = append(problems, Problem{MerchantsFileName, i + 2, fmt.Sprintf("merchant %q is not in %s", r.Name, MerchToColsFileName)})
+		case colName == "":
+			data.Skipped = append(data.Skipped, fmt.Sprintf("%s (%s)", r.Name, r.BankName))
+		default:
+			data.Merchants = append(data.Merchants, Merchant{BankName: r.BankName, Name: r.Name, ColumnName: colName})
+		}
+		bankNames[r.BankName] = true
+	}
+
+	if len(problems) > 0 {
+		return nil, problems
+	}
+	return data, nil
+}
+
+func readCSV(fileName string, rows interface{}) error {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return fmt.Errorf("could not open seed file: %s", err.Error())
+	}
+	defer f.Close()
+
+	err = gocsv.UnmarshalCSV(gocsv.CSVReader(csvReader(f)), rows)
+	if err != nil {
+		return fmt.Errorf("could not read %s: %s", fileName, err.Error())
+	}
+	return nil
+}
+
+// csvReader trims the space after commas, as in the "Bank Name, Name" header of merchants.csv
+func csvReader(r io.Reader) *csv.Reader {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	return reader
+}
+
+// Result counts the rows written by Seed
+type Result struct {
+	ColumnsCreated   int
+	ColumnsUpdated   int
+	MerchantsCreated int
+	MerchantsUpdated int
+	Unchanged        int
+}
+
+// Seed upserts the columns, by name, and then the merchants, by bank name. Seeding again writes only what
+// changed in the files.
+func Seed(store Store, data *Data) (*Result, error) {
+	if err := store.MigrateCatalog(); err != nil {
+		return nil, err
+	}
+	result := &Result{}
+
+	existingCols := make(map[string]models.Column)
+	for _, c := range store.GetColumns() {
+		existingCols[c.Name] = c
+	}
+	columnIDs := make(map[string]int)
+	for _, c := range data.Columns {
+		col := c
+		if old, ok := existingCols[c.Name]; ok {
+			columnIDs[c.Name] = old.ID
+			if old.Color == c.Color && old.ColumnIndex == c.ColumnIndex && old.IsCategory == c.IsCategory {
+				result.Unchanged++
+				continue
+			}
+			col.ID, col.Letter = old.ID, old.Letter
+			result.ColumnsUpdated++
+		} else {
+			result.ColumnsCreated++
+		}
+		if err := store.SaveColumn(&col); err != nil {
+			return result, fmt.Errorf("could not save column %s: %s", c.Name, err.Error())
+		}
+		columnIDs[c.Name] = col.ID
+	}
+
+	existingMerchants := make(map[string]models.Merchant)
+	for _, m := range store.GetMerchants() {
+		if _, ok := existingMerchants[m.BankName]; !ok {
+			existingMerchants[m.BankName] = m
+		}
+	}
+	for _, s := range data.Merchants {
+		m := models.Merchant{BankName: s.BankName, Name: s.Name, ColumnID: columnIDs[s.ColumnName]}
+		if old, ok := existingMerchants[s.BankName]; ok {
+			if old.Name == m.Name && old.ColumnID == m.ColumnID {
+				result.Unchanged++
+				continue
+			}
+			m.ID, m.TaxDeductible = old.ID, old.TaxDeductible
+			result.MerchantsUpdated++
+		} else {
+			result.MerchantsCreated++
+		}
+		if err := store.SaveMerchant(&m); err != nil {
+			return result, fmt.Errorf("could not save merchant %s: %s", s.BankName, err.Error())
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,158 @@
+package seed
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"register/pkg/models"
+)
+
+func writeSeedFiles(t *testing.T, columns, merchToCols, merchants string) string {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		ColumnsFileName:     columns,
+		MerchToColsFileName: merchToCols,
+		MerchantsFileName:   merchants,
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoad(t *testing.T) {
+	columns := "Name,Color,Category\n\"Date\",\"white\",\"false\"\n\"\",\"yellow\",\"false\"\n\"Dining Out\",\"green\",\"true\"\n"
+	tests := []struct {
+		name        string
+		columns     string
+		merchToCols string
+		merchants   string
+		want        *Data
+		wantErr     string
+	}{
+		{
+			name:        "Test valid files",
+			columns:     columns,
+			merchToCols: "Merchant,Column Name\n\"Chipotle\",\"Dining Out\"\n\"Salary\",\"\"\n",
+			merchants:   "Bank Name, Name\n\"CHIPOTLE 123\",\"Chipotle\"\n\"ACME PAYROLL\",\"Salary\"\n",
+			want: &Data{
+				Columns: []models.Column{
+					{Name: "Date", Color: "white", ColumnIndex: 0},
+					{Name: "old-1", Color: "yellow", ColumnIndex: 1},
+					{Name: "Dining Out", Color: "green", ColumnIndex: 2, IsCategory: true},
+				},
+				Merchants: []Merchant{{BankName: "CHIPOTLE 123", Name: "Chipotle", ColumnName: "Dining Out"}},
+				Skipped:   []string{"Salary (ACME PAYROLL)"},
+			},
+		},
+		{
+			name:        "Test invalid rows",
+			columns:     "Name,Color,Category\n\"Date\",\"white\",\"no\"\n\"Date\",\"white\",\"false\"\n",
+			merchToCols: "Merchant,Column Name\n\"Chipotle\",\"Dining Out\"\n\"Salary\",\"\"\n\"Salary\",\"Date\"\n",
+			merchants:   "Bank Name, Name\n\"CHIPOTLE 123\",\"Chipotle\"\n\"CHIPOTLE 123\",\"Chipotle\"\n\"SAFEWAY\",\"Safeway\"\n",
+			wantErr: "columns.csv line 2: Category \"no\" is not true or false\n" +
+				"columns.csv line 3: column \"Date\" is listed twice\n" +
+				"merch_to_cats.csv line 2: column \"Dining Out\" is not in columns.csv\n" +
+				"merch_to_cats.csv line 4: merchant \"Salary\" is mapped to both \"\" and \"Date\"\n" +
+				"merchants.csv line 3: bank name \"CHIPOTLE 123\" is listed twice\n" +
+				"merchants.csv line 4: merchant \"Safeway\" is not in merch_to_cats.csv",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Load(writeSeedFiles(t, tt.columns, tt.merchToCols, tt.merchants))
+			if (err != nil || tt.wantErr != "") && (err == nil || err.Error() != tt.wantErr) {
+				t.Fatalf("Load() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Load() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoad_repositoryFiles(t *testing.T) {
+	data, err := Load("../repository/csv")
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(data.Columns) != 54 || len(data.Merchants) == 0 {
+		t.Errorf("Load() read %d columns and %d merchants", len(data.Columns), len(data.Merchants))
+	}
+	// the import program made the first ten columns, and only those, not categories
+	for _, c := range data.Columns {
+		if c.IsCategory != (c.ColumnIndex >= 10) {
+			t.Errorf("Load() column %s IsCategory = %v", c.Name, c.IsCategory)
+		}
+	}
+}
+
+// fakeStore keeps the columns and merchants in memory and counts the saves
+type fakeStore struct {
+	columns   []models.Column
+	merchants []models.Merchant
+	saves     int
+}
+
+func (f *fakeStore) MigrateCatalog() error           { return nil }
+func (f *fakeStore) GetColumns() []models.Column     { return f.columns }
+func (f *fakeStore) GetMerchants() []models.Merchant { return f.merchants }
+func (f *fakeStore) SaveColumn(col *models.Column) error {
+	f.saves++
+	if col.ID == 0 {
+		col.ID = len(f.columns) + 1
+		f.columns = append(f.columns, *col)
+		return nil
+	}
+	f.columns[col.ID-1] = *col
+	return nil
+}
+func (f *fakeStore) SaveMerchant(m *models.Merchant) error {
+	f.saves++
+	if m.ID == 0 {
+		m.ID = len(f.merchants) + 1
+		f.merchants = append(f.merchants, *m)
+		return nil
+	}
+	f.merchants[m.ID-1] = *m
+	return nil
+}
+
+func TestSeed(t *testing.T) {
+	data := &Data{
+		Columns: []models.Column{
+			{Name: "Dining Out", Color: "green", ColumnIndex: 10, IsCategory: true},
+			{Name: "Grocery", Color: "green", ColumnIndex: 11, IsCategory: true},
+		},
+		Merchants: []Merchant{
+			{BankName: "CHIPOTLE 123", Name: "Chipotle", ColumnName: "Dining Out"},
+			{BankName: "SAFEWAY", Name: "Safeway", ColumnName: "Grocery"},
+		},
+	}
+	store := &fakeStore{
+		columns:   []models.Column{{ID: 1, Name: "Dining Out", Color: "red", ColumnIndex: 10, IsCategory: true}},
+		merchants: []models.Merchant{{ID: 1, BankName: "SAFEWAY", Name: "Safeway", ColumnID: 1, TaxDeductible: true}},
+	}
+
+	got, err := Seed(store, data)
+	if err != nil {
+		t.Fatalf("Seed() error = %v", err)
+	}
+	want := &Result{ColumnsCreated: 1, ColumnsUpdated: 1, MerchantsCreated: 1, MerchantsUpdated: 1}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Seed() = %+v, want %+v", got, want)
+	}
+	if m := store.merchants[0]; m.ColumnID != 2 || !m.TaxDeductible {
+		t.Errorf("updated merchant = %+v, want column 2 and still tax deductible", m)
+	}
+
+	// seeding again changes nothing
+	store.saves = 0
+	got, err = Seed(store, data)
+	if err != nil || store.saves != 0 || got.Unchanged != 4 {
+		t.Errorf("Seed() again = %+v, %v with %d saves, want 4 unchanged and no saves", got, err, store.saves)
+	}
+}
@@ -0,0 +1,64 @@
+package statement
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+
+	"register/pkg/models"
+)
+
+// CardPaymentRe matches the payment of a card's balance, which the register records from the checking account
+var CardPaymentRe = regexp.MustCompile(`(?i)(payment\s+thank you|autopay|online payment)`)
+
+// Entry is a transaction read from a statement download, eg. an OFX, QIF or mapped CSV file
+type Entry struct {
+	Source       string  // the bank's source, eg. Chase
+	KeyPrefix    string  // starts the Key; if empty, the lower case Source, or the check number of a check
+	Date         string  // in models.RegisterDateFormat
+	Description  string  // the bank's name for the transaction
+	CheckNum     string  // makes the entry a check written on the account
+	Out          float64 // positive for money out and negative for money in, as Plaid's amounts are
+	IsCreditCard bool
+}
+
+// Transaction follows the Plaid conventions in banking.buildTransaction: card entries fill the Credit Purchases
+// and Credit Card columns, others the Withdrawal or Deposit column, and checks take their number as the Source
+func (e Entry) Transaction() *models.Transaction {
+	t := &models.Transaction{
+		Source:   e.Source,
+		Date:     e.Date,
+		Name:     e.Description,
+		BankName: e.Description,
+		Amount:   e.Out,
+		Budget:   -e.Out,
+	}
+	if e.IsCreditCard {
+		t.CreditPurchase = e.Out
+		t.CreditCard = e.Out
+		t.Key = fmt.Sprintf("%s:%s:%.2f", e.keyPrefix(t.Source), e.Date, e.Out)
+		return t
+	}
+
+	if e.CheckNum != "" {
+		t.Source = e.CheckNum
+		t.IsCheck = true
+		t.Name = "CHECK"
+		t.BankName = "CHECK"
+	}
+	if e.Out < 0 {
+		t.Deposit = -e.Out
+	} else {
+		t.Withdrawal = e.Out
+	}
+	t.Key = fmt.Sprintf("%s:%s:%.2f", e.keyPrefix(t.Source), e.Date, math.Abs(e.Out))
+	return t
+}
+
+func (e Entry) keyPrefix(source string) string {
+	if e.KeyPrefix != "" {
+		return e.KeyPrefix
+	}
+	return strings.ToLower(source)
+}
@@ -0,0 +1,59 @@
+package statement
+
+import (
+	"reflect"
+	"testing"
+
+	"register/pkg/models"
+)
+
+func TestEntry_Transaction(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry Entry
+		want  *models.Transaction
+	}{
+		{
+			name:  "Test withdrawal",
+			entry: Entry{Source: "WellsFargo", Date: "01/05/26", Description: "GLO FIBER", Out: 75.53},
+			want: &models.Transaction{Key: "wellsfargo:01/05/26:75.53", Source: "WellsFargo", Date: "01/05/26",
+				Name: "GLO FIBER", BankName: "GLO FIBER", Amount: 75.53, Withdrawal: 75.53, Budget: -75.53},
+		},
+		{
+			name:  "Test deposit with a key prefix",
+			entry: Entry{Source: "CreditUnion", KeyPrefix: "credit_union", Date: "01/07/26", Description: "PAYROLL", Out: -2000},
+			want: &models.Transaction{Key: "credit_union:01/07/26:2000.00", Source: "CreditUnion", Date: "01/07/26",
+				Name: "PAYROLL", BankName: "PAYROLL", Amount: -2000, Deposit: 2000, Budget: 2000},
+		},
+		{
+			name:  "Test check",
+			entry: Entry{Source: "WellsFargo", Date: "01/06/26", Description: "CHECK 110", CheckNum: "110", Out: 25},
+			want: &models.Transaction{Key: "110:01/06/26:25.00", Source: "110", Date: "01/06/26",
+				Name: "CHECK", BankName: "CHECK", Amount: 25, Withdrawal: 25, Budget: -25, IsCheck: true},
+		},
+		{
+			name:  "Test card refund ignores a check number",
+			entry: Entry{Source: "Chase", Date: "01/08/26", Description: "REFUND", CheckNum: "7", Out: -12, IsCreditCard: true},
+			want: &models.Transaction{Key: "chase:01/08/26:-12.00", Source: "Chase", Date: "01/08/26",
+				Name: "REFUND", BankName: "REFUND", Amount: -12, CreditPurchase: -12, CreditCard: -12, Budget: 12},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.Transaction(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Transaction() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCardPaymentRe(t *testing.T) {
+	for _, name := range []string{"PAYMENT THANK YOU", "Payment  Thank You", "AUTOPAY 260105", "ONLINE PAYMENT"} {
+		if !CardPaymentRe.MatchString(name) {
+			t.Errorf("CardPaymentRe does not match %q", name)
+		}
+	}
+	if CardPaymentRe.MatchString("NETFLIX.COM") {
+		t.Errorf("CardPaymentRe matches NETFLIX.COM")
+	}
+}
@@ -0,0 +1,231 @@
+package token_store
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// fileMagic starts every encrypted store file; the version byte allows the format to change
+var fileMagic = []byte("RTS1")
+
+// FileStore keeps the tokens as a JSON map encrypted with AES-256-GCM in a single file, written with mode 0600
+type FileStore struct {
+	fileName string
+	key      []byte
+	keyErr   error // why the key could not be loaded
+	mu       sync.Mutex
+}
+
+// NewFileStore returns a store for fileName; the file is created by the first Put
+func NewFileStore(fileName string, key []byte) (*FileStore, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("token store key must be 32 bytes, got %d", len(key))
+	}
+	return &FileStore{fileName: fileName, key: key}, nil
+}
+
+// GenerateKey returns a new random store key, base64 encoded
+func GenerateKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// LoadKey reads the store key from KeyEnvVar, or from keyFile
+func LoadKey(keyFile string) ([]byte, error) {
+	encoded := os.Getenv(KeyEnvVar)
+	if encoded == "" {
+		if keyFile == "" {
+			return nil, fmt.Errorf("no token store key: set %s or TokenKeyFile", KeyEnvVar)
+		}
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read token key file %s: %s", keyFile, err.Error())
+		}
+		encoded = string(data)
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("could not decode token store key: %s", err.Error())
+	}
+	return key, nil
+}
+
+// Get decrypts the store and returns the institution and item's token
+func (s *FileStore) Get(institution, itemID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read(s.key)
+	if err != nil {
+		return "", err
+	}
+	token, ok := tokens[Key{institution, itemID}.String()]
+	if !ok {
+		return "", fmt.Errorf("%s: %w", Key{institution, itemID}, ErrNotFound)
+	}
+	return token, nil
+}
+
+// Put adds or replaces the token of an item
+func (s *FileStore) Put(institution, itemID, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read(s.key)
+	if err != nil {
+		return err
+	}
+	tokens[Key{institution, itemID}.String()] = token
+	return s.write(tokens, s.key)
+}
+
+// Remove deletes the token of an item
+func (s *FileStore) Remove(institution, itemID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read(s.key)
+	if err != nil {
+		return err
+	}
+	k := Key{institution, itemID}.String()
+	if _, ok := tokens[k]; !ok {
+		return fmt.Errorf("%s: %w", k, ErrNotFound)
+	}
+	delete(tokens, k)
+	return s.write(tokens, s.key)
+}
+
+// List returns the keys of the stored tokens, sorted
+func (s *FileStore) List() ([]Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read(s.key)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]Key, 0, len(tokens))
+	for k := range tokens {
+		institution, itemID, _ := strings.Cut(k, "/")
+		keys = append(keys, Key{Institution: institution, ItemID: itemID})
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].String() < keys[j].String()
+	})
+	return keys, nil
+}
+
+// RotateKey re-encrypts the store with newKey; the store uses newKey from then on
+func (s *FileStore) RotateKey(newKey []byte) error {
+	if len(newKey) != 32 {
+		return fmt.Errorf("token store key must be 32 bytes, got %d", len(newKey))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tokens, err := s.read(s.key)
+	if err != nil {
+		return err
+	}
+	if err := s.write(tokens, newKey); err != nil {
+		return err
+	}
+	s.key, s.keyErr = newKey, nil
+	return nil
+}
+
+func (s *FileStore) read(key []byte) (map[string]string, error) {
+	if s.keyErr != nil {
+		return nil, s.keyErr
+	}
+	data, err := os.ReadFile(s.fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return make(map[string]string), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read token store %s: %s", s.fileName, err.Error())
+	}
+	if !bytes.HasPrefix(data, fileMagic) {
+		return nil, fmt.Errorf("%s is not a token store file", s.fileName)
+	}
+	data = data[len(fileMagic):]
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, fmt.Errorf("token store %s is truncated", s.fileName)
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], fileMagic)
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt token store %s: wrong key or corrupt file", s.fileName)
+	}
+	tokens := make(map[string]string)
+	if err := json.Unmarshal(plain, &tokens); err != nil {
+		return nil, fmt.Errorf("could not parse token store %s: %s", s.fileName, err.Error())
+	}
+	return tokens, nil
+}
+
+// write encrypts the tokens to a temp file and renames it over the store so a failed write leaves the old file
+func (s *FileStore) write(tokens map[string]string, key []byte) error {
+	plain, err := json.Marshal(tokens)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	data := append(append([]byte{}, fileMagic...), nonce...)
+	data = gcm.Seal(data, nonce, plain, fileMagic)
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.fileName), ".tokens-*")
+	if err != nil {
+		return fmt.Errorf("could not write token store: %s", err.Error())
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write token store: %s", err.Error())
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("could not write token store: %s", err.Error())
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("could not write token store: %s", err.Error())
+	}
+	if err := os.Rename(tmp.Name(), s.fileName); err != nil {
+		return fmt.Errorf("could not write token store: %s", err.Error())
+	}
+	return nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,68 @@
+package token_store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// tokenFileSuffix ends the legacy token file names, eg. ChaseAccessToken.txt
+const tokenFileSuffix = "AccessToken.txt"
+
+// PlainStore reads and writes the legacy plain text token files, <itemID>AccessToken.txt. The institution is
+// not part of the file name. It is kept so existing token files work and can be migrated.
+type PlainStore struct {
+	dir string
+}
+
+// NewPlainStore returns the store of the token files in dir, the PlaidTokensDir
+func NewPlainStore(dir string) *PlainStore {
+	return &PlainStore{dir: dir}
+}
+
+// Get reads the item's token file
+func (s *PlainStore) Get(institution, itemID string) (string, error) {
+	data, err := os.ReadFile(s.fileName(itemID))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("%s: %w", Key{institution, itemID}, ErrNotFound)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(string(data), "\n", ""), nil
+}
+
+// Put writes the token file with mode 0600
+func (s *PlainStore) Put(institution, itemID, token string) error {
+	return os.WriteFile(s.fileName(itemID), []byte(token+"\n"), 0600)
+}
+
+// Remove deletes the item's token file
+func (s *PlainStore) Remove(institution, itemID string) error {
+	err := os.Remove(s.fileName(itemID))
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%s: %w", Key{institution, itemID}, ErrNotFound)
+	}
+	return err
+}
+
+// List returns a key per token file; the institution is unknown
+func (s *PlainStore) List() ([]Key, error) {
+	files, err := filepath.Glob(filepath.Join(s.dir, "*"+tokenFileSuffix))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	keys := make([]Key, 0, len(files))
+	for _, f := range files {
+		keys = append(keys, Key{ItemID: strings.TrimSuffix(filepath.Base(f), tokenFileSuffix)})
+	}
+	return keys, nil
+}
+
+func (s *PlainStore) fileName(itemID string) string {
+	return filepath.Join(s.dir, itemID+tokenFileSuffix)
+}
@@ -0,0 +1,67 @@
+package token_store
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+)
+
+const (
+	// EncryptedBackend keeps every token in one AES-GCM encrypted file
+	EncryptedBackend = "encrypted"
+	// PlainBackend reads and writes the legacy plain text <item>AccessToken.txt files
+	PlainBackend = "plain"
+
+	// StoreFileName is the encrypted token file in the tokens dir
+	StoreFileName = "tokens.enc"
+	// KeyEnvVar holds the base64 encoded 32 byte store key; it takes precedence over the key file
+	KeyEnvVar = "REGISTER_TOKEN_KEY"
+)
+
+// ErrNotFound is returned for an institution and item without a stored token
+var ErrNotFound = errors.New("token not found")
+
+// Key identifies an access token: the institution (bank ID) and the Plaid item within it
+type Key struct {
+	Institution string `json:"institution"`
+	ItemID      string `json:"itemID"`
+}
+
+func (k Key) String() string {
+	return k.Institution + "/" + k.ItemID
+}
+
+// Store keeps Plaid access tokens by institution and item
+type Store interface {
+	Get(institution, itemID string) (string, error)
+	Put(institution, itemID, token string) error
+	Remove(institution, itemID string) error
+	List() ([]Key, error)
+}
+
+// ConfigOptions select the token store and where it keeps the tokens
+type ConfigOptions struct {
+	Dir     string // PlaidTokensDir
+	Backend string // EncryptedBackend or PlainBackend (default), so existing token files keep working
+	KeyFile string // base64 store key, used when KeyEnvVar is not set
+}
+
+// New returns the store for the configured backend. A missing or bad key for the encrypted backend is not an
+// error here, so commands that do not use tokens still run; every call on the store returns it instead.
+func New(o ConfigOptions) (Store, error) {
+	switch o.Backend {
+	case PlainBackend, "":
+		return NewPlainStore(o.Dir), nil
+	case EncryptedBackend:
+		fileName := filepath.Join(o.Dir, StoreFileName)
+		key, err := LoadKey(o.KeyFile)
+		if err == nil {
+			var s *FileStore
+			if s, err = NewFileStore(fileName, key); err == nil {
+				return s, nil
+			}
+		}
+		return &FileStore{fileName: fileName, keyErr: err}, nil
+	}
+	return nil, fmt.Errorf("unknown token store backend: %s", o.Backend)
+}
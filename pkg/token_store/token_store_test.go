@@ -0,0 +1,114 @@
+package token_store
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, 32)
+}
+
+func TestFileStore(t *testing.T) {
+	fileName := filepath.Join(t.TempDir(), StoreFileName)
+	s, err := NewFileStore(fileName, testKey(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Put("chase", "login1", "access-sandbox-1"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if err := s.Put("wellsfargo", "WellsFargo", "access-sandbox-2"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if got, err := s.Get("chase", "login1"); err != nil || got != "access-sandbox-1" {
+		t.Errorf("Get() = %v, %v", got, err)
+	}
+	if _, err := s.Get("chase", "login2"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() of a missing item error = %v, want ErrNotFound", err)
+	}
+
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(data, []byte("access-sandbox")) {
+		t.Errorf("store file holds a token in plain text")
+	}
+	info, _ := os.Stat(fileName)
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("store file mode = %v, want 0600", info.Mode().Perm())
+	}
+
+	wrongKey, _ := NewFileStore(fileName, testKey(2))
+	if _, err := wrongKey.Get("chase", "login1"); err == nil {
+		t.Errorf("Get() with the wrong key did not fail")
+	}
+
+	if err := s.RotateKey(testKey(3)); err != nil {
+		t.Fatalf("RotateKey() error = %v", err)
+	}
+	rotated, _ := NewFileStore(fileName, testKey(3))
+	if got, err := rotated.Get("wellsfargo", "WellsFargo"); err != nil || got != "access-sandbox-2" {
+		t.Errorf("Get() after RotateKey() = %v, %v", got, err)
+	}
+
+	if err := s.Remove("chase", "login1"); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	keys, err := s.List()
+	if err != nil || !reflect.DeepEqual(keys, []Key{{Institution: "wellsfargo", ItemID: "WellsFargo"}}) {
+		t.Errorf("List() = %v, %v", keys, err)
+	}
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv(KeyEnvVar, "")
+	dir := t.TempDir()
+
+	// without a backend the legacy token files are read, so existing installs keep working
+	s, err := New(ConfigOptions{Dir: dir})
+	if _, ok := s.(*PlainStore); err != nil || !ok {
+		t.Errorf("New() without a backend = %T, %v, want *PlainStore", s, err)
+	}
+
+	// a missing key is reported when the store is used, not when it is created
+	s, err = New(ConfigOptions{Dir: dir, Backend: EncryptedBackend, KeyFile: filepath.Join(dir, "missing.key")})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if _, err := s.List(); err == nil {
+		t.Errorf("List() without a key did not fail")
+	}
+
+	if _, err := New(ConfigOptions{Dir: dir, Backend: "vault"}); err == nil {
+		t.Errorf("New() with an unknown backend did not fail")
+	}
+}
+
+func TestPlainStore(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "ChaseAccessToken.txt"), []byte("access-sandbox-1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s := NewPlainStore(dir)
+	if got, err := s.Get("chase", "Chase"); err != nil || got != "access-sandbox-1" {
+		t.Errorf("Get() = %v, %v", got, err)
+	}
+	if err := s.Put("citi", "Citi", "access-sandbox-2"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	info, _ := os.Stat(filepath.Join(dir, "CitiAccessToken.txt"))
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("token file mode = %v, want 0600", info.Mode().Perm())
+	}
+	keys, _ := s.List()
+	if !reflect.DeepEqual(keys, []Key{{ItemID: "Chase"}, {ItemID: "Citi"}}) {
+		t.Errorf("List() = %v", keys)
+	}
+}
@@ -0,0 +1,67 @@
+package webhook
+
+import (
+	"context"
+	"log"
+	"sync"
+)
+
+// SyncFunc reads an item's new transactions
+type SyncFunc func(bankID, itemID string) error
+
+// Queue runs item syncs one at a time, so a burst of webhooks for an item causes a single sync
+type Queue struct {
+	sync    SyncFunc
+	items   chan item
+	mu      sync.Mutex
+	pending map[item]bool
+}
+
+type item struct {
+	bankID string
+	itemID string
+}
+
+// NewQueue returns a Queue holding up to size pending items
+func NewQueue(sync SyncFunc, size int) *Queue {
+	return &Queue{
+		sync:    sync,
+		items:   make(chan item, size),
+		pending: make(map[item]bool),
+	}
+}
+
+// Enqueue queues a sync of the item. It returns false if the item is already pending or the queue is full.
+func (q *Queue) Enqueue(bankID, itemID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	i := item{bankID: bankID, itemID: itemID}
+	if q.pending[i] {
+		return false
+	}
+	select {
+	case q.items <- i:
+		q.pending[i] = true
+		return true
+	default:
+		log.Printf("webhook queue full, dropping sync of %s item %s", bankID, itemID)
+		return false
+	}
+}
+
+// Run syncs the queued items until ctx is done
+func (q *Queue) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case i := <-q.items:
+			q.mu.Lock()
+			delete(q.pending, i)
+			q.mu.Unlock()
+			if err := q.sync(i.bankID, i.itemID); err != nil {
+				log.Printf("sync of %s item %s: %s", i.bankID, i.itemID, err.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package webhook
+
+import (
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+)
+
+// MaxAge is how old a webhook's JWT may be before it is refused as a replay
+const MaxAge = 5 * time.Minute
+
+// ErrInvalidSignature is returned for a webhook whose Plaid-Verification JWT does not verify
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// KeySource returns Plaid's public key for a JWT key ID; banking.Client is one
+type KeySource interface {
+	WebhookVerificationKey(kid string) (*ecdsa.PublicKey, error)
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	Typ string `json:"typ,omitempty"`
+}
+
+type jwtClaims struct {
+	IssuedAt          int64  `json:"iat"`
+	RequestBodySHA256 string `json:"request_body_sha256"`
+}
+
+// Verify checks the Plaid-Verification JWT of a webhook: an ES256 signature by a Plaid key, issued no more
+// than MaxAge before now, over the SHA-256 of body
+func Verify(token string, body []byte, keys KeySource, now time.Time) error {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return fmt.Errorf("%w: not a JWT", ErrInvalidSignature)
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return fmt.Errorf("%w: header: %s", ErrInvalidSignature, err.Error())
+	}
+	if header.Alg != "ES256" || header.Kid == "" {
+		return fmt.Errorf("%w: alg %q kid %q, want ES256 with a kid", ErrInvalidSignature, header.Alg, header.Kid)
+	}
+
+	key, err := keys.WebhookVerificationKey(header.Kid)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidSignature, err.Error())
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || len(sig) != 64 {
+		return fmt.Errorf("%w: malformed signature", ErrInvalidSignature)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(sig[:32]), new(big.Int).SetBytes(sig[32:])
+	if !ecdsa.Verify(key, digest[:], r, s) {
+		return fmt.Errorf("%w: signature does not match key %s", ErrInvalidSignature, header.Kid)
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return fmt.Errorf("%w: claims: %s", ErrInvalidSignature, err.Error())
+	}
+	issued := time.Unix(claims.IssuedAt, 0)
+	if now.Sub(issued) > MaxAge || issued.Sub(now) > MaxAge {
+		return fmt.Errorf("%w: issued at %s", ErrInvalidSignature, issued.Format(time.RFC3339))
+	}
+	bodySum := sha256.Sum256(body)
+	if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(bodySum[:])), []byte(claims.RequestBodySHA256)) != 1 {
+		return fmt.Errorf("%w: body does not match request_body_sha256", ErrInvalidSignature)
+	}
+	return nil
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,119 @@
+package webhook
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+)
+
+// VerificationHeader carries the JWT Plaid signs each webhook with
+const VerificationHeader = "Plaid-Verification"
+
+// maxBodySize limits the webhook bodies read
+const maxBodySize = 1 << 20
+
+// Items finds the bank item a Plaid item_id belongs to and records its errors; banking.Client is one
+type Items interface {
+	FindItem(plaidItemID string) (bankID, itemID string, ok bool)
+	RecordItemError(bankID, itemID, errorCode, message string) error
+}
+
+// Payload is the body of a Plaid webhook
+type Payload struct {
+	WebhookType           string `json:"webhook_type"`
+	WebhookCode           string `json:"webhook_code"`
+	ItemID                string `json:"item_id"`
+	Error                 *Error `json:"error,omitempty"`
+	ConsentExpirationTime string `json:"consent_expiration_time,omitempty"`
+	Environment           string `json:"environment,omitempty"`
+}
+
+// Error is the Plaid error of an ITEM_ERROR webhook
+type Error struct {
+	ErrorType    string `json:"error_type"`
+	ErrorCode    string `json:"error_code"`
+	ErrorMessage string `json:"error_message"`
+}
+
+// ConfigOptions are the Handler's collaborators
+type ConfigOptions struct {
+	Keys  KeySource
+	Items Items
+	Queue *Queue
+	Now   func() time.Time
+}
+
+// Handler receives Plaid webhooks
+type Handler struct {
+	keys  KeySource
+	items Items
+	queue *Queue
+	now   func() time.Time
+}
+
+// New returns a Handler
+func New(o ConfigOptions) *Handler {
+	now := o.Now
+	if now == nil {
+		now = time.Now
+	}
+	return &Handler{
+		keys:  o.Keys,
+		items: o.Items,
+		queue: o.Queue,
+		now:   now,
+	}
+}
+
+// ServeHTTP verifies the webhook and acts on it. Webhooks that are not handled, or are for items not in the
+// config, are acknowledged so Plaid does not retry them.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := Verify(r.Header.Get(VerificationHeader), body, h.keys, h.now()); err != nil {
+		log.Printf("webhook refused: %s", err.Error())
+		http.Error(w, "invalid webhook signature", http.StatusUnauthorized)
+		return
+	}
+
+	var payload Payload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err := h.handle(payload); err != nil {
+		log.Printf("webhook %s %s: %s", payload.WebhookType, payload.WebhookCode, err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *Handler) handle(p Payload) error {
+	bankID, itemID, ok := h.items.FindItem(p.ItemID)
+	if !ok {
+		log.Printf("webhook %s %s for unknown item %s ignored", p.WebhookType, p.WebhookCode, p.ItemID)
+		return nil
+	}
+
+	switch {
+	case p.WebhookType == "TRANSACTIONS" && (p.WebhookCode == "SYNC_UPDATES_AVAILABLE" || p.WebhookCode == "DEFAULT_UPDATE"):
+		h.queue.Enqueue(bankID, itemID)
+	case p.WebhookType == "ITEM" && p.WebhookCode == "ITEM_ERROR":
+		if p.Error == nil {
+			return errors.New("ITEM_ERROR webhook without an error")
+		}
+		return h.items.RecordItemError(bankID, itemID, p.Error.ErrorCode, p.Error.ErrorMessage)
+	case p.WebhookType == "ITEM" && p.WebhookCode == "PENDING_EXPIRATION":
+		return h.items.RecordItemError(bankID, itemID, "PENDING_EXPIRATION", "consent expires at "+p.ConsentExpirationTime)
+	default:
+		log.Printf("webhook %s %s for %s item %s ignored", p.WebhookType, p.WebhookCode, bankID, itemID)
+	}
+	return nil
+}
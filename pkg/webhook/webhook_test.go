@@ -0,0 +1,145 @@
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakeItems knows the Plaid item "plaid-chase" and records item errors
+type fakeItems struct {
+	errors []string
+}
+
+func (f *fakeItems) FindItem(plaidItemID string) (string, string, bool) {
+	if plaidItemID == "plaid-chase" {
+		return "chase", "login1", true
+	}
+	return "", "", false
+}
+
+func (f *fakeItems) RecordItemError(bankID, itemID, errorCode, message string) error {
+	f.errors = append(f.errors, bankID+"/"+itemID+" "+errorCode)
+	return nil
+}
+
+func TestHandler_ServeHTTP(t *testing.T) {
+	sender, err := NewFakeSender()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		payload    Payload
+		issuedAt   time.Duration
+		tamper     bool
+		wantStatus int
+		wantSynced []string
+		wantErrors []string
+	}{
+		{
+			name:       "Test sync updates available",
+			payload:    Payload{WebhookType: "TRANSACTIONS", WebhookCode: "SYNC_UPDATES_AVAILABLE", ItemID: "plaid-chase"},
+			wantStatus: http.StatusOK,
+			wantSynced: []string{"chase/login1"},
+		},
+		{
+			name: "Test item error",
+			payload: Payload{WebhookType: "ITEM", WebhookCode: "ITEM_ERROR", ItemID: "plaid-chase",
+				Error: &Error{ErrorType: "ITEM_ERROR", ErrorCode: "ITEM_LOGIN_REQUIRED"}},
+			wantStatus: http.StatusOK,
+			wantErrors: []string{"chase/login1 ITEM_LOGIN_REQUIRED"},
+		},
+		{
+			name:       "Test pending expiration",
+			payload:    Payload{WebhookType: "ITEM", WebhookCode: "PENDING_EXPIRATION", ItemID: "plaid-chase"},
+			wantStatus: http.StatusOK,
+			wantErrors: []string{"chase/login1 PENDING_EXPIRATION"},
+		},
+		{
+			name:       "Test unknown item",
+			payload:    Payload{WebhookType: "TRANSACTIONS", WebhookCode: "SYNC_UPDATES_AVAILABLE", ItemID: "plaid-other"},
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "Test tampered body",
+			payload:    Payload{WebhookType: "TRANSACTIONS", WebhookCode: "SYNC_UPDATES_AVAILABLE", ItemID: "plaid-chase"},
+			tamper:     true,
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "Test replayed webhook",
+			payload:    Payload{WebhookType: "TRANSACTIONS", WebhookCode: "SYNC_UPDATES_AVAILABLE", ItemID: "plaid-chase"},
+			issuedAt:   -10 * time.Minute,
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			synced := make(chan string, 1)
+			queue := NewQueue(func(bankID, itemID string) error {
+				synced <- bankID + "/" + itemID
+				return nil
+			}, 10)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go queue.Run(ctx)
+
+			items := &fakeItems{}
+			ts := httptest.NewServer(New(ConfigOptions{Keys: sender, Items: items, Queue: queue}))
+			defer ts.Close()
+
+			sender.Now = func() time.Time { return time.Now().Add(tt.issuedAt) }
+			req, err := sender.Request(ts.URL, tt.payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.tamper {
+				tt.payload.ItemID = "plaid-other"
+				tampered, _ := sender.Request(ts.URL, tt.payload)
+				tampered.Header.Set(VerificationHeader, req.Header.Get(VerificationHeader))
+				req = tampered
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			var gotSynced []string
+			if tt.wantSynced != nil {
+				select {
+				case s := <-synced:
+					gotSynced = append(gotSynced, s)
+				case <-time.After(time.Second):
+				}
+			}
+			if !reflect.DeepEqual(gotSynced, tt.wantSynced) {
+				t.Errorf("synced = %v, want %v", gotSynced, tt.wantSynced)
+			}
+			if !reflect.DeepEqual(items.errors, tt.wantErrors) {
+				t.Errorf("recorded errors = %v, want %v", items.errors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+func TestQueue_Enqueue(t *testing.T) {
+	q := NewQueue(func(bankID, itemID string) error { return nil }, 1)
+	if !q.Enqueue("chase", "login1") {
+		t.Errorf("Enqueue() = false, want true")
+	}
+	if q.Enqueue("chase", "login1") {
+		t.Errorf("Enqueue() of a pending item = true, want false")
+	}
+	if q.Enqueue("citi", "Citi") {
+		t.Errorf("Enqueue() on a full queue = true, want false")
+	}
+}
@@ -0,0 +1,162 @@
+package window
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateFormat is the format of window dates, the format Plaid uses, eg. 2026-09-28
+const DateFormat = "2006-01-02"
+
+// LastRun is the --since value for the window starting at the last successful update
+const LastRun = "last-run"
+
+// LastRunOverlap is how far before the last run's end a last-run window starts, so transactions that post
+// late are read; those already in the register are filtered out
+const LastRunOverlap = 7 * 24 * time.Hour
+
+// Window is the range of dates transactions are read for, both included
+type Window struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Options select a window. The first given of Year, Since and the fixed Start and End is used; without any
+// the window is the current year to date.
+type Options struct {
+	Year    int       // a calendar year, eg. 2027
+	Since   string    // a relative start, eg. 30d, 8w, 3m, 1y or last-run, or a date
+	Start   string    // a fixed start date, eg. the StartDate config setting
+	End     string    // a fixed end date; default today
+	LastRun time.Time // the end of the last successful update, for Since last-run
+	Now     time.Time
+}
+
+var relativeRe = regexp.MustCompile(`^(\d+)([dwmy])$`)
+
+// New returns the window the options select
+func New(o Options) (Window, error) {
+	now := truncate(o.Now)
+	switch {
+	case o.Year != 0:
+		if o.Year < 1900 || o.Year > 9999 {
+			return Window{}, fmt.Errorf("invalid year %d", o.Year)
+		}
+		return Year(o.Year), nil
+	case o.Since != "":
+		start, err := Since(o.Since, now, o.LastRun)
+		if err != nil {
+			return Window{}, err
+		}
+		return Window{Start: start, End: now}, nil
+	}
+
+	w := Window{Start: time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.UTC), End: now}
+	var err error
+	if o.Start != "" {
+		if w.Start, err = time.Parse(DateFormat, o.Start); err != nil {
+			return Window{}, fmt.Errorf("invalid start date %q: %s", o.Start, err.Error())
+		}
+	}
+	if o.End != "" {
+		if w.End, err = time.Parse(DateFormat, o.End); err != nil {
+			return Window{}, fmt.Errorf("invalid end date %q: %s", o.End, err.Error())
+		}
+	}
+	if w.End.Before(w.Start) {
+		return Window{}, fmt.Errorf("the window ends %s, before it starts %s", w.End.Format(DateFormat), w.Start.Format(DateFormat))
+	}
+	return w, nil
+}
+
+// Since returns the start of a relative window ending today: a number of days, weeks, months or years
+// ago, eg. 30d or 3m, the last run less LastRunOverlap, or a date
+func Since(since string, now, lastRun time.Time) (time.Time, error) {
+	now = truncate(now)
+	if since == LastRun {
+		if lastRun.IsZero() {
+			return time.Time{}, errors.New("there is no last run yet; give --since a period or date")
+		}
+		return truncate(lastRun.Add(-LastRunOverlap)), nil
+	}
+	if m := relativeRe.FindStringSubmatch(strings.ToLower(since)); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "d":
+			return now.AddDate(0, 0, -n), nil
+		case "w":
+			return now.AddDate(0, 0, -7*n), nil
+		case "m":
+			return now.AddDate(0, -n, 0), nil
+		default:
+			return now.AddDate(-n, 0, 0), nil
+		}
+	}
+	start, err := time.Parse(DateFormat, since)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid --since %q: want eg. 30d, 8w, 3m, 1y, %s or %s", since, LastRun, DateFormat)
+	}
+	return start, nil
+}
+
+// Year returns the window of the calendar year
+func Year(year int) Window {
+	return Window{
+		Start: time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// AfterYear returns the window with its start moved to the day after the year, eg. a closed year whose
+// transactions must not be read again; an error if the whole window is in or before the year
+func (w Window) AfterYear(year int) (Window, error) {
+	start := time.Date(year+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if w.End.Before(start) {
+		return Window{}, fmt.Errorf("the window %s to %s is in %d, which is closed", w.StartDate(), w.EndDate(), year)
+	}
+	if w.Start.Before(start) {
+		w.Start = start
+	}
+	return w, nil
+}
+
+// StartDate returns the window's start in DateFormat
+func (w Window) StartDate() string {
+	return w.Start.Format(DateFormat)
+}
+
+// EndDate returns the window's end in DateFormat
+func (w Window) EndDate() string {
+	return w.End.Format(DateFormat)
+}
+
+// Contains returns true if the date is in the window
+func (w Window) Contains(date time.Time) bool {
+	date = truncate(date)
+	return !date.Before(w.Start) && !date.After(w.End)
+}
+
+// ReadLastRun returns the end of the last successful update recorded in the file; zero if there is none
+func ReadLastRun(file string) (time.Time, error) {
+	b, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return time.Time{}, nil
+	} else if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(DateFormat, strings.TrimSpace(string(b)))
+}
+
+// WriteLastRun records the end of a successful update in the file
+func WriteLastRun(file string, end time.Time) error {
+	return os.WriteFile(file, []byte(end.Format(DateFormat)+"\n"), 0600)
+}
+
+func truncate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
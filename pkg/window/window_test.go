@@ -0,0 +1,83 @@
+package window
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func day(s string) time.Time {
+	d, _ := time.Parse(DateFormat, s)
+	return d
+}
+
+func TestNew(t *testing.T) {
+	now := time.Date(2027, time.January, 15, 18, 30, 0, 0, time.Local)
+	tests := []struct {
+		name    string
+		o       Options
+		want    Window
+		wantErr bool
+	}{
+		{name: "Test year to date", o: Options{Now: now}, want: Window{Start: day("2027-01-01"), End: day("2027-01-15")}},
+		{name: "Test year", o: Options{Year: 2026, Since: "30d", Now: now}, want: Window{Start: day("2026-01-01"), End: day("2026-12-31")}},
+		{name: "Test days", o: Options{Since: "30d", Now: now}, want: Window{Start: day("2026-12-16"), End: day("2027-01-15")}},
+		{name: "Test weeks", o: Options{Since: "2w", Now: now}, want: Window{Start: day("2027-01-01"), End: day("2027-01-15")}},
+		{name: "Test months", o: Options{Since: "3M", Now: now}, want: Window{Start: day("2026-10-15"), End: day("2027-01-15")}},
+		{name: "Test years", o: Options{Since: "1y", Now: now}, want: Window{Start: day("2026-01-15"), End: day("2027-01-15")}},
+		{name: "Test date", o: Options{Since: "2026-11-01", Now: now}, want: Window{Start: day("2026-11-01"), End: day("2027-01-15")}},
+		{name: "Test last run", o: Options{Since: LastRun, LastRun: day("2027-01-10"), Now: now}, want: Window{Start: day("2027-01-03"), End: day("2027-01-15")}},
+		{name: "Test fixed dates", o: Options{Start: "2026-06-01", End: "2026-06-30", Now: now}, want: Window{Start: day("2026-06-01"), End: day("2026-06-30")}},
+		{name: "Test no last run", o: Options{Since: LastRun, Now: now}, wantErr: true},
+		{name: "Test bad since", o: Options{Since: "last-week", Now: now}, wantErr: true},
+		{name: "Test end before start", o: Options{Start: "2027-02-01", Now: now}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := New(tt.o)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Start.Equal(tt.want.Start) || !got.End.Equal(tt.want.End) {
+				t.Errorf("New() = %s to %s, want %s to %s", got.StartDate(), got.EndDate(), tt.want.StartDate(), tt.want.EndDate())
+			}
+		})
+	}
+}
+
+func TestWindow_AfterYear(t *testing.T) {
+	tests := []struct {
+		name    string
+		w       Window
+		want    Window
+		wantErr bool
+	}{
+		{name: "Test overlapping", w: Window{Start: day("2026-12-20"), End: day("2027-01-15")}, want: Window{Start: day("2027-01-01"), End: day("2027-01-15")}},
+		{name: "Test after", w: Window{Start: day("2027-01-03"), End: day("2027-01-15")}, want: Window{Start: day("2027-01-03"), End: day("2027-01-15")}},
+		{name: "Test closed", w: Year(2026), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.w.AfterYear(2026)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AfterYear() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !got.Start.Equal(tt.want.Start) || !got.End.Equal(tt.want.End) {
+				t.Errorf("AfterYear() = %s to %s, want %s to %s", got.StartDate(), got.EndDate(), tt.want.StartDate(), tt.want.EndDate())
+			}
+		})
+	}
+}
+
+func TestLastRun(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "last_run")
+	if got, err := ReadLastRun(file); err != nil || !got.IsZero() {
+		t.Fatalf("ReadLastRun() = %v, %v, want zero", got, err)
+	}
+	if err := WriteLastRun(file, day("2027-01-15")); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ReadLastRun(file); err != nil || !got.Equal(day("2027-01-15")) {
+		t.Errorf("ReadLastRun() = %v, %v, want 2027-01-15", got, err)
+	}
+}